@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -40,6 +41,8 @@ import (
 // - HOST_IP: should be populated by downward API
 // - POD_IP: should be populated by downward API
 // - CNI_CONFIG_TEMPLATE: the cni .conflist template, run with {{ .PodCIDR }}
+// - CNI_MTU: the pod interface MTU, if unset the MTU of eth0 is used
+//   (kindest/kindnetd:v20200725-4d6bea59 and older ignore CNI_MTU)
 
 // TODO: improve logging & error handling
 
@@ -71,10 +74,18 @@ func main() {
 	}
 
 	mtu, err := computeBridgeMTU()
-	klog.Infof("setting mtu %d for CNI \n", mtu)
 	if err != nil {
 		klog.Infof("Failed to get MTU size from interface eth0, using kernel default MTU size error:%v", err)
 	}
+	// an explicit MTU takes precedence, E.G. when the node network MTU
+	// does not fit the host's
+	if v := os.Getenv("CNI_MTU"); v != "" {
+		mtu, err = strconv.Atoi(v)
+		if err != nil || mtu <= 0 {
+			panic(fmt.Sprintf("invalid CNI_MTU %q: must be a positive integer", v))
+		}
+	}
+	klog.Infof("setting mtu %d for CNI \n", mtu)
 	// used to track if the cni config inputs changed and write the config
 	cniConfigWriter := &CNIConfigWriter{
 		path: cniConfigPath,
@@ -81,6 +81,37 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string `yaml:"containerdConfigPatchesJSON6902,omitempty"`
+
+	// ImageGCHighThresholdPercent is the kubelet's imageGCHighThresholdPercent
+	// for every node, unless overridden on the node.
+	// If unset kind disables image garbage collection by using 100
+	ImageGCHighThresholdPercent int32 `yaml:"imageGCHighThresholdPercent,omitempty"`
+
+	// ImageGCLowThresholdPercent is the kubelet's imageGCLowThresholdPercent
+	// for every node, unless overridden on the node.
+	// If unset the kubelet default is used
+	ImageGCLowThresholdPercent int32 `yaml:"imageGCLowThresholdPercent,omitempty"`
+
+	// SchedulerProfiles are additional kube-scheduler profiles, pods select
+	// a profile by setting its name as their spec.schedulerName.
+	// The default-scheduler profile is kept unless it is listed here.
+	//
+	// This requires Kubernetes v1.18 or newer.
+	//
+	// https://kubernetes.io/docs/reference/scheduling/config/#multiple-profiles
+	SchedulerProfiles []SchedulerProfile `yaml:"schedulerProfiles,omitempty"`
+
+	// KubeReserved is the kubelet's kubeReserved for every node, resources
+	// reserved for Kubernetes system daemons by name (cpu, memory,
+	// ephemeral-storage, or pid) and quantity, E.G. memory: 256Mi.
+	// Nodes may override the quantity of each resource.
+	//
+	// https://kubernetes.io/docs/tasks/administer-cluster/reserve-compute-resources/
+	KubeReserved map[string]string `yaml:"kubeReserved,omitempty"`
+
+	// SystemReserved is the kubelet's systemReserved for every node,
+	// resources reserved for the OS system daemons, like KubeReserved
+	SystemReserved map[string]string `yaml:"systemReserved,omitempty"`
 }
 
 // TypeMeta partially copies apimachinery/pkg/apis/meta/v1.TypeMeta
@@ -101,7 +132,8 @@ type Node struct {
 	Role NodeRole `yaml:"role,omitempty"`
 
 	// Image is the node image to use when creating this node
-	// If unset a default image will be used, see defaults.Image
+	// If unset $KIND_NODE_IMAGE or else a default image will be used,
+	// see defaults.Image
 	Image string `yaml:"image,omitempty"`
 
 	/* Advanced fields */
@@ -115,6 +147,68 @@ type Node struct {
 	// binded to a host Port
 	ExtraPortMappings []PortMapping `yaml:"extraPortMappings,omitempty"`
 
+	// HostAliases are additional /etc/hosts entries for the node container,
+	// E.G. to resolve internal services during bootstrap. These take
+	// precedence over the container runtime's DNS for these hostnames.
+	HostAliases []HostAlias `yaml:"hostAliases,omitempty"`
+
+	// Env sets additional environment variables on the node container.
+	// NOTE: these are set on the container, not for the kubelet or other
+	// processes the node's init system starts, but may be used to configure
+	// custom node images at boot
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// GPUs gives the node container access to the host's GPUs, either "all",
+	// a number of GPUs, or "device=" and a comma separated list of GPU device
+	// indexes or UUIDs, E.G. "device=0,2", like docker's --gpus.
+	// This is only supported by the docker provider, and the node image must
+	// include the runtime hooks for the GPUs, E.G. the NVIDIA container toolkit
+	GPUs string `yaml:"gpus,omitempty"`
+
+	// ImageGCHighThresholdPercent overrides the cluster-wide
+	// imageGCHighThresholdPercent for this node's kubelet if set
+	ImageGCHighThresholdPercent int32 `yaml:"imageGCHighThresholdPercent,omitempty"`
+
+	// ImageGCLowThresholdPercent overrides the cluster-wide
+	// imageGCLowThresholdPercent for this node's kubelet if set
+	ImageGCLowThresholdPercent int32 `yaml:"imageGCLowThresholdPercent,omitempty"`
+
+	// KubeReserved and SystemReserved override the quantities of the
+	// cluster-wide kubeReserved and systemReserved resources for this
+	// node's kubelet, other resources are still reserved
+	KubeReserved   map[string]string `yaml:"kubeReserved,omitempty"`
+	SystemReserved map[string]string `yaml:"systemReserved,omitempty"`
+
+	// Zone is the topology.kubernetes.io/zone label for this node, it is
+	// applied by the kubelet at registration before the node is schedulable
+	Zone string `yaml:"zone,omitempty"`
+
+	// Region is the topology.kubernetes.io/region label for this node, it is
+	// applied by the kubelet at registration before the node is schedulable
+	Region string `yaml:"region,omitempty"`
+
+	// Labels are added to this node, those the kubelet may set on its own
+	// node are applied at registration and the rest, E.G. node-role labels
+	// prohibited by the NodeRestriction admission plugin, through the API
+	// after the node joins
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Hostname is the hostname of the node container, if set it is also the
+	// Kubernetes node name instead of the container name.
+	// It must be a DNS-1123 label, unique across nodes
+	Hostname string `yaml:"hostname,omitempty"`
+
+	// Entrypoint overrides the node container's entrypoint, the first element
+	// is the executable and the rest are its arguments.
+	// WARNING: this is unsupported and for experimenting with alternative
+	// node init systems, kind will not work unless it still starts systemd
+	Entrypoint []string `yaml:"entrypoint,omitempty"`
+
+	// SkipPhases are additional kubeadm init or join phases to skip on this
+	// node, E.G. "addon/kube-proxy". The first control plane node runs init
+	// and the others run join. Phases kind depends on cannot be skipped.
+	SkipPhases []string `yaml:"skipPhases,omitempty"`
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// merge patches. The `kind` field must match the target object, and
 	// if `apiVersion` is specified it will only be applied to matching objects.
@@ -155,6 +249,11 @@ const (
 	ControlPlaneRole NodeRole = "control-plane"
 	// WorkerRole identifies a node that hosts a Kubernetes worker
 	WorkerRole NodeRole = "worker"
+	// KubeletOnlyRole identifies a node that runs a kubelet, but never joins
+	// the cluster, E.G. for experimenting with a standalone kubelet and static
+	// pods. These nodes will not appear in `kubectl get nodes`.
+	// NOTE: kind does not configure the kubelet on these nodes
+	KubeletOnlyRole NodeRole = "kubelet-only"
 )
 
 // Networking contains cluster wide network settings
@@ -204,8 +303,12 @@ type ProxyMode string
 const (
 	// IPTablesMode sets ProxyMode to iptables
 	IPTablesMode ProxyMode = "iptables"
-	// IPVSMode sets ProxyMode to iptables
+	// IPVSMode sets ProxyMode to ipvs
 	IPVSMode ProxyMode = "ipvs"
+	// NFTablesMode sets ProxyMode to nftables
+	// This requires Kubernetes v1.31+, or v1.29+ with the NFTablesProxyMode
+	// feature gate enabled
+	NFTablesMode ProxyMode = "nftables"
 )
 
 // PatchJSON6902 represents an inline kustomize json 6902 patch
@@ -224,6 +327,36 @@ These types are from
 https://github.com/kubernetes/kubernetes/blob/063e7ff358fdc8b0916e6f39beedc0d025734cb1/pkg/kubelet/apis/cri/runtime/v1alpha2/api.pb.go#L183
 */
 
+// HostAlias is an /etc/hosts entry resolving Hostnames to IP, like the
+// Kubernetes PodSpec field of the same name
+type HostAlias struct {
+	// IP is the IPv4 or IPv6 address the hostnames resolve to
+	IP string `yaml:"ip,omitempty"`
+	// Hostnames are the hostnames resolving to IP
+	Hostnames []string `yaml:"hostnames,omitempty"`
+}
+
+// SchedulerProfile is a profile in the kube-scheduler's
+// KubeSchedulerConfiguration
+type SchedulerProfile struct {
+	// SchedulerName is the name pods use to select this profile
+	SchedulerName string `yaml:"schedulerName,omitempty"`
+
+	// Plugins is the profile's `plugins` field, enabling and disabling
+	// plugins per extension point.
+	//
+	// This should be an inline yaml blob-string for the
+	// KubeSchedulerConfiguration apiVersion of the cluster's Kubernetes version
+	Plugins string `yaml:"plugins,omitempty"`
+
+	// PluginConfig is the profile's `pluginConfig` field, a list of
+	// arguments for the plugins.
+	//
+	// This should be an inline yaml blob-string for the
+	// KubeSchedulerConfiguration apiVersion of the cluster's Kubernetes version
+	PluginConfig string `yaml:"pluginConfig,omitempty"`
+}
+
 // Mount specifies a host volume to mount into a container.
 // This is a close copy of the upstream cri Mount type
 // see: k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2
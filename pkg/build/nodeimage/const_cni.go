@@ -20,6 +20,8 @@ package nodeimage
 The default CNI manifest and images are our own tiny kindnet
 */
 
+// NOTE: this kindnetd predates CNI_MTU and ignores it, see
+// cluster.CreateWithNetworkMTU
 var defaultCNIImages = []string{"kindest/kindnetd:v20200725-4d6bea59"}
 
 const defaultCNIManifest = `
@@ -31,6 +31,12 @@ const (
 	// WorkerNodeRoleValue identifies a node that hosts a Kubernetes worker
 	WorkerNodeRoleValue string = "worker"
 
+	// KubeletOnlyNodeRoleValue identifies a node that runs a kubelet but
+	// never joins the cluster
+	//
+	// Please note that `kind` nodes with this role are not kubernetes nodes
+	KubeletOnlyNodeRoleValue string = "kubelet-only"
+
 	// ExternalLoadBalancerNodeRoleValue identifies a node that hosts an
 	// external load balancer for the API server in HA configurations.
 	//
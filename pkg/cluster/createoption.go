@@ -17,10 +17,12 @@ limitations under the License.
 package cluster
 
 import (
+	"io"
 	"time"
 
 	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
+	internalconfig "sigs.k8s.io/kind/pkg/internal/apis/config"
 	internalencoding "sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
 )
 
@@ -44,6 +46,30 @@ func CreateWithConfigFile(path string) CreateOption {
 	})
 }
 
+// CreateWithConfigFiles configures multiple config file paths to merge in
+// order, with fields in later files overriding earlier ones. Maps are merged
+// key by key while lists such as nodes are replaced entirely.
+// This takes precedence over any other config option.
+func CreateWithConfigFiles(paths ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ConfigPaths = paths
+		return nil
+	})
+}
+
+// CreateWithConfigTemplate configures a config file path to render as a Go
+// text/template with vars before use, E.G. {{ .Name }} is replaced with
+// vars["Name"]. Every variable the template references must be set in vars.
+// This takes precedence over any other config option other than
+// CreateWithConfigFiles, which it cannot be combined with.
+func CreateWithConfigTemplate(path string, vars map[string]string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ConfigTemplatePath = path
+		o.ConfigVars = vars
+		return nil
+	})
+}
+
 // CreateWithRawConfig configures the config to use from raw (yaml) bytes
 func CreateWithRawConfig(raw []byte) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
@@ -61,6 +87,23 @@ func CreateWithV1Alpha4Config(config *v1alpha4.Cluster) CreateOption {
 	})
 }
 
+// CreateWithMutateConfig configures a function to modify the cluster config
+// after defaulting and before validation, E.G. to add nodes or change subnets.
+// If mutate returns an error then creating the cluster is aborted.
+func CreateWithMutateConfig(mutate func(*v1alpha4.Cluster) error) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.MutateConfig = func(cfg *internalconfig.Cluster) error {
+			v1alpha4Config := internalencoding.InternalToV1Alpha4(cfg)
+			if err := mutate(v1alpha4Config); err != nil {
+				return err
+			}
+			*cfg = *internalencoding.V1Alpha4ToInternal(v1alpha4Config)
+			return nil
+		}
+		return nil
+	})
+}
+
 // CreateWithNodeImage overrides the image on all nodes in config
 // as an easy way to change the Kubernetes version
 func CreateWithNodeImage(nodeImage string) CreateOption {
@@ -70,6 +113,36 @@ func CreateWithNodeImage(nodeImage string) CreateOption {
 	})
 }
 
+// CreateWithKubeProxyMode overrides the kube-proxy mode in the config:
+// iptables, ipvs, or nftables (which requires a recent Kubernetes version)
+func CreateWithKubeProxyMode(mode string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeProxyMode = mode
+		return nil
+	})
+}
+
+// CreateWithRespectPerNodeImages limits CreateWithNodeImage to only setting
+// the image for nodes that do not have an image set in the config, rather
+// than overriding all nodes, E.G. to deliberately run workers with an older
+// image than the control plane for version skew testing
+func CreateWithRespectPerNodeImages(respect bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.RespectPerNodeImages = respect
+		return nil
+	})
+}
+
+// CreateWithImageResolver sets a function that is applied to every node image
+// after defaulting, before the images are pulled, which may be used to
+// centrally rewrite images (E.G. to a mirror registry, or pinned by digest)
+func CreateWithImageResolver(resolver func(image string) (string, error)) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ImageResolver = resolver
+		return nil
+	})
+}
+
 // CreateWithRetain disables deletion of nodes and any other cleanup
 // that would normally occur after a failure to create
 // This is mainly used for debugging purposes
@@ -80,8 +153,21 @@ func CreateWithRetain(retain bool) CreateOption {
 	})
 }
 
+// CreateWithFailureCleanupDelay configures how long to wait after a failure
+// to create the cluster before deleting it, giving a chance to inspect the
+// failed cluster. By default the cluster is deleted immediately.
+// This has no effect if CreateWithRetain(true) is also used.
+func CreateWithFailureCleanupDelay(delay time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.FailureCleanupDelay = delay
+		return nil
+	})
+}
+
 // CreateWithWaitForReady configures a maximum wait time for the control plane
-// node(s) to be ready. By default no waiting is performed
+// node(s) to be ready. By default no waiting is performed.
+// A zero waitTime skips waiting entirely, the kubeconfig is still exported
+// as soon as the cluster is created so readiness may be checked separately.
 func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
 		o.WaitForReady = waitTime
@@ -89,6 +175,494 @@ func CreateWithWaitForReady(waitTime time.Duration) CreateOption {
 	})
 }
 
+// CreateWithReadyPollInterval configures the minimum time between checks
+// while waiting for the control plane node(s) to be ready, see
+// CreateWithWaitForReady. By default the next check starts as soon as the
+// previous check completes
+func CreateWithReadyPollInterval(interval time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ReadyPollInterval = interval
+		return nil
+	})
+}
+
+// CreateWithAPIServerReadyTimeout configures the maximum time to wait for
+// the API server to be serving before installing the default CNI and storage,
+// by default a short timeout is used.
+func CreateWithAPIServerReadyTimeout(timeout time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.APIServerReadyTimeout = timeout
+		return nil
+	})
+}
+
+// CreateWithVerboseKubeadm enables logging kubeadm's output line by line at
+// V(1) while kubeadm init and join are running, rather than after they finish
+func CreateWithVerboseKubeadm(verbose bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.VerboseKubeadm = verbose
+		return nil
+	})
+}
+
+// CreateWithJoinConcurrency joins at most concurrency worker nodes at the
+// same time, E.G. to avoid overloading the control plane with many workers.
+// By default all of the workers join at once. Every worker is attempted and
+// all of the failures are reported.
+func CreateWithJoinConcurrency(concurrency int) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.JoinConcurrency = concurrency
+		return nil
+	})
+}
+
+// CreateWithImagePullTimeout bounds pulling each node image, including
+// retries, failing creation if the pull takes longer. By default this is
+// twenty minutes.
+func CreateWithImagePullTimeout(timeout time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ImagePullTimeout = timeout
+		return nil
+	})
+}
+
+// CreateWithControlPlaneStagger spaces out starting the control plane node
+// containers by delay, E.G. to bring up the first control plane node before
+// the others. By default they are all started at the same time.
+func CreateWithControlPlaneStagger(delay time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ControlPlaneStagger = delay
+		return nil
+	})
+}
+
+// CreateWithAutoPort picks and reserves the unset published host ports, the
+// API server port and any extraPortMappings with hostPort 0, before creating
+// the nodes. Unlike the default random ports these do not collide between
+// clusters created concurrently on the same host.
+// onAllocated is called with the config including the chosen ports, if set.
+func CreateWithAutoPort(onAllocated func(cfg *v1alpha4.Cluster)) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.AutoPort = true
+		if onAllocated != nil {
+			o.OnAutoPorts = func(cfg *internalconfig.Cluster) {
+				onAllocated(internalconfig.ConvertToV1alpha4(cfg))
+			}
+		}
+		return nil
+	})
+}
+
+// CreateWithJoinCommand enables logging a kubeadm join command after the
+// cluster is created, for joining bare-metal or VM nodes to the cluster.
+// The command uses the API server endpoint published on the host, or the
+// control plane endpoint or external load balancer if set, which must be
+// reachable from those nodes.
+// tokenTTL is the lifetime of the bootstrap token in the command, if zero
+// the kubeadm default is used.
+func CreateWithJoinCommand(tokenTTL time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.PrintJoinCommand = true
+		o.JoinTokenTTL = tokenTTL
+		return nil
+	})
+}
+
+// CreateWithExternalLoadBalancer configures the control plane to use a load
+// balancer managed outside of kind at endpoint (host:port), instead of
+// creating one for clusters with multiple control plane nodes.
+// The endpoint must be reachable from the nodes, and will be included in the
+// API server certificate.
+func CreateWithExternalLoadBalancer(endpoint string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ExternalLoadBalancerEndpoint = endpoint
+		return nil
+	})
+}
+
+// CreateWithHostAddressOverride sets the address the ports published by the
+// container runtime are reachable at, which is used for the API server in the
+// exported kubeconfig and included in its certificate. By default this is the
+// address the runtime reports, or the host of a remote (tcp:// or ssh://)
+// DOCKER_HOST. This is only supported by the docker provider.
+func CreateWithHostAddressOverride(address string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.HostAddressOverride = address
+		return nil
+	})
+}
+
+// CreateWithNodeNameTemplate names the node containers by rendering the
+// text/template nodeNameTemplate instead of the default
+// <cluster>-<role><index> names, E.G. "{{.Cluster}}-{{.Role}}-{{.Index}}".
+// The template may use {{.Cluster}}, {{.Role}} and {{.Index}}, the 1-based
+// index of the node amongst the nodes with the same role. The rendered names
+// must be unique and short enough to be used as host names.
+func CreateWithNodeNameTemplate(nodeNameTemplate string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NodeNameTemplate = nodeNameTemplate
+		return nil
+	})
+}
+
+// CreateWithControlPlaneEndpoint configures the cluster to use endpoint
+// (host:port) as the kubeadm controlPlaneEndpoint for any topology,
+// E.G. a stable DNS name. It is included in the API server certificate,
+// used by the nodes to join, and used as-is in the exported kubeconfig,
+// so it must be reachable from both the nodes and the host.
+func CreateWithControlPlaneEndpoint(endpoint string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ControlPlaneEndpoint = endpoint
+		return nil
+	})
+}
+
+// CreateWithFailOnLowDisk configures creating the cluster to fail if the
+// container runtime may not have enough free disk space for the nodes,
+// by default this is only a warning
+func CreateWithFailOnLowDisk(fail bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.FailOnLowDisk = fail
+		return nil
+	})
+}
+
+// CreateWithFailOnInsufficientResources configures creating the cluster to
+// fail if the host may not have enough CPUs or memory for the nodes,
+// by default this is only a warning
+func CreateWithFailOnInsufficientResources(fail bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.FailOnInsufficientResources = fail
+		return nil
+	})
+}
+
+// CreateWithGracefulNodeShutdown enables the kubelet's graceful node shutdown
+// with the GracefulNodeShutdown feature gate, delaying shutting down each
+// node by gracePeriod for its pods to terminate, of which criticalPods is
+// reserved for critical pods. criticalPods must be at most gracePeriod.
+// This requires Kubernetes v1.20.0 or newer.
+// NOTE: the kubelet delays shutdown with a systemd inhibitor lock, so this
+// only works if the node image runs systemd-logind
+func CreateWithGracefulNodeShutdown(gracePeriod, criticalPods time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ShutdownGracePeriod = gracePeriod
+		o.ShutdownGracePeriodCriticalPods = criticalPods
+		return nil
+	})
+}
+
+// CreateWithControlPlaneLivenessProbe relaxes the API server and etcd
+// liveness probes, which may otherwise restart them in a loop while the
+// cluster is starting on slow hosts. This trades slower detection of a broken
+// control plane for stability. Zero values leave the kubeadm defaults.
+// Both values must be whole seconds of at most 10 minutes.
+// This requires Kubernetes v1.19.0 or newer.
+func CreateWithControlPlaneLivenessProbe(initialDelay, timeout time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ControlPlaneLivenessInitialDelay = initialDelay
+		o.ControlPlaneLivenessTimeout = timeout
+		return nil
+	})
+}
+
+// CreateWithAPIServerRequestTimeout sets the API server's --request-timeout,
+// E.G. for scale testing. If zero the API server default is used.
+// NOTE: extreme values may destabilize the control plane, they are allowed
+// for testing.
+func CreateWithAPIServerRequestTimeout(timeout time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.APIServerRequestTimeout = timeout
+		return nil
+	})
+}
+
+// CreateWithAPIServerWatchCacheSizes sets the API server's watch cache sizes,
+// E.G. for scale testing. defaultSize is --default-watch-cache-size, where
+// zero disables the watch cache and a negative size keeps the API server
+// default. sizes is --watch-cache-sizes, keyed by resource[.group].
+// NOTE: extreme values may destabilize the control plane, they are allowed
+// for testing.
+func CreateWithAPIServerWatchCacheSizes(defaultSize int32, sizes map[string]int32) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.APIServerDefaultWatchCacheSize = nil
+		if defaultSize >= 0 {
+			o.APIServerDefaultWatchCacheSize = &defaultSize
+		}
+		o.APIServerWatchCacheSizes = sizes
+		return nil
+	})
+}
+
+// CreateWithAdmissionPlugins sets the API server's --enable-admission-plugins
+// and --disable-admission-plugins, E.G. for testing webhooks and policies.
+// Disabling admission plugins kind clusters rely on (NodeRestriction and
+// ServiceAccount) fails unless force is true.
+func CreateWithAdmissionPlugins(enable, disable []string, force bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.EnableAdmissionPlugins = enable
+		o.DisableAdmissionPlugins = disable
+		o.ForceDisableAdmissionPlugins = force
+		return nil
+	})
+}
+
+// CreateWithWaitForSystemPods also waits for all of the kube-system
+// deployments and daemonsets (E.G. CoreDNS) to be ready, within the same
+// timeout as CreateWithWaitForReady, which must also be set
+func CreateWithWaitForSystemPods(wait bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.WaitForSystemPods = wait
+		return nil
+	})
+}
+
+// CreateWithSystemPodsExclude does not wait for some of the kube-system
+// workloads with CreateWithWaitForSystemPods, which must also be set.
+// names are deployments or daemonsets by name, or by kind/name (E.G.
+// deployment/coredns), and labels are key or key=value, excluding the
+// workloads with any of them.
+func CreateWithSystemPodsExclude(names, labels []string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SystemPodsExcludeNames = append(o.SystemPodsExcludeNames, names...)
+		o.SystemPodsExcludeLabels = append(o.SystemPodsExcludeLabels, labels...)
+		return nil
+	})
+}
+
+// CreateWithContinueOnNodeFailure waits for all of the nodes to be ready
+// instead of only the control plane, see CreateWithWaitForReady, and then
+// continues creating the cluster without the nodes that are not ready in
+// time, logging the skipped nodes. If minReadyNodes is non-zero creating the
+// cluster fails if fewer nodes than that are ready.
+func CreateWithContinueOnNodeFailure(minReadyNodes int) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ContinueOnNodeFailure = true
+		o.MinReadyNodes = minReadyNodes
+		return nil
+	})
+}
+
+// CreateWithIgnoreNodesForReady does not wait for the named nodes to be
+// ready, see CreateWithWaitForReady, they are still created and joined to
+// the cluster and are logged as not waited on. Nodes are named by their
+// Kubernetes node names, which are their hostnames if set. At least one
+// control plane node must still be waited for.
+func CreateWithIgnoreNodesForReady(nodeNames ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.IgnoreNodesForReady = append(o.IgnoreNodesForReady, nodeNames...)
+		return nil
+	})
+}
+
+// CreateWithCNIReadyCheck also waits for the daemonset namespacedName
+// (namespace/name) of a third party CNI to have all of its pods ready,
+// see CreateWithWaitForReady. By default the CNI is only checked by way of
+// the nodes being ready, which is sufficient for the default CNI.
+func CreateWithCNIReadyCheck(namespacedName string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.CNIReadyDaemonSet = namespacedName
+		return nil
+	})
+}
+
+// CreateWithExpectKubernetesVersion fails creating the cluster if the API
+// server is not running the expected Kubernetes version once it is ready,
+// E.G. when the node image is not the version its tag suggests.
+// A partial version matches any version it prefixes, E.G. v1.19 matches
+// v1.19.1, and the error reports the actual version. The bootstrap control
+// plane node's kubeadm is also checked before running kubeadm init.
+func CreateWithExpectKubernetesVersion(expected string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ExpectKubernetesVersion = expected
+		return nil
+	})
+}
+
+// CreateWithVerifyAPIServerHA fails creating the cluster if any control plane
+// node is not serving a healthy API server once the cluster is ready, checking
+// each node on the address the load balancer routes to and that it is
+// registered as an endpoint of the kubernetes service, and that the kind load
+// balancer has every node as a backend and serves a healthy API server.
+// The health of each node is logged. The load balancer is not checked with
+// CreateWithExternalLoadBalancer, as kind does not manage it.
+// This requires multiple control plane nodes.
+func CreateWithVerifyAPIServerHA(verify bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.VerifyAPIServerHA = verify
+		return nil
+	})
+}
+
+// CreateWithUntaintControlPlane removes the NoSchedule taints from the
+// control plane nodes once the cluster is ready, allowing workloads to be
+// scheduled on them. Clusters with a single node are always untainted.
+func CreateWithUntaintControlPlane(untaint bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.UntaintControlPlane = untaint
+		return nil
+	})
+}
+
+// CreateWithCNIImage replaces the kindnetd image used by the default CNI with
+// image, E.G. a copy in a mirror for air-gapped environments. It should be
+// the same version as the node image's bundled kindnetd, a warning is logged
+// if the tags differ.
+func CreateWithCNIImage(image string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.CNIImage = image
+		return nil
+	})
+}
+
+// CreateWithNodePlatform pulls and runs the node images for platform, an
+// os/arch or os/arch/variant platform (E.G. linux/arm64), instead of the
+// container runtime's own platform. The node images must be available for
+// platform. Other platforms are run under emulation, which must be set up on
+// the host (E.G. with qemu-user-static) and is much slower, so this is only
+// meant for testing other architectures. This is not supported by podman.
+func CreateWithNodePlatform(platform string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NodePlatform = platform
+		return nil
+	})
+}
+
+// CreateWithNetworkMTU sets the MTU of the node network when it is created,
+// and of the default CNI's pod interfaces, E.G. to fit within a VPN or a
+// nested container network. With the docker provider an existing kind
+// network must already have this MTU. This is not supported by podman.
+// NOTE: the pod interface MTU is passed to kindnetd as CNI_MTU, which only
+// kindnetd images built from this tree honor. The kindnetd image shipped in
+// current node images (kindest/kindnetd:v20200725-4d6bea59) ignores it and
+// uses the MTU of the node's eth0, which this option also sets when the
+// node network is created, so the pod MTU only differs from it with a
+// custom kindnetd image (E.G. via CreateWithCNIImage).
+func CreateWithNetworkMTU(mtu int) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NetworkMTU = mtu
+		return nil
+	})
+}
+
+// CreateWithNodeCIDRMaskSize sets the prefix lengths of the pod CIDR the
+// kube-controller-manager assigns each node in ipv4 and ipv6 clusters, only
+// the size for the cluster's IP family may be non-zero, zero uses the
+// kube-controller-manager default. E.G. a /26 for each node in a /24 pod
+// subnet fits 4 nodes, creating the cluster warns if the nodes do not fit.
+func CreateWithNodeCIDRMaskSize(ipv4, ipv6 int32) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NodeCIDRMaskSizeIPv4 = ipv4
+		o.NodeCIDRMaskSizeIPv6 = ipv6
+		return nil
+	})
+}
+
+// CreateWithCoreDNSUpstreams configures CoreDNS to forward to upstreams
+// instead of the nodes' resolv.conf, which may loop back to CoreDNS or be
+// empty in some docker setups. Each upstream is an IP address, optionally
+// with a port (E.G. 1.1.1.1 or [2606:4700:4700::1111]:53). The Corefile is
+// patched in the coredns configmap after kubeadm creates it, so the
+// upstreams are kept when CoreDNS restarts.
+func CreateWithCoreDNSUpstreams(upstreams ...string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.CoreDNSUpstreams = append(o.CoreDNSUpstreams, upstreams...)
+		return nil
+	})
+}
+
+// CreateWithNodeLocalDNS installs the node-local DNS cache once the cluster is
+// ready, configured with the cluster's DNS service IP and domain.
+// This requires the default CNI and kube-proxy in iptables mode.
+func CreateWithNodeLocalDNS(nodeLocalDNS bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NodeLocalDNS = nodeLocalDNS
+		return nil
+	})
+}
+
+// CreateWithAddonApplyRetries sets how many times installing the default CNI,
+// storage and node-local DNS cache is retried with backoff if the API server
+// is not ready yet, E.G. the connection is refused just after it started.
+// Other errors are not retried. If zero a default of 5 retries is used,
+// -1 disables retrying.
+func CreateWithAddonApplyRetries(retries int) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.AddonApplyRetries = retries
+		return nil
+	})
+}
+
+// CreateWithSchedulableControlPlane registers the control plane nodes without
+// the NoSchedule taint, and removes it once the cluster is ready, so that
+// workloads can be scheduled on them. This is intended for single node
+// development clusters, a warning is logged for larger clusters.
+func CreateWithSchedulableControlPlane(schedulable bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SchedulableControlPlane = schedulable
+		return nil
+	})
+}
+
+// CreateWithContainerdVersion replaces the node image's containerd with the
+// release version (E.G. v1.6.8) in every node before Kubernetes is started,
+// E.G. for CRI compatibility testing. One node per architecture must be able
+// to download the release from GitHub, it is verified against the release's
+// sha256sum before being copied to the other nodes. Creating the cluster
+// fails early if the release does not exist, does not match its checksum or
+// is known to be incompatible with the node image's kubelet.
+// This cannot be used with the "hardened" security profile, under which the
+// release cannot be installed.
+// NOTE: this is an advanced option, the node image is only tested with the
+// containerd it ships.
+func CreateWithContainerdVersion(version string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ContainerdVersion = version
+		return nil
+	})
+}
+
+// CreateWithSeedObjects creates objects in the cluster, in order, once it is
+// ready, E.G. Secrets and ConfigMaps workloads expect to exist.
+// Each object must marshal to JSON as a Kubernetes object with an apiVersion,
+// kind, and metadata.name, such as a typed API object with TypeMeta set or a
+// map[string]interface{}. Missing namespaces are created as needed.
+func CreateWithSeedObjects(objects ...interface{}) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SeedObjects = append(o.SeedObjects, objects...)
+		return nil
+	})
+}
+
+// WorkloadRef identifies a workload to wait for, see CreateWithWaitForWorkloads
+type WorkloadRef = internalcreate.WorkloadRef
+
+// CreateWithWaitForWorkloads waits for each workload in order to be ready
+// after creating the seed objects, E.G. an operator's webhook Deployment.
+// Deployments, StatefulSets and DaemonSets are ready once rolled out and Jobs
+// once complete. Each workload is waited for within its own Timeout, and
+// creating the cluster fails with the workload's last status if it is not
+// ready in time.
+func CreateWithWaitForWorkloads(workloads ...WorkloadRef) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.WaitForWorkloads = append(o.WaitForWorkloads, workloads...)
+		return nil
+	})
+}
+
+// CreateWithReadyGate configures what the control plane node(s) must have
+// to be considered ready while waiting, see CreateWithWaitForReady.
+// condition is a node condition type that must be True instead of Ready,
+// and label is a label (key or key=value) the nodes must have in addition.
+// If both are empty the standard Ready condition check is used.
+func CreateWithReadyGate(condition, label string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ReadyCondition = condition
+		o.ReadyLabel = label
+		return nil
+	})
+}
+
 // CreateWithKubeconfigPath sets the explicit --kubeconfig path
 func CreateWithKubeconfigPath(explicitPath string) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
@@ -97,6 +671,81 @@ func CreateWithKubeconfigPath(explicitPath string) CreateOption {
 	})
 }
 
+// CreateWithKubeconfigStandalone configures writing a kubeconfig containing
+// only this cluster's cluster, context, and user to the explicit kubeconfig
+// path (see CreateWithKubeconfigPath), replacing any existing file instead of
+// merging into it
+func CreateWithKubeconfigStandalone(standalone bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeconfigStandalone = standalone
+		return nil
+	})
+}
+
+// CreateWithKubeconfigEncryptionKey configures encrypting the kubeconfig
+// written to the explicit kubeconfig path (see CreateWithKubeconfigPath)
+// with key, a 32 byte AES-256 key that is hex or base64 encoded (E.G. from
+// `openssl rand -hex 32`), replacing any existing file instead of merging
+// into it. The kubeconfig can be decrypted with DecryptKubeconfig, and is
+// left in place when deleting the cluster.
+func CreateWithKubeconfigEncryptionKey(key string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeconfigEncryptionKey = key
+		return nil
+	})
+}
+
+// CreateWithKubeconfigCAPath configures also writing the cluster CA to
+// caPath on the host and referencing it from the exported kubeconfig instead
+// of embedding it, E.G. so that host tooling behind a TLS intercepting proxy
+// can be pointed at a well-known file to trust. The kubeconfig stops working
+// if the file is removed, and it is overwritten on every export.
+func CreateWithKubeconfigCAPath(caPath string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeconfigCAPath = caPath
+		return nil
+	})
+}
+
+// CreateWithKubeconfigInsecureSkipTLSVerify configures the exported
+// kubeconfig to skip verifying the API server certificate if insecure is
+// true. This removes any protection against a man in the middle reading the
+// cluster credentials or impersonating the API server, so it should only be
+// used for throwaway local development clusters when the CA cannot be
+// trusted any other way. It cannot be combined with
+// CreateWithKubeconfigCAPath.
+func CreateWithKubeconfigInsecureSkipTLSVerify(insecure bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeconfigInsecureSkipTLSVerify = insecure
+		return nil
+	})
+}
+
+// CreateWithWarnOnKubeconfigContextClash warns when merging the exported
+// kubeconfig replaces an existing context with the cluster's context name
+// that points at another server or CA, E.G. a stale context left behind by a
+// cluster that no longer exists. This is enabled by default.
+func CreateWithWarnOnKubeconfigContextClash(warn bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.IgnoreKubeconfigContextClash = !warn
+		return nil
+	})
+}
+
+// CreateWithClientCredentials writes the cluster CA certificate and the admin
+// client certificate and key from the admin kubeconfig to the host paths
+// caPath, certPath, and keyPath after creating the cluster, E.G. for tools
+// that do not read kubeconfigs. Empty paths are skipped, and the directory of
+// each path must already exist. The key is only readable by the current user.
+func CreateWithClientCredentials(caPath, certPath, keyPath string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.CACertPath = caPath
+		o.ClientCertPath = certPath
+		o.ClientKeyPath = keyPath
+		return nil
+	})
+}
+
 // CreateWithStopBeforeSettingUpKubernetes enables skipping setting up
 // kubernetes (kubeadm init etc.) after creating node containers
 // This generally shouldn't be used and is only lightly supported, but allows
@@ -108,6 +757,420 @@ func CreateWithStopBeforeSettingUpKubernetes(stopBeforeSettingUpKubernetes bool)
 	})
 }
 
+// CreateWithKubeletConfigVersion overrides the KubeletConfiguration apiVersion
+// (E.G. "kubelet.config.k8s.io/v1beta1") written to each node, which is
+// otherwise detected from the node's Kubernetes version.
+// Currently "kubelet.config.k8s.io/v1beta1" is the only known apiVersion,
+// so this only validates that an explicitly pinned apiVersion is supported.
+func CreateWithKubeletConfigVersion(apiVersion string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeletConfigVersion = apiVersion
+		return nil
+	})
+}
+
+// CreateWithAPIServerAdvertiseAddress sets the address the bootstrap control
+// plane node's API server advertises, instead of the node IP detected by kind.
+// This is useful for multi-homed nodes, the address must be assigned to
+// one of the node's network interfaces.
+func CreateWithAPIServerAdvertiseAddress(address string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.APIServerAdvertiseAddress = address
+		return nil
+	})
+}
+
+// CreateWithOnKubeadmConfig sets a callback that is called with the name and
+// the exact kubeadm config of each node, after patches are applied and before
+// the config is written to the node, E.G. to record the configs used.
+// Calls are not concurrent, but are not in any particular order.
+func CreateWithOnKubeadmConfig(onKubeadmConfig func(nodeName string, config []byte)) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.OnKubeadmConfig = onKubeadmConfig
+		return nil
+	})
+}
+
+// CleanupContext is the cluster passed to the callback set with
+// CreateWithOnBeforeCleanup
+type CleanupContext = internalcreate.CleanupContext
+
+// CreateWithOnBeforeCleanup sets a callback that is called with the error
+// creating the cluster right before the failed cluster is deleted, E.G. to
+// snapshot the nodes' state or collect diagnostics. If the callback returns
+// an error it is logged and the cluster is still deleted. The callback is not
+// called if the cluster is retained, see CreateWithRetain.
+func CreateWithOnBeforeCleanup(onBeforeCleanup func(ctx *CleanupContext, err error) error) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.OnBeforeCleanup = onBeforeCleanup
+		return nil
+	})
+}
+
+// CreateWithCloudProvider configures the kubelet and kube-controller-manager
+// with --cloud-provider, currently only "external" is supported, for testing
+// cloud-controller-manager integrations. Nodes will be registered with the
+// node.cloudprovider.kubernetes.io/uninitialized taint until a
+// cloud-controller-manager is installed and initializes them.
+func CreateWithCloudProvider(cloudProvider string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.CloudProvider = cloudProvider
+		return nil
+	})
+}
+
+// CreateWithDNSDomain sets the cluster's DNS domain for services instead of
+// the default cluster.local, E.G. for multi-cluster service meshes.
+// kubeadm configures CoreDNS and the API server certificate to match.
+func CreateWithDNSDomain(dnsDomain string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.DNSDomain = dnsDomain
+		return nil
+	})
+}
+
+// CreateWithBootstrapToken sets the kubeadm bootstrap token used to join the
+// nodes, instead of the default well known token, and its lifetime if ttl is
+// non-zero, instead of the kubeadm default of 24h.
+// The token must match `[a-z0-9]{6}\.[a-z0-9]{16}`.
+//
+// NOTE: anyone that can reach the API server with the token can join nodes
+// to the cluster until it expires, so avoid long lifetimes for clusters that
+// are reachable by others.
+func CreateWithBootstrapToken(token string, ttl time.Duration) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.BootstrapToken = token
+		o.BootstrapTokenTTL = ttl
+		return nil
+	})
+}
+
+// CreateWithPodSecurityConfig enforces Pod Security Standards from the start,
+// by configuring the API server's PodSecurity admission plugin with the
+// AdmissionConfiguration file at the host path configPath.
+// This requires Kubernetes v1.23.0 or newer.
+func CreateWithPodSecurityConfig(configPath string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.PodSecurityConfigPath = configPath
+		return nil
+	})
+}
+
+// CreateWithKonnectivity routes the API server's traffic to the nodes and
+// pods, E.G. for logs, exec, and webhooks, through konnectivity if enabled,
+// for testing network isolated control planes. The API server's egress
+// selector is configured to use a konnectivity server static pod on the
+// control plane node, and a konnectivity agent DaemonSet connects every node
+// to it once the API server is up.
+// This requires a single control plane node and Kubernetes v1.20.0 or newer.
+// This is an advanced option, most users should not need it.
+func CreateWithKonnectivity(enabled bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Konnectivity = enabled
+		return nil
+	})
+}
+
+// OIDCConfig configures the API server to authenticate OIDC ID tokens,
+// see CreateWithOIDC
+type OIDCConfig = internalcreate.OIDCConfig
+
+// CreateWithOIDC configures the API server to authenticate users with ID
+// tokens from the OIDC issuer in config, E.G. for testing OIDC login flows.
+// The issuer URL must be an https URL and, if config.CAPath is set, that CA
+// is copied to the control plane nodes to verify the issuer.
+// NOTE: this does not set up an identity provider, only the API server side,
+// the issuer must be reachable from the control plane nodes
+func CreateWithOIDC(config OIDCConfig) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.OIDC = config
+		return nil
+	})
+}
+
+// CreateWithNodeCACerts installs the PEM encoded CA certificates at the host
+// paths certPaths into every node's trust store before starting Kubernetes,
+// e.g. so that images can be pulled from a registry with a private CA
+func CreateWithNodeCACerts(certPaths []string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NodeCACerts = certPaths
+		return nil
+	})
+}
+
+// CreateWithSharedImageCache stores docker.io images pulled by any node
+// in the existing, writable host directory cacheDir, so that they are
+// re-used instead of being pulled again by the other nodes and any other
+// cluster using the same cacheDir. The nodes pull through a registry cache
+// container serving cacheDir, which is shared by these clusters and is
+// deleted along with the last of them, cacheDir itself is kept.
+// Images from other registries are not cached.
+// This is not supported by the podman provider.
+func CreateWithSharedImageCache(cacheDir string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SharedImageCache = cacheDir
+		return nil
+	})
+}
+
+// CreateWithNodeLogDir persists the logs of every node (/var/log) on the host
+// for inspection after the cluster is gone, in logDir/<cluster>/<node>.
+// Creating the cluster fails if logDir/<cluster> already exists and is not
+// empty, E.G. from a previous cluster with the same name.
+func CreateWithNodeLogDir(logDir string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.NodeLogDir = logDir
+		return nil
+	})
+}
+
+// CreateWithLocalRegistry creates a local image registry container alongside
+// the cluster, published on the host at localhost:hostPort (5000 if zero).
+// Images pushed to localhost:hostPort may be used in the cluster under the
+// same name, and the registry is documented for tools with the standard
+// local-registry-hosting ConfigMap. The registry is deleted with the cluster.
+// This is currently only supported with docker.
+func CreateWithLocalRegistry(hostPort int32) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.LocalRegistry = true
+		o.LocalRegistryPort = hostPort
+		return nil
+	})
+}
+
+// CreateWithRestartPolicy overrides the node container restart policy, one of:
+// no, always, unless-stopped, on-failure, or on-failure:<max-retries>.
+// E.G. "unless-stopped" allows the cluster to recover after a host reboot.
+//
+// NOTE: node IPs may change when restarted, which may break the cluster,
+// and the API server's host port may change, in which case the kubeconfig
+// must be re-exported with Provider.ExportKubeConfig (kind export kubeconfig)
+func CreateWithRestartPolicy(policy string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.RestartPolicy = policy
+		return nil
+	})
+}
+
+// CreateWithSecurityProfile hardens the node containers, the only supported
+// profile is "hardened", which makes the node root filesystem read-only
+// except for the paths kind and kubeadm need to write.
+//
+// NOTE: this is for security research and is not a security boundary, the
+// nodes are still privileged. Anything writing to other paths on the nodes
+// will fail, E.G. CNIs that install their plugins to /opt/cni/bin, and
+// hostPath volumes outside of /var, /tmp and /run.
+func CreateWithSecurityProfile(profile string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SecurityProfile = profile
+		return nil
+	})
+}
+
+// CreateWithKubeletRootDir sets the kubelet's root directory in the nodes
+// instead of /var/lib/kubelet, E.G. for testing storage drivers. dir must be
+// an absolute path in the nodes, outside of /var it is given a volume.
+//
+// NOTE: some CSI drivers assume the default root directory and will not work.
+func CreateWithKubeletRootDir(dir string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.KubeletRootDir = dir
+		return nil
+	})
+}
+
+// CreateWithExistingNetwork attaches the nodes to the docker network with
+// the ID networkID instead of the network kind manages, E.G. a network
+// created with a custom subnet or MTU. kind never creates or deletes this
+// network, it must exist and its subnets must not overlap the cluster's pod
+// and service subnets. This is not supported by the podman provider.
+func CreateWithExistingNetwork(networkID string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ExistingNetworkID = networkID
+		return nil
+	})
+}
+
+// NetworkSpec is a secondary network, see CreateWithSecondaryNetworks
+type NetworkSpec = internalcreate.NetworkSpec
+
+// SecondaryNetworkInterface returns the name of the network interface in the
+// nodes of the i-th network passed to CreateWithSecondaryNetworks
+func SecondaryNetworkInterface(i int) string {
+	return internalcreate.SecondaryNetworkInterface(i)
+}
+
+// CreateWithSecondaryNetworks attaches the nodes to networks in addition to
+// the primary node network, E.G. for testing Multus, in order so that the
+// interfaces are the same on every node, see SecondaryNetworkInterface.
+// Networks that do not exist are created with their Subnet if set, and are
+// not deleted with the cluster. The subnets must not overlap each other, the
+// primary network's, or the cluster's pod and service subnets.
+// This is not supported by the podman provider.
+func CreateWithSecondaryNetworks(networks ...NetworkSpec) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.SecondaryNetworks = append(o.SecondaryNetworks, networks...)
+		return nil
+	})
+}
+
+// CreateWithNodeExecEnv sets env on every command kind runs in the nodes
+// while creating the cluster, E.G. HTTP_PROXY so that installing add-ons can
+// reach the internet, without changing the environment of the node
+// containers themselves. These take precedence over the node containers'
+// environment, but not over the env kind sets on specific commands. The keys
+// must be valid environment variable names.
+func CreateWithNodeExecEnv(env map[string]string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		if o.NodeExecEnv == nil {
+			o.NodeExecEnv = make(map[string]string, len(env))
+		}
+		for k, v := range env {
+			o.NodeExecEnv[k] = v
+		}
+		return nil
+	})
+}
+
+// CreateWithTimezone sets the timezone of the node containers to the tz
+// database name tz, E.G. "America/New_York", by default the nodes are in UTC.
+// This affects the timestamps of the processes and logs in the nodes, it
+// does not change the timezone Kubernetes uses, E.G. for CronJob schedules.
+func CreateWithTimezone(tz string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Timezone = tz
+		return nil
+	})
+}
+
+// CreateWithDiagnosticsBundle writes a gzipped tarball of the node logs,
+// kubeadm output, and cluster resources to bundlePath after creating the
+// cluster, or failing to create it, for attaching to bug reports
+func CreateWithDiagnosticsBundle(bundlePath string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.DiagnosticsBundlePath = bundlePath
+		return nil
+	})
+}
+
+// CreateWithImageInventory writes the images present in each node's
+// containerd to inventoryPath as JSON after creating the cluster, including
+// their tags and digests, E.G. for generating an SBOM of the cluster
+func CreateWithImageInventory(inventoryPath string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ImageInventoryPath = inventoryPath
+		return nil
+	})
+}
+
+// OnExisting is what to do when creating a cluster whose name is already in use
+type OnExisting = internalcreate.OnExisting
+
+const (
+	// OnExistingFail fails with an *AlreadyExistsError, this is the default
+	OnExistingFail = internalcreate.OnExistingFail
+	// OnExistingReuse keeps the existing cluster as-is and only exports its
+	// kubeconfig, none of the other create options are applied to it
+	OnExistingReuse = internalcreate.OnExistingReuse
+	// OnExistingRecreate deletes the existing cluster and creates a new one
+	OnExistingRecreate = internalcreate.OnExistingRecreate
+)
+
+// AlreadyExistsError is returned by Provider.Create when a cluster with the
+// same name already exists, see CreateWithOnExisting
+type AlreadyExistsError = internalcreate.AlreadyExistsError
+
+// CreateWithOnExisting sets what to do when a cluster with the same name
+// already exists, by default creating fails with an *AlreadyExistsError
+func CreateWithOnExisting(onExisting OnExisting) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.OnExisting = onExisting
+		return nil
+	})
+}
+
+// CreateWithActionVerbosity raises the log verbosity while running the named
+// actions (see CreateWithActions for the known action names), E.G.
+// map[string]int{"kubeadm-init": 3} to debug only kubeadm init.
+// This never lowers the verbosity of the logger.
+func CreateWithActionVerbosity(verbosity map[string]int) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.ActionVerbosity = verbosity
+		return nil
+	})
+}
+
+// CreateWithStopAfterAction stops creating the cluster after running the named
+// action (see CreateWithActions for the known action names), to allow
+// inspecting the cluster in an intermediate state for debugging.
+// The cluster is always retained in this mode, even if creating it fails,
+// and the kubeconfig is not exported.
+func CreateWithStopAfterAction(action string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.StopAfterAction = action
+		return nil
+	})
+}
+
+// CreateWithActions sets the exact ordered list of built-in actions to run
+// after creating the node containers, instead of the default actions.
+//
+// Known actions are: loadbalancer, config, install-ca-certs,
+// install-containerd, kubeadm-init, wait-for-apiserver, configure-coredns,
+// install-cni, install-konnectivity-agent, install-storage, kubeadm-join,
+// label-nodes, local-registry, print-join-command, wait-for-ready,
+// install-node-local-dns, check-version, verify-apiserver-ha,
+// untaint-control-plane, seed-objects, and wait-for-workloads
+//
+// Each action may only be specified once. kubeadm-init must come after
+// loadbalancer (unless using CreateWithExternalLoadBalancer) and config, and
+// install-ca-certs and install-containerd must come before it. All of the
+// other actions must come after kubeadm-init, and label-nodes,
+// verify-apiserver-ha and untaint-control-plane must also come after
+// kubeadm-join. install-ca-certs must be listed if and only if
+// CreateWithNodeCACerts is used, and likewise install-containerd with
+// CreateWithContainerdVersion.
+// This is an advanced option, most users should not need it.
+func CreateWithActions(actions []string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Actions = actions
+		return nil
+	})
+}
+
+// CreateWithPlanOutput writes what would be created to stdout instead of
+// creating the cluster, in format "json" or "yaml". The plan is the resolved
+// cluster config, the actions to run, the node images and the host ports,
+// and is computed without calling the node provider.
+func CreateWithPlanOutput(format string) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.PlanOutput = format
+		return nil
+	})
+}
+
+// CreateWithLogWriter copies each message logged while creating the cluster
+// to w as a line, without terminal color codes, E.G. to assert on the output
+// in tests. The normal user facing messages are always copied, even with a
+// logger that discards them, and the debug messages if enabled on the logger.
+func CreateWithLogWriter(w io.Writer) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.LogWriter = w
+		return nil
+	})
+}
+
+// CreateWithQuiet suppresses all output other than warnings and errors if
+// quiet is true, including the status spinner, the usage, and the salutation
+// regardless of CreateWithDisplayUsage and CreateWithDisplaySalutation,
+// E.G. when embedding kind in another CLI.
+func CreateWithQuiet(quiet bool) CreateOption {
+	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
+		o.Quiet = quiet
+		return nil
+	})
+}
+
 // CreateWithDisplayUsage enables displaying usage if displayUsage is true
 func CreateWithDisplayUsage(displayUsage bool) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
@@ -117,7 +1180,8 @@ func CreateWithDisplayUsage(displayUsage bool) CreateOption {
 }
 
 // CreateWithDisplaySalutation enables display a salutation at the end of create
-// cluster if displaySalutation is true
+// cluster if displaySalutation is true, it is preceded by a blank line only
+// when logging to a smart terminal
 func CreateWithDisplaySalutation(displaySalutation bool) CreateOption {
 	return createOptionAdapter(func(o *internalcreate.ClusterOptions) error {
 		o.DisplaySalutation = displaySalutation
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	internaldelete "sigs.k8s.io/kind/pkg/cluster/internal/delete"
+)
+
+// DeleteOption is a Provider.Delete option
+type DeleteOption interface {
+	apply(*internaldelete.ClusterOptions) error
+}
+
+type deleteOptionAdapter func(*internaldelete.ClusterOptions) error
+
+func (c deleteOptionAdapter) apply(o *internaldelete.ClusterOptions) error {
+	return c(o)
+}
+
+// DeleteWithGracefulDelete configures cordoning and draining all nodes and
+// waiting for volumes to be detached before deleting the cluster if graceful
+// is true, so that finalizers and CSI detach run for clusters using external
+// resources. If this fails or times out the cluster is deleted anyway.
+func DeleteWithGracefulDelete(graceful bool) DeleteOption {
+	return deleteOptionAdapter(func(o *internaldelete.ClusterOptions) error {
+		o.GracefulDelete = graceful
+		return nil
+	})
+}
+
+// DeleteWithGracefulDeleteTimeout sets how long DeleteWithGracefulDelete
+// waits before deleting the cluster anyway, the default is two minutes
+func DeleteWithGracefulDeleteTimeout(timeout time.Duration) DeleteOption {
+	return deleteOptionAdapter(func(o *internaldelete.ClusterOptions) error {
+		o.GracefulTimeout = timeout
+		return nil
+	})
+}
+
+// DeleteWithSnapshotEtcd saves a snapshot of the cluster's etcd to path on
+// the host with etcdctl before deleting the cluster, E.G. for testing backup
+// and restore. If the snapshot cannot be saved the cluster is not deleted.
+func DeleteWithSnapshotEtcd(path string) DeleteOption {
+	return deleteOptionAdapter(func(o *internaldelete.ClusterOptions) error {
+		o.SnapshotEtcdBeforeDelete = path
+		return nil
+	})
+}
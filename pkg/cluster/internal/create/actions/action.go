@@ -39,22 +39,29 @@ type ActionContext struct {
 	Status   *cli.Status
 	Config   *config.Cluster
 	Provider providers.Provider
-	cache    *cachedData
+	// NodeExecEnv is set on every command run in the nodes returned by Nodes
+	NodeExecEnv map[string]string
+	cache       *cachedData
 }
 
 // NewActionContext returns a new ActionContext
+// nodeExecEnv is set on every command the actions run in the nodes if
+// non-empty, overriding the node container's environment, while the env an
+// action sets on a command overrides nodeExecEnv
 func NewActionContext(
 	logger log.Logger,
 	status *cli.Status,
 	provider providers.Provider,
 	cfg *config.Cluster,
+	nodeExecEnv map[string]string,
 ) *ActionContext {
 	return &ActionContext{
-		Logger:   logger,
-		Status:   status,
-		Provider: provider,
-		Config:   cfg,
-		cache:    &cachedData{},
+		Logger:      logger,
+		Status:      status,
+		Provider:    provider,
+		Config:      cfg,
+		NodeExecEnv: nodeExecEnv,
+		cache:       &cachedData{},
 	}
 }
 
@@ -85,6 +92,7 @@ func (ac *ActionContext) Nodes() ([]nodes.Node, error) {
 	if err != nil {
 		return nil, err
 	}
+	n = withEnv(n, ac.NodeExecEnv)
 	ac.cache.setNodes(n)
 	return n, nil
 }
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checkversion implements an action to verify the version of
+// Kubernetes the cluster is running
+package checkversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct {
+	expected string
+}
+
+// NewAction returns a new action for verifying that the API server's
+// version matches expected, see Matches
+func NewAction(expected string) actions.Action {
+	return &action{
+		expected: expected,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Checking Kubernetes version 🔍")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "--raw", "/version",
+	).SetStdout(&out).Run(); err != nil {
+		return errors.Wrap(err, "failed to get the API server version")
+	}
+	serverVersion := struct {
+		GitVersion string `json:"gitVersion"`
+	}{}
+	if err := json.Unmarshal(out.Bytes(), &serverVersion); err != nil {
+		return errors.Wrap(err, "failed to parse the API server version")
+	}
+
+	if !Matches(a.expected, serverVersion.GitVersion) {
+		return errors.Errorf(
+			"the cluster is running Kubernetes %s, expected %s", serverVersion.GitVersion, a.expected,
+		)
+	}
+	ctx.Logger.V(1).Infof("The cluster is running Kubernetes %s", serverVersion.GitVersion)
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// Validate returns an error if expected is not a version, E.G. v1.19 or v1.19.1
+func Validate(expected string) error {
+	if _, err := version.ParseGeneric(expected); err != nil {
+		return errors.Wrapf(err, "invalid expected Kubernetes version %q", expected)
+	}
+	return nil
+}
+
+// Matches returns true if actual is the expected version, or expected is a
+// prefix of actual ending at a version component, E.G. v1.19 matches v1.19.1
+// but not v1.191.0. The leading v is optional in both.
+func Matches(expected, actual string) bool {
+	expected = "v" + strings.TrimPrefix(expected, "v")
+	actual = "v" + strings.TrimPrefix(actual, "v")
+	if actual == expected {
+		return true
+	}
+	if !strings.HasPrefix(actual, expected) {
+		return false
+	}
+	next := actual[len(expected)]
+	return next == '.' || next == '+'
+}
@@ -20,11 +20,19 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"path"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
@@ -35,11 +43,72 @@ import (
 )
 
 // Action implements action for creating the node config files
-type Action struct{}
+type Action struct {
+	opts Options
+	// onKubeadmConfigMu serializes calls to opts.OnKubeadmConfig
+	onKubeadmConfigMu sync.Mutex
+}
+
+// Options configures the config action, the zero value writes the default
+// config for each node
+type Options struct {
+	// KubeletConfigVersion overrides the detected KubeletConfiguration
+	// apiVersion if non-empty
+	KubeletConfigVersion string
+	// AdvertiseAddress overrides the address the bootstrap control plane's
+	// API server advertises if non-empty, it must be assigned to that node
+	AdvertiseAddress string
+	// CloudProvider configures the cluster's cloud provider if non-empty
+	CloudProvider string
+	// DNSDomain overrides the cluster's DNS domain if non-empty
+	DNSDomain string
+	// PodSecurityConfig is the host path of the API server's PodSecurity
+	// admission configuration if non-empty
+	PodSecurityConfig string
+	// Token overrides the well known bootstrap token if non-empty
+	Token string
+	// TokenTTL overrides the bootstrap token's kubeadm default lifetime
+	// if non-zero
+	TokenTTL time.Duration
+	// Schedulable registers the control plane nodes without the NoSchedule
+	// taint
+	Schedulable bool
+	// ExternalLoadBalancer is the endpoint of a load balancer managed
+	// outside of kind if non-empty, it overrides the provider's control
+	// plane endpoint
+	ExternalLoadBalancer string
+	// ControlPlaneEndpoint overrides the control plane endpoint if
+	// non-empty, it must be reachable from the nodes once the API server is up
+	ControlPlaneEndpoint string
+	// Liveness relaxes the control plane liveness probes with kubeadm
+	// patches if not zero valued
+	Liveness kubeadm.LivenessProbeTuning
+	// Shutdown enables the kubelet's graceful node shutdown if not zero valued
+	Shutdown kubeadm.GracefulShutdown
+	// APIServer sets the API server tuning flags if not zero valued
+	APIServer kubeadm.APIServerTuning
+	// OIDC sets the API server OIDC authentication flags if not zero valued,
+	// its CAPath is copied to the control plane nodes if non-empty
+	OIDC kubeadm.OIDCConfig
+	// Konnectivity routes the API server's egress through a konnectivity
+	// server on each control plane node
+	Konnectivity bool
+	// NodeCIDRMaskSize overrides the kube-controller-manager's node CIDR
+	// mask size if non-zero
+	NodeCIDRMaskSize int32
+	// NodeNameTemplate is the template the nodes were named from if
+	// non-empty, it is used to match the nodes to the config
+	NodeNameTemplate string
+	// KubeletRootDir overrides the kubelet's root directory if non-empty
+	KubeletRootDir string
+	// OnKubeadmConfig is called with each node's kubeadm config before it is
+	// written to the node if non-nil
+	OnKubeadmConfig func(nodeName string, config []byte)
+}
 
 // NewAction returns a new action for creating the config files
-func NewAction() actions.Action {
-	return &Action{}
+func NewAction(opts Options) actions.Action {
+	return &Action{opts: opts}
 }
 
 // Execute runs the action
@@ -52,40 +121,149 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
-	controlPlaneEndpoint, err := ctx.Provider.GetAPIServerInternalEndpoint(ctx.Config.Name)
-	if err != nil {
-		return err
+	// the API server must also serve a certificate valid for the endpoint
+	// of an external load balancer and the explicit control plane endpoint
+	var extraCertSANs []string
+	for _, endpoint := range []string{a.opts.ExternalLoadBalancer, a.opts.ControlPlaneEndpoint} {
+		if endpoint == "" {
+			continue
+		}
+		host, _, err := net.SplitHostPort(endpoint)
+		if err != nil {
+			return errors.Wrapf(err, "invalid control plane endpoint %q", endpoint)
+		}
+		extraCertSANs = append(extraCertSANs, host)
+	}
+	// as well as the address the API server is published on the host at,
+	// which may differ from the listen address E.G. for a remote daemon
+	if endpoint, err := ctx.Provider.GetAPIServerEndpoint(ctx.Config.Name); err == nil {
+		if host, _, err := net.SplitHostPort(endpoint); err == nil && host != ctx.Config.Networking.APIServerAddress {
+			extraCertSANs = append(extraCertSANs, host)
+		}
+	}
+	controlPlaneEndpoint := a.opts.ControlPlaneEndpoint
+	if controlPlaneEndpoint == "" {
+		controlPlaneEndpoint = a.opts.ExternalLoadBalancer
+	}
+	if controlPlaneEndpoint == "" {
+		controlPlaneEndpoint, err = ctx.Provider.GetAPIServerInternalEndpoint(ctx.Config.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// read the pod security admission config to copy to the control planes
+	var podSecurityConfig string
+	if a.opts.PodSecurityConfig != "" {
+		contents, err := ioutil.ReadFile(a.opts.PodSecurityConfig)
+		if err != nil {
+			return errors.Wrap(err, "failed to read pod security admission config")
+		}
+		podSecurityConfig = string(contents)
+	}
+
+	// read the OIDC issuer's CA to copy to the control planes
+	var oidcCA string
+	if a.opts.OIDC.CAPath != "" {
+		contents, err := ioutil.ReadFile(a.opts.OIDC.CAPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to read OIDC issuer CA")
+		}
+		oidcCA = string(contents)
+	}
+
+	// the OIDC flags are set along with the API server tuning flags
+	apiServerExtraArgs := a.opts.APIServer.ExtraArgs()
+	if oidcArgs := a.opts.OIDC.ExtraArgs(); len(oidcArgs) > 0 {
+		if apiServerExtraArgs == nil {
+			apiServerExtraArgs = map[string]string{}
+		}
+		for k, v := range oidcArgs {
+			apiServerExtraArgs[k] = v
+		}
+	}
+
+	// the scheduler config is rendered for each control plane's version
+	schedulerProfiles := make([]kubeadm.SchedulerProfile, 0, len(ctx.Config.SchedulerProfiles))
+	for _, p := range ctx.Config.SchedulerProfiles {
+		schedulerProfiles = append(schedulerProfiles, kubeadm.SchedulerProfile{
+			SchedulerName: p.SchedulerName,
+			Plugins:       p.Plugins,
+			PluginConfig:  p.PluginConfig,
+		})
 	}
 
 	// create kubeadm init config
 	fns := []func() error{}
 
+	token := kubeadm.Token
+	if a.opts.Token != "" {
+		token = a.opts.Token
+	}
+	var tokenTTL string
+	if a.opts.TokenTTL > 0 {
+		tokenTTL = a.opts.TokenTTL.String()
+	}
+
 	configData := kubeadm.ConfigData{
 		NodeProvider:         fmt.Sprintf("%s", ctx.Provider),
 		ClusterName:          ctx.Config.Name,
 		ControlPlaneEndpoint: controlPlaneEndpoint,
 		APIBindPort:          common.APIServerInternalPort,
 		APIServerAddress:     ctx.Config.Networking.APIServerAddress,
-		Token:                kubeadm.Token,
+		Token:                token,
+		TokenTTL:             tokenTTL,
 		PodSubnet:            ctx.Config.Networking.PodSubnet,
 		KubeProxyMode:        string(ctx.Config.Networking.KubeProxyMode),
 		ServiceSubnet:        ctx.Config.Networking.ServiceSubnet,
+		DNSDomain:            a.opts.DNSDomain,
 		ControlPlane:         true,
 		IPv6:                 ctx.Config.Networking.IPFamily == "ipv6",
 		FeatureGates:         ctx.Config.FeatureGates,
 		RuntimeConfig:        ctx.Config.RuntimeConfig,
+		// NOTE: this is detected per node if unset
+		KubeletConfigAPIVersion: a.opts.KubeletConfigVersion,
+		CloudProvider:           a.opts.CloudProvider,
+		ExtraCertSANs:           extraCertSANs,
+		PodSecurityConfig:       a.opts.PodSecurityConfig != "",
+		SchedulerConfig:         len(schedulerProfiles) > 0,
+		OIDCCA:                  oidcCA != "",
+		Konnectivity:            a.opts.Konnectivity,
+		APIServerExtraArgs:      apiServerExtraArgs,
+		SchedulableControlPlane: a.opts.Schedulable,
+		KubeletRootDir:          a.opts.KubeletRootDir,
+		NodeCIDRMaskSize:        a.opts.NodeCIDRMaskSize,
+	}
+
+	// graceful node shutdown is behind a feature gate until it is GA
+	if !a.opts.Shutdown.IsZero() {
+		featureGates := make(map[string]bool, len(ctx.Config.FeatureGates)+1)
+		for k, v := range ctx.Config.FeatureGates {
+			featureGates[k] = v
+		}
+		featureGates[kubeadm.GracefulNodeShutdownFeatureGate] = true
+		configData.FeatureGates = featureGates
+		configData.ShutdownGracePeriod = a.opts.Shutdown.GracePeriod.String()
+		if a.opts.Shutdown.CriticalPodsGracePeriod > 0 {
+			configData.ShutdownGracePeriodCriticalPods = a.opts.Shutdown.CriticalPodsGracePeriod.String()
+		}
 	}
 
 	kubeadmConfigPlusPatches := func(node nodes.Node, data kubeadm.ConfigData) func() error {
 		return func() error {
 			data.NodeName = node.String()
-			kubeadmConfig, err := getKubeadmConfig(ctx.Config, data, node)
+			kubeadmConfig, err := getKubeadmConfig(ctx.Logger, ctx.Config, a.opts.NodeNameTemplate, data, node)
 			if err != nil {
 				// TODO(bentheelder): logging here
 				return errors.Wrap(err, "failed to generate kubeadm config content")
 			}
 
 			ctx.Logger.V(2).Infof("Using the following kubeadm config for node %s:\n%s", node.String(), kubeadmConfig)
+			if a.opts.OnKubeadmConfig != nil {
+				a.onKubeadmConfigMu.Lock()
+				a.opts.OnKubeadmConfig(node.String(), []byte(kubeadmConfig))
+				a.onKubeadmConfigMu.Unlock()
+			}
 			return writeKubeadmConfig(kubeadmConfig, node)
 		}
 	}
@@ -96,10 +274,53 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
-	for _, node := range controlPlanes {
+	// the nodes will not be able to join through an external load balancer
+	// they cannot reach, so fail before running kubeadm
+	if a.opts.ExternalLoadBalancer != "" {
+		if err := validateNodeCanReach(controlPlanes[0], a.opts.ExternalLoadBalancer); err != nil {
+			return err
+		}
+	}
+
+	for i, node := range controlPlanes {
 		node := node             // capture loop variable
 		configData := configData // copy config data
+		// only the bootstrap control plane is initialized with kubeadm init
+		if i == 0 && a.opts.AdvertiseAddress != "" {
+			if err := validateNodeHasAddress(node, a.opts.AdvertiseAddress); err != nil {
+				return err
+			}
+			configData.AdvertiseAddress = a.opts.AdvertiseAddress
+		}
 		fns = append(fns, kubeadmConfigPlusPatches(node, configData))
+		if patches := a.opts.Liveness.Patches(); len(patches) > 0 {
+			fns = append(fns, func() error {
+				return writeKubeadmPatches(node, patches)
+			})
+		}
+		if podSecurityConfig != "" {
+			fns = append(fns, func() error {
+				return writePodSecurityConfig(node, podSecurityConfig)
+			})
+		}
+		if len(schedulerProfiles) > 0 {
+			fns = append(fns, func() error {
+				return writeSchedulerConfig(node, schedulerProfiles)
+			})
+		}
+		if oidcCA != "" {
+			fns = append(fns, func() error {
+				if err := nodeutils.WriteFile(node, kubeadm.OIDCCAPath, oidcCA); err != nil {
+					return errors.Wrap(err, "failed to copy OIDC issuer CA to node")
+				}
+				return nil
+			})
+		}
+		if a.opts.Konnectivity {
+			fns = append(fns, func() error {
+				return writeKonnectivityServer(node)
+			})
+		}
 	}
 
 	// then create the kubeadm join config for the worker nodes if any
@@ -124,11 +345,16 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 
 	// if we have containerd config, patch all the nodes concurrently
 	if len(ctx.Config.ContainerdConfigPatches) > 0 || len(ctx.Config.ContainerdConfigPatchesJSON6902) > 0 {
-		// we only want to patch kubernetes nodes
+		// we only want to patch nodes running containerd for kubernetes
 		// this is a cheap workaround to re-use the already listed
 		// workers + control planes
+		kubeletOnly, err := nodeutils.SelectNodesByRole(allNodes, constants.KubeletOnlyNodeRoleValue)
+		if err != nil {
+			return err
+		}
 		kubeNodes := append([]nodes.Node{}, controlPlanes...)
 		kubeNodes = append(kubeNodes, workers...)
+		kubeNodes = append(kubeNodes, kubeletOnly...)
 		fns := make([]func() error, len(kubeNodes))
 		for i, node := range kubeNodes {
 			node := node // capture loop variable
@@ -166,7 +392,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 
 // getKubeadmConfig generates the kubeadm config contents for the cluster
 // by running data through the template and applying patches as needed.
-func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.Node) (path string, err error) {
+func getKubeadmConfig(logger log.Logger, cfg *config.Cluster, nodeNameTemplate string, data kubeadm.ConfigData, node nodes.Node) (path string, err error) {
 	kubeVersion, err := nodeutils.KubeVersion(node)
 	if err != nil {
 		// TODO(bentheelder): logging here
@@ -174,21 +400,49 @@ func getKubeadmConfig(cfg *config.Cluster, data kubeadm.ConfigData, node nodes.N
 	}
 	data.KubernetesVersion = kubeVersion
 
-	// TODO: gross hack!
-	// identify node in config by matching name (since these are named in order)
-	// we should really just streamline the bootstrap code and maintain
-	// this mapping ... something for the next major refactor
-	var configNode *config.Node
-	namer := common.MakeNodeNamer("")
-	for i := range cfg.Nodes {
-		n := &cfg.Nodes[i]
-		nodeSuffix := namer(string(n.Role))
-		if strings.HasSuffix(node.String(), nodeSuffix) {
-			configNode = n
+	if err := kubeadm.ValidateKubeProxyMode(data.KubeProxyMode, kubeVersion, data.FeatureGates); err != nil {
+		return "", err
+	}
+	if data.ShutdownGracePeriod != "" {
+		if err := kubeadm.ValidateGracefulShutdown(kubeVersion); err != nil {
+			return "", errors.Wrapf(err, "cannot configure graceful shutdown on node %s", node.String())
+		}
+	}
+
+	// warn if an explicit kubelet config version may be ignored by this kubelet
+	if data.KubeletConfigAPIVersion != "" {
+		if err := kubeadm.ValidateKubeletConfigAPIVersion(data.KubeletConfigAPIVersion, kubeVersion); err != nil {
+			logger.Warnf("WARNING: node %s may not use the kubelet config: %v", node.String(), err)
 		}
 	}
-	if configNode == nil {
-		return "", errors.Errorf("failed to match node %q to config", node.String())
+
+	configNode, err := common.ConfigNode(cfg, nodeNameTemplate, node.String())
+	if err != nil {
+		return "", err
+	}
+
+	data.ImageGCHighThresholdPercent, data.ImageGCLowThresholdPercent = config.ImageGCThresholds(cfg, configNode)
+
+	data.KubeReserved, data.SystemReserved = config.ReservedResources(cfg, configNode)
+	if err := validateReservedMemory(node, data.KubeReserved, data.SystemReserved); err != nil {
+		return "", err
+	}
+
+	// register the node by its hostname if it is not the container name
+	data.NodeHostname = configNode.Hostname
+
+	// register the node with its topology labels and the labels the kubelet
+	// may set so they are set before anything can be scheduled to it, the
+	// rest are set through the API after the node joins
+	data.NodeLabels, _ = config.NodeLabels(configNode)
+	if data.NodeLabels == nil {
+		data.NodeLabels = map[string]string{}
+	}
+	if configNode.Zone != "" {
+		data.NodeLabels["topology.kubernetes.io/zone"] = configNode.Zone
+	}
+	if configNode.Region != "" {
+		data.NodeLabels["topology.kubernetes.io/region"] = configNode.Region
 	}
 
 	// get the node ip address
@@ -248,6 +502,185 @@ func allPatchesFromConfig(cfg *config.Cluster) (patches []string, jsonPatches []
 	return cfg.KubeadmConfigPatches, cfg.KubeadmConfigPatchesJSON6902
 }
 
+// validateNodeHasAddress returns an error if address is not assigned to
+// one of the node's network interfaces, and is therefore not reachable from
+// the rest of the node network at the node
+func validateNodeHasAddress(node nodes.Node, address string) error {
+	want := net.ParseIP(address)
+	if want == nil {
+		return errors.Errorf("invalid advertise address: %q", address)
+	}
+	lines, err := exec.OutputLines(node.Command("ip", "-o", "addr", "show"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to list addresses for node %s", node.String())
+	}
+	// lines are of the form:
+	// 2: eth0    inet 172.18.0.2/16 brd 172.18.255.255 scope global eth0 ...
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] != "inet" && fields[i] != "inet6" {
+				continue
+			}
+			ip, _, err := net.ParseCIDR(fields[i+1])
+			if err == nil && ip.Equal(want) {
+				return nil
+			}
+		}
+	}
+	return errors.Errorf("advertise address %s is not within any subnet assigned to node %s", address, node.String())
+}
+
+// validateReservedMemory returns an error if the memory reserved for the
+// Kubernetes and OS system daemons is not less than the memory available to
+// node, the kubelet would not have any memory left to allocate to pods
+func validateReservedMemory(node nodes.Node, kubeReserved, systemReserved map[string]string) error {
+	var reserved float64
+	for _, r := range []map[string]string{kubeReserved, systemReserved} {
+		if q, ok := r["memory"]; ok {
+			v, err := config.ParseQuantity(q)
+			if err != nil {
+				return errors.Wrap(err, "invalid reserved memory")
+			}
+			reserved += v
+		}
+	}
+	if reserved == 0 {
+		return nil
+	}
+	limit, err := nodeMemoryLimit(node)
+	if err != nil {
+		return err
+	}
+	if reserved >= float64(limit) {
+		return errors.Errorf("node %s only has %d bytes of memory, but %.0f bytes are reserved for the system daemons", node.String(), limit, reserved)
+	}
+	return nil
+}
+
+// nodeMemoryLimit returns the memory available to node in bytes, which is
+// the lower of the host memory and the container's cgroup memory limit
+func nodeMemoryLimit(node nodes.Node) (int64, error) {
+	lines, err := exec.OutputLines(node.Command("cat", "/proc/meminfo"))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the memory of node %s", node.String())
+	}
+	var limit int64
+	for _, line := range lines {
+		// MemTotal:       16314624 kB
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "MemTotal:" && fields[2] == "kB" {
+			if kb, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				limit = kb * 1024
+			}
+		}
+	}
+	if limit == 0 {
+		return 0, errors.Errorf("failed to get the memory of node %s: no MemTotal in /proc/meminfo", node.String())
+	}
+	// the limit is "max" on cgroup v2, or a huge number on cgroup v1 if unset
+	for _, path := range []string{"/sys/fs/cgroup/memory.max", "/sys/fs/cgroup/memory/memory.limit_in_bytes"} {
+		lines, err := exec.OutputLines(node.Command("cat", path))
+		if err != nil || len(lines) != 1 {
+			continue
+		}
+		if v, err := strconv.ParseInt(lines[0], 10, 64); err == nil && v < limit {
+			limit = v
+		}
+		break
+	}
+	return limit, nil
+}
+
+// validateNodeCanReach returns an error if node cannot open a TCP connection
+// to endpoint (host:port)
+func validateNodeCanReach(node nodes.Node, endpoint string) error {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "invalid endpoint %q", endpoint)
+	}
+	// bash's /dev/tcp avoids depending on any other tools in the node image
+	if err := node.Command(
+		"timeout", "5", "bash", "-c", `exec 3<>"/dev/tcp/$0/$1"`, host, port,
+	).Run(); err != nil {
+		return errors.Wrapf(err, "node %s cannot reach the control plane endpoint %s", node.String(), endpoint)
+	}
+	return nil
+}
+
+// writeKubeadmPatches writes the kubeadm patch files to kubeadm.PatchesDir
+// in the specified node, if the node's kubeadm supports patches
+func writeKubeadmPatches(node nodes.Node, patches map[string]string) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	if _, err := kubeadm.PatchesFlag(kubeVersion); err != nil {
+		return errors.Wrapf(err, "cannot tune the liveness probes on node %s", node.String())
+	}
+	for name, patch := range patches {
+		if err := nodeutils.WriteFile(node, path.Join(kubeadm.PatchesDir, name), patch); err != nil {
+			return errors.Wrap(err, "failed to copy kubeadm patches to node")
+		}
+	}
+	return nil
+}
+
+// writePodSecurityConfig writes the API server's pod security admission
+// config to kubeadm.PodSecurityConfigPath in the specified node, if the
+// node's Kubernetes version supports it
+func writePodSecurityConfig(node nodes.Node, podSecurityConfig string) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	if err := kubeadm.ValidatePodSecurityAdmission(kubeVersion); err != nil {
+		return errors.Wrapf(err, "cannot configure pod security admission on node %s", node.String())
+	}
+	if err := nodeutils.WriteFile(node, kubeadm.PodSecurityConfigPath, podSecurityConfig); err != nil {
+		return errors.Wrap(err, "failed to copy pod security admission config to node")
+	}
+	return nil
+}
+
+// writeSchedulerConfig writes the kube-scheduler config with profiles to
+// kubeadm.SchedulerConfigPath in the specified node, using the
+// KubeSchedulerConfiguration apiVersion of the node's Kubernetes version
+func writeSchedulerConfig(node nodes.Node, profiles []kubeadm.SchedulerProfile) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	schedulerConfig, err := kubeadm.SchedulerConfig(kubeVersion, profiles)
+	if err != nil {
+		return errors.Wrapf(err, "cannot configure scheduler profiles on node %s", node.String())
+	}
+	if err := nodeutils.WriteFile(node, kubeadm.SchedulerConfigPath, schedulerConfig); err != nil {
+		return errors.Wrap(err, "failed to copy scheduler config to node")
+	}
+	return nil
+}
+
+// writeKonnectivityServer writes the API server's egress selector config
+// to kubeadm.EgressSelectorConfigPath and the konnectivity server static pod
+// to kubeadm.KonnectivityServerManifestPath in the specified node
+func writeKonnectivityServer(node nodes.Node) error {
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	if err := kubeadm.ValidateKonnectivity(kubeVersion); err != nil {
+		return errors.Wrapf(err, "cannot configure konnectivity on node %s", node.String())
+	}
+	if err := nodeutils.WriteFile(node, kubeadm.EgressSelectorConfigPath, kubeadm.EgressSelectorConfig); err != nil {
+		return errors.Wrap(err, "failed to copy egress selector config to node")
+	}
+	if err := nodeutils.WriteFile(node, kubeadm.KonnectivityServerManifestPath, kubeadm.KonnectivityServerManifest()); err != nil {
+		return errors.Wrap(err, "failed to copy konnectivity server manifest to node")
+	}
+	return nil
+}
+
 // writeKubeadmConfig writes the kubeadm configuration in the specified node
 func writeKubeadmConfig(kubeadmConfig string, node nodes.Node) error {
 	// copy the config to the node
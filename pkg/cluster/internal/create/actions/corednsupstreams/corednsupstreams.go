@@ -0,0 +1,137 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package corednsupstreams implements an action to configure the upstream
+// DNS servers CoreDNS forwards to
+package corednsupstreams
+
+import (
+	"encoding/json"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// MaxUpstreams is the most upstream servers CoreDNS can forward to
+const MaxUpstreams = 15
+
+// forwardRE matches the kubeadm Corefile forwarding to the node's
+// resolv.conf, older CoreDNS versions use the proxy plugin instead
+var forwardRE = regexp.MustCompile(`(?m)^(\s*)(forward|proxy) \. /etc/resolv\.conf`)
+
+type action struct {
+	upstreams []string
+	retries   int
+}
+
+// NewAction returns a new action for configuring CoreDNS to forward to
+// upstreams instead of the node's resolv.conf, see ValidateUpstream.
+// retries bounds retrying while the API server is not ready,
+// see actions.ActionContext.RetryApply
+func NewAction(upstreams []string, retries int) actions.Action {
+	return &action{
+		upstreams: upstreams,
+		retries:   retries,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Configuring CoreDNS upstreams 🧭")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// the Corefile is stored in the configmap kubeadm creates, so the
+	// change is kept when CoreDNS restarts, and picked up by its reload
+	// plugin without restarting it
+	var corefile string
+	if err := ctx.RetryApply(a.retries, func() error {
+		out, err := exec.Output(node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"--namespace=kube-system",
+			"get", "configmap", "coredns",
+			"-o=jsonpath={.data.Corefile}",
+		))
+		corefile = string(out)
+		return err
+	}); err != nil {
+		return errors.Wrap(err, "failed to get the CoreDNS Corefile")
+	}
+	patched, err := PatchCorefile(corefile, a.upstreams)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"data": map[string]string{"Corefile": patched},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the CoreDNS Corefile")
+	}
+	if err := node.Command(
+		"kubectl",
+		"--kubeconfig=/etc/kubernetes/admin.conf",
+		"--namespace=kube-system",
+		"patch", "configmap", "coredns",
+		"--type=merge", "--patch", string(patch),
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to configure the CoreDNS upstreams")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// PatchCorefile returns corefile forwarding to upstreams instead of
+// /etc/resolv.conf, or an error if it does not forward there
+func PatchCorefile(corefile string, upstreams []string) (string, error) {
+	if !forwardRE.MatchString(corefile) {
+		return "", errors.New("the CoreDNS Corefile does not forward to /etc/resolv.conf")
+	}
+	return forwardRE.ReplaceAllString(corefile, "${1}${2} . "+strings.Join(upstreams, " ")), nil
+}
+
+// ValidateUpstream returns an error if upstream is not an IP address,
+// optionally with a port (E.G. 8.8.8.8:53 or [2001:4860:4860::8888]:53)
+func ValidateUpstream(upstream string) error {
+	if net.ParseIP(upstream) != nil {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(upstream)
+	if err != nil || net.ParseIP(host) == nil {
+		return errors.Errorf("invalid CoreDNS upstream %q: must be an IP address with an optional port", upstream)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return errors.Errorf("invalid CoreDNS upstream %q: %q is not a valid port", upstream, port)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// envNode wraps a nodes.Node, setting env on every command run in the node
+type envNode struct {
+	nodes.Node
+	env []string
+}
+
+var _ nodes.Node = &envNode{}
+
+// Command is part of the exec.Cmder interface
+func (n *envNode) Command(command string, args ...string) exec.Cmd {
+	return newEnvCmd(n.Node.Command(command, args...), n.env)
+}
+
+// CommandContext is part of the exec.Cmder interface
+func (n *envNode) CommandContext(ctx context.Context, command string, args ...string) exec.Cmd {
+	return newEnvCmd(n.Node.CommandContext(ctx, command, args...), n.env)
+}
+
+// envCmd wraps an exec.Cmd, keeping env when the caller sets its own, which
+// takes precedence as it is set last
+type envCmd struct {
+	exec.Cmd
+	env []string
+}
+
+var _ exec.Cmd = &envCmd{}
+
+func newEnvCmd(cmd exec.Cmd, env []string) exec.Cmd {
+	cmd.SetEnv(env...)
+	return &envCmd{Cmd: cmd, env: env}
+}
+
+// SetEnv is part of the exec.Cmd interface
+func (c *envCmd) SetEnv(env ...string) exec.Cmd {
+	c.Cmd.SetEnv(append(append([]string{}, c.env...), env...)...)
+	return c
+}
+
+// SetStdin is part of the exec.Cmd interface
+func (c *envCmd) SetStdin(r io.Reader) exec.Cmd {
+	c.Cmd.SetStdin(r)
+	return c
+}
+
+// SetStdout is part of the exec.Cmd interface
+func (c *envCmd) SetStdout(w io.Writer) exec.Cmd {
+	c.Cmd.SetStdout(w)
+	return c
+}
+
+// SetStderr is part of the exec.Cmd interface
+func (c *envCmd) SetStderr(w io.Writer) exec.Cmd {
+	c.Cmd.SetStderr(w)
+	return c
+}
+
+// withEnv returns allNodes wrapped to run every command with env, or
+// allNodes as is if env is empty
+func withEnv(allNodes []nodes.Node, env map[string]string) []nodes.Node {
+	if len(env) == 0 {
+		return allNodes
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+env[k])
+	}
+	wrapped := make([]nodes.Node, 0, len(allNodes))
+	for _, n := range allNodes {
+		wrapped = append(wrapped, &envNode{Node: n, env: pairs})
+	}
+	return wrapped
+}
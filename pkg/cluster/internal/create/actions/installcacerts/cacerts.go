@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installcacerts implements the action to install additional CA
+// certificates into the nodes' trust store
+package installcacerts
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// certsDir is where the certificates are written on the node, for
+// update-ca-certificates to pick up
+const certsDir = "/usr/local/share/ca-certificates"
+
+type action struct {
+	certPaths []string
+}
+
+// NewAction returns a new action for installing the CA certificates
+// at certPaths on the host into every node
+func NewAction(certPaths []string) actions.Action {
+	return &action{
+		certPaths: certPaths,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Installing CA certificates 🔐")
+	defer ctx.Status.End(false)
+
+	// read all the certificates up front
+	certs := make([]string, 0, len(a.certPaths))
+	for _, path := range a.certPaths {
+		cert, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read CA certificate %q", path)
+		}
+		certs = append(certs, string(cert))
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubeNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// install into all the nodes concurrently
+	fns := make([]func() error, 0, len(kubeNodes))
+	for _, node := range kubeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return installCerts(node, certs)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+func installCerts(node nodes.Node, certs []string) error {
+	for i, cert := range certs {
+		dest := fmt.Sprintf("%s/kind-ca-%d.crt", certsDir, i)
+		if err := nodeutils.WriteFile(node, dest, cert); err != nil {
+			return errors.Wrapf(err, "failed to write CA certificate to node %s", node.String())
+		}
+	}
+	if err := node.Command("update-ca-certificates").Run(); err != nil {
+		return errors.Wrapf(err, "failed to update CA certificates on node %s", node.String())
+	}
+	// restart containerd so image pulls trust the new certificates
+	// skip if the systemd (also the containerd) is not running
+	if err := node.Command("bash", "-c", `! systemctl is-system-running || systemctl restart containerd`).Run(); err != nil {
+		return errors.Wrapf(err, "failed to restart containerd on node %s", node.String())
+	}
+	return nil
+}
+
+// ValidateCertFile returns an error if the file at path does not contain
+// only PEM encoded x509 certificates
+func ValidateCertFile(path string) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	found := false
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return errors.Errorf("%q contains a PEM block of type %q, not CERTIFICATE", path, block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return errors.Wrapf(err, "%q contains an invalid certificate", path)
+		}
+		found = true
+	}
+	if !found {
+		return errors.Errorf("%q does not contain any PEM encoded certificates", path)
+	}
+	return nil
+}
@@ -19,6 +19,8 @@ package installcni
 
 import (
 	"bytes"
+	"fmt"
+	"regexp"
 	"strings"
 	"text/template"
 
@@ -28,11 +30,24 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
-type action struct{}
+type action struct {
+	image   string
+	mtu     int
+	retries int
+}
 
 // NewAction returns a new action for installing default CNI
-func NewAction() actions.Action {
-	return &action{}
+// image replaces the kindnetd image in the manifest if non-empty
+// mtu sets the pod interface MTU if non-zero, otherwise kindnetd uses the
+// node's MTU
+// retries bounds retrying the install while the API server is not ready,
+// see actions.ActionContext.RetryApply
+func NewAction(image string, mtu, retries int) actions.Action {
+	return &action{
+		image:   image,
+		mtu:     mtu,
+		retries: retries,
+	}
 }
 
 // Execute runs the action
@@ -83,11 +98,37 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		manifest = out.String()
 	}
 
-	// install the manifest
-	if err := node.Command(
-		"kubectl", "create", "--kubeconfig=/etc/kubernetes/admin.conf",
-		"-f", "-",
-	).SetStdin(strings.NewReader(manifest)).Run(); err != nil {
+	// use the configured kindnetd image, E.G. from a mirror
+	if a.image != "" {
+		bundled, replaced, err := replaceImage(manifest, a.image)
+		if err != nil {
+			return err
+		}
+		if tag(bundled) != tag(a.image) {
+			ctx.Logger.Warnf(
+				"WARNING: CNI image %s does not match the node image's bundled %s, it may not be compatible",
+				a.image, bundled,
+			)
+		}
+		manifest = replaced
+	}
+
+	// configure the pod interface MTU
+	if a.mtu > 0 {
+		manifest, err = setMTU(manifest, a.mtu)
+		if err != nil {
+			return err
+		}
+	}
+
+	// install the manifest, with apply so that retries after a partial
+	// install do not fail on the objects that were already created
+	if err := ctx.RetryApply(a.retries, func() error {
+		return node.Command(
+			"kubectl", "apply", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"-f", "-",
+		).SetStdin(strings.NewReader(manifest)).Run()
+	}); err != nil {
 		return errors.Wrap(err, "failed to apply overlay network")
 	}
 
@@ -95,3 +136,47 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.End(true)
 	return nil
 }
+
+// kindnetdImageRE matches the kindnetd container image in the CNI manifest
+var kindnetdImageRE = regexp.MustCompile(`(?m)^(\s*(?:- )?image:\s*)"?(\S*kindnetd\S*?)"?\s*$`)
+
+// replaceImage returns the kindnetd image bundled in manifest, and manifest
+// with it replaced by image
+func replaceImage(manifest, image string) (bundled, replaced string, err error) {
+	match := kindnetdImageRE.FindStringSubmatch(manifest)
+	if match == nil {
+		return "", "", errors.New("cannot replace the CNI image: the node image's CNI manifest does not use kindnetd")
+	}
+	return match[2], kindnetdImageRE.ReplaceAllString(manifest, "${1}"+image), nil
+}
+
+// kindnetdEnvRE matches the env of the kindnetd container in the CNI manifest,
+// along with the prefix of its first item
+var kindnetdEnvRE = regexp.MustCompile(`(?m)^\s*env:[ \t]*\n([ \t]*- )`)
+
+// setMTU returns manifest with the kindnetd CNI_MTU env set to mtu
+// NOTE: kindnetd images before CNI_MTU was added, including the one in
+// current node images, ignore it and use the MTU of the node's eth0
+func setMTU(manifest string, mtu int) (string, error) {
+	if !kindnetdImageRE.MatchString(manifest) {
+		return "", errors.New("cannot set the CNI MTU: the node image's CNI manifest does not use kindnetd")
+	}
+	match := kindnetdEnvRE.FindStringSubmatchIndex(manifest)
+	if match == nil {
+		return "", errors.New("cannot set the CNI MTU: the node image's CNI manifest has no kindnetd env")
+	}
+	// insert an item before the first item, with the same indentation
+	prefix := manifest[match[2]:match[3]]
+	indent := strings.Repeat(" ", len(prefix))
+	env := fmt.Sprintf("%sname: CNI_MTU\n%svalue: \"%d\"\n", prefix, indent, mtu)
+	return manifest[:match[2]] + env + manifest[match[2]:], nil
+}
+
+// tag returns the tag of the image reference, if any
+func tag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installcontainerd implements the action to replace the nodes'
+// containerd with a specific release
+package installcontainerd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// releaseURL is the URL of the containerd release tarball, formatted with
+// the version (without the leading v) and architecture, the release's
+// sha256sum file is at the same URL with checksumSuffix
+const releaseURL = "https://github.com/containerd/containerd/releases/download/v%[1]s/containerd-%[1]s-linux-%[2]s.tar.gz"
+
+// checksumSuffix is appended to releaseURL for the release's sha256sum file
+const checksumSuffix = ".sha256sum"
+
+// tarballPath is where the release tarball is staged in the nodes
+const tarballPath = "/tmp/containerd-release.tar.gz"
+
+type action struct {
+	version string
+}
+
+// NewAction returns a new action for installing the containerd release
+// version into every node, replacing the node image's containerd
+func NewAction(version string) actions.Action {
+	return &action{
+		version: version,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start(fmt.Sprintf("Installing containerd %s 📦", a.version))
+	defer ctx.Status.End(false)
+
+	containerdVersion, err := version.ParseSemantic(a.version)
+	if err != nil {
+		return errors.Wrapf(err, "invalid containerd version %q", a.version)
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	kubeNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// check compatibility before changing anything
+	for _, node := range kubeNodes {
+		kubeVersion, err := nodeutils.KubeVersion(node)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get kubernetes version from node %s", node.String())
+		}
+		if err := validateCompatibility(containerdVersion, kubeVersion); err != nil {
+			return err
+		}
+	}
+
+	// stage the verified release in every node before changing anything,
+	// downloading it once per architecture and copying it to the other nodes
+	byArch := map[string][]nodes.Node{}
+	var arches []string
+	for _, node := range kubeNodes {
+		arch, err := nodeArch(node)
+		if err != nil {
+			return err
+		}
+		if _, ok := byArch[arch]; !ok {
+			arches = append(arches, arch)
+		}
+		byArch[arch] = append(byArch[arch], node)
+	}
+	for _, arch := range arches {
+		if err := stage(byArch[arch], containerdVersion, arch); err != nil {
+			return err
+		}
+	}
+
+	// install into all the nodes concurrently
+	fns := make([]func() error, 0, len(kubeNodes))
+	for _, node := range kubeNodes {
+		node := node // capture loop variable
+		fns = append(fns, func() error {
+			return install(node, containerdVersion)
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// validateCompatibility returns an error if the kubelet at kubeVersion
+// cannot use containerd at containerdVersion over the CRI
+// The kubelet requires the CRI v1 API served by containerd 1.6+ since
+// Kubernetes v1.26.0, and containerd 2.0+ no longer serves the v1alpha2 API
+// used by kubelets before v1.23.0
+func validateCompatibility(containerdVersion *version.Version, kubeVersion string) error {
+	kv, err := version.ParseGeneric(kubeVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", kubeVersion)
+	}
+	if !kv.LessThan(version.MustParseSemantic("v1.26.0")) && containerdVersion.LessThan(version.MustParseSemantic("v1.6.0")) {
+		return errors.Errorf("containerd %s is not compatible with Kubernetes %s, which requires containerd v1.6.0 or newer", containerdVersion, kubeVersion)
+	}
+	if kv.LessThan(version.MustParseSemantic("v1.23.0")) && !containerdVersion.LessThan(version.MustParseSemantic("v2.0.0-alpha.0")) {
+		return errors.Errorf("containerd %s is not compatible with Kubernetes %s, which requires containerd older than v2.0.0", containerdVersion, kubeVersion)
+	}
+	return nil
+}
+
+// nodeArch returns the node's architecture as named by containerd releases
+func nodeArch(node nodes.Node) (string, error) {
+	lines, err := exec.OutputLines(node.Command("uname", "-m"))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get architecture of node %s", node.String())
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("architecture should only be one line, got %d lines", len(lines))
+	}
+	// containerd releases use the GOARCH names
+	arch := lines[0]
+	switch arch {
+	case "x86_64":
+		arch = "amd64"
+	case "aarch64":
+		arch = "arm64"
+	}
+	return arch, nil
+}
+
+// stage downloads the containerd release for arch to tarballPath in the
+// first of targets, verifies it against the release's sha256sum and copies
+// it to the rest of targets, verifying each copy
+func stage(targets []nodes.Node, v *version.Version, arch string) error {
+	url := fmt.Sprintf(releaseURL, v.String(), arch)
+	source := targets[0]
+	sumFile, err := exec.Output(source.Command("curl", "-fsSL", url+checksumSuffix))
+	if err != nil {
+		return errors.Errorf("containerd version %s does not exist or could not be downloaded from %s", v, url)
+	}
+	sum, err := parseSHA256Sum(string(sumFile))
+	if err != nil {
+		return errors.Wrapf(err, "invalid checksum for containerd release %s", url)
+	}
+	if err := source.Command("curl", "-fsSL", "-o", tarballPath, url).Run(); err != nil {
+		return errors.Errorf("containerd version %s does not exist or could not be downloaded from %s", v, url)
+	}
+	if err := verify(source, sum); err != nil {
+		return err
+	}
+	for _, node := range targets[1:] {
+		if err := nodeutils.CopyNodeToNode(source, node, tarballPath); err != nil {
+			return errors.Wrapf(err, "failed to copy containerd release to node %s", node.String())
+		}
+		if err := verify(node, sum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sha256SumRE matches a sha256 digest in hex
+var sha256SumRE = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// parseSHA256Sum returns the digest from the contents of a sha256sum file
+// for a single file, E.G. "<digest>  containerd-1.6.8-linux-amd64.tar.gz"
+func parseSHA256Sum(content string) (string, error) {
+	fields := strings.Fields(content)
+	if len(fields) != 2 || !sha256SumRE.MatchString(fields[0]) {
+		return "", errors.Errorf("unexpected sha256sum file contents: %q", content)
+	}
+	return fields[0], nil
+}
+
+// verify returns an error if the staged release in the node does not have
+// the sha256 digest sum
+func verify(node nodes.Node, sum string) error {
+	if err := node.Command(
+		"bash", "-c", `echo "$0  $1" | sha256sum --check --status`, sum, tarballPath,
+	).Run(); err != nil {
+		return errors.Errorf("containerd release downloaded to node %s does not match its checksum %s", node.String(), sum)
+	}
+	return nil
+}
+
+// install replaces the node's containerd binaries with the staged release
+// and restarts it, verifying the installed version
+func install(node nodes.Node, v *version.Version) error {
+	if err := node.Command("tar", "-C", "/usr/local", "-xzf", tarballPath).Run(); err != nil {
+		return errors.Wrapf(err, "failed to install containerd on node %s", node.String())
+	}
+	if err := node.Command("rm", "-f", tarballPath).Run(); err != nil {
+		return errors.Wrapf(err, "failed to remove containerd release from node %s", node.String())
+	}
+	if err := node.Command("systemctl", "restart", "containerd").Run(); err != nil {
+		return errors.Wrapf(err, "failed to restart containerd on node %s", node.String())
+	}
+	lines, err := exec.OutputLines(node.Command("containerd", "--version"))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get containerd version on node %s", node.String())
+	}
+	if len(lines) != 1 || !strings.Contains(lines[0], " v"+v.String()+" ") {
+		return errors.Errorf("unexpected containerd version on node %s: %v", node.String(), lines)
+	}
+	return nil
+}
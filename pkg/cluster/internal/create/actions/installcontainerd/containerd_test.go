@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installcontainerd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateCompatibility(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		ContainerdVersion string
+		KubeVersion       string
+		ExpectError       bool
+	}{
+		{Name: "containerd 1.5 with v1.25", ContainerdVersion: "v1.5.13", KubeVersion: "v1.25.3"},
+		{Name: "containerd 1.5 with v1.26", ContainerdVersion: "v1.5.13", KubeVersion: "v1.26.0", ExpectError: true},
+		{Name: "containerd 1.6 with v1.26", ContainerdVersion: "v1.6.8", KubeVersion: "v1.26.0"},
+		{Name: "containerd 1.6 with v1.19", ContainerdVersion: "v1.6.8", KubeVersion: "v1.19.1"},
+		{Name: "containerd 2.0 with v1.22", ContainerdVersion: "v2.0.0", KubeVersion: "v1.22.17", ExpectError: true},
+		{Name: "containerd 2.0 prerelease with v1.22", ContainerdVersion: "v2.0.0-rc.1", KubeVersion: "v1.22.17", ExpectError: true},
+		{Name: "containerd 2.0 with v1.23", ContainerdVersion: "v2.0.0", KubeVersion: "v1.23.0"},
+		{Name: "containerd 2.0 with v1.30", ContainerdVersion: "v2.0.0", KubeVersion: "v1.30.2"},
+		{Name: "bogus kubernetes version", ContainerdVersion: "v1.6.8", KubeVersion: "bogus", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := validateCompatibility(version.MustParseSemantic(tc.ContainerdVersion), tc.KubeVersion)
+			assert.ExpectError(t, tc.ExpectError, err)
+		})
+	}
+}
+
+func TestParseSHA256Sum(t *testing.T) {
+	t.Parallel()
+	const sum = "2d3a3b3e4e1d5cbbb1c3c0d8e4b6ef6d1a8e8b2e0e3f2b1c4d5e6f708192a3b4"
+	cases := []struct {
+		Name        string
+		Content     string
+		Expected    string
+		ExpectError bool
+	}{
+		{Name: "sha256sum output", Content: sum + "  containerd-1.6.8-linux-amd64.tar.gz\n", Expected: sum},
+		{Name: "binary mode", Content: sum + " *containerd-1.6.8-linux-amd64.tar.gz", Expected: sum},
+		{Name: "empty", ExpectError: true},
+		{Name: "digest only", Content: sum, ExpectError: true},
+		{Name: "short digest", Content: sum[:63] + "  containerd-1.6.8-linux-amd64.tar.gz", ExpectError: true},
+		{Name: "multiple files", Content: sum + "  a.tar.gz\n" + sum + "  b.tar.gz\n", ExpectError: true},
+		{Name: "html", Content: "<html><body>Not Found</body></html>", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			sum, err := parseSHA256Sum(tc.Content)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, sum)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package installkonnectivity implements an action to install the
+// konnectivity agents
+package installkonnectivity
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct {
+	retries int
+}
+
+// NewAction returns a new action for installing the konnectivity agents,
+// which connect to the konnectivity server the config action configured on
+// the bootstrap control plane node.
+// retries bounds retrying the install while the API server is not ready,
+// see actions.ActionContext.RetryApply
+func NewAction(retries int) actions.Action {
+	return &action{
+		retries: retries,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Installing konnectivity agents 🔌")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// the agents verify the server's API server certificate, which is valid
+	// for the node's address
+	ipv4, ipv6, err := node.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get IP for node")
+	}
+	serverHost := ipv4
+	if ctx.Config.Networking.IPFamily == config.IPv6Family {
+		serverHost = ipv6
+	}
+	if serverHost == "" {
+		return errors.Errorf("failed to get the %s address of node %s", ctx.Config.Networking.IPFamily, node.String())
+	}
+
+	manifest := strings.NewReplacer(
+		"__IMAGE__", kubeadm.KonnectivityAgentImage,
+		"__SERVER_HOST__", serverHost,
+		"__SERVER_PORT__", fmt.Sprint(kubeadm.KonnectivityAgentPort),
+	).Replace(manifestTemplate)
+	if err := ctx.RetryApply(a.retries, func() error {
+		cmd := node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+		)
+		cmd.SetStdin(strings.NewReader(manifest))
+		return cmd.Run()
+	}); err != nil {
+		return errors.Wrap(err, "failed to install konnectivity agents")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// manifestTemplate is adapted from the upstream docs, see:
+// https://kubernetes.io/docs/tasks/extend-kubernetes/setup-konnectivity/
+const manifestTemplate = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: konnectivity-agent
+  namespace: kube-system
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: konnectivity-agent
+  namespace: kube-system
+  labels:
+    k8s-app: konnectivity-agent
+spec:
+  selector:
+    matchLabels:
+      k8s-app: konnectivity-agent
+  template:
+    metadata:
+      labels:
+        k8s-app: konnectivity-agent
+    spec:
+      priorityClassName: system-cluster-critical
+      serviceAccountName: konnectivity-agent
+      tolerations:
+      - key: CriticalAddonsOnly
+        operator: Exists
+      - effect: NoSchedule
+        operator: Exists
+      containers:
+      - name: konnectivity-agent
+        image: __IMAGE__
+        command: ["/proxy-agent"]
+        args:
+        - --logtostderr=true
+        - --ca-cert=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt
+        - --proxy-server-host=__SERVER_HOST__
+        - --proxy-server-port=__SERVER_PORT__
+        - --admin-server-port=8133
+        - --health-server-port=8134
+        - --service-account-token-path=/var/run/secrets/tokens/konnectivity-agent-token
+        volumeMounts:
+        - mountPath: /var/run/secrets/tokens
+          name: konnectivity-agent-token
+        livenessProbe:
+          httpGet:
+            port: 8134
+            path: /healthz
+          initialDelaySeconds: 15
+          timeoutSeconds: 15
+      volumes:
+      - name: konnectivity-agent-token
+        projected:
+          sources:
+          - serviceAccountToken:
+              path: konnectivity-agent-token
+              audience: system:konnectivity-server
+`
@@ -24,17 +24,22 @@ import (
 
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
-	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 )
 
-type action struct{}
+type action struct {
+	retries int
+}
 
 // NewAction returns a new action for installing storage
-func NewAction() actions.Action {
-	return &action{}
+// retries bounds retrying the install while the API server is not ready,
+// see actions.ActionContext.RetryApply
+func NewAction(retries int) actions.Action {
+	return &action{
+		retries: retries,
+	}
 }
 
 // Execute runs the action
@@ -55,7 +60,7 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	node := controlPlanes[0] // kind expects at least one always
 
 	// add the default storage class
-	if err := addDefaultStorage(ctx.Logger, node); err != nil {
+	if err := addDefaultStorage(ctx, node, a.retries); err != nil {
 		return errors.Wrap(err, "failed to add default storage class")
 	}
 
@@ -77,23 +82,25 @@ metadata:
     storageclass.kubernetes.io/is-default-class: "true"
 provisioner: kubernetes.io/host-path`
 
-func addDefaultStorage(logger log.Logger, controlPlane nodes.Node) error {
+func addDefaultStorage(ctx *actions.ActionContext, controlPlane nodes.Node, retries int) error {
 	// start with fallback default, and then try to get the newer kind node
 	// storage manifest if present
 	manifest := defaultStorageManifest
 	var raw bytes.Buffer
 	if err := controlPlane.Command("cat", "/kind/manifests/default-storage.yaml").SetStdout(&raw).Run(); err != nil {
-		logger.Warn("Could not read storage manifest, falling back on old k8s.io/host-path default ...")
+		ctx.Logger.Warn("Could not read storage manifest, falling back on old k8s.io/host-path default ...")
 	} else {
 		manifest = raw.String()
 	}
 
 	// apply the manifest
-	in := strings.NewReader(manifest)
-	cmd := controlPlane.Command(
-		"kubectl",
-		"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
-	)
-	cmd.SetStdin(in)
-	return cmd.Run()
+	return ctx.RetryApply(retries, func() error {
+		in := strings.NewReader(manifest)
+		cmd := controlPlane.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+		)
+		cmd.SetStdin(in)
+		return cmd.Run()
+	})
 }
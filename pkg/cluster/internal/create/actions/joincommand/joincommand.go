@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package joincommand implements an action to print a kubeadm join command
+// for joining nodes from outside of kind to the cluster
+package joincommand
+
+import (
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct {
+	tokenTTL         time.Duration
+	externalEndpoint string
+}
+
+// NewAction returns a new action for printing a kubeadm join command
+// tokenTTL is the lifetime of the bootstrap token in the command, if zero
+// the kubeadm default is used
+// externalEndpoint is the external load balancer endpoint if any, otherwise
+// the API server endpoint published on the host is used
+func NewAction(tokenTTL time.Duration, externalEndpoint string) actions.Action {
+	return &action{
+		tokenTTL:         tokenTTL,
+		externalEndpoint: externalEndpoint,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Creating join command for external nodes 🔑")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	endpoint := a.externalEndpoint
+	if endpoint == "" {
+		endpoint, err = ctx.Provider.GetAPIServerEndpoint(ctx.Config.Name)
+		if err != nil {
+			return errors.Wrap(err, "failed to get api server endpoint")
+		}
+	}
+
+	args := []string{"token", "create", "--print-join-command"}
+	if a.tokenTTL > 0 {
+		args = append(args, "--ttl="+a.tokenTTL.String())
+	}
+	lines, err := exec.OutputLines(node.Command("kubeadm", args...))
+	if err != nil {
+		return errors.Wrap(err, "failed to create join command")
+	}
+	command, err := withEndpoint(lines, endpoint)
+	if err != nil {
+		return err
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	ctx.Logger.V(0).Infof(
+		"To join external nodes to the cluster, run the following as root on each node:\n\n  %s\n",
+		command,
+	)
+	return nil
+}
+
+// withEndpoint returns the join command printed by kubeadm in lines,
+// with the API server endpoint replaced by endpoint
+// kubeadm prints the cluster's controlPlaneEndpoint, which is only
+// reachable on the kind network
+func withEndpoint(lines []string, endpoint string) (string, error) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "kubeadm" && fields[1] == "join" {
+			fields[2] = endpoint
+			return strings.Join(fields, " "), nil
+		}
+	}
+	return "", errors.Errorf("failed to find join command in kubeadm output: %v", lines)
+}
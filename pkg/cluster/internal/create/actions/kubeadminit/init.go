@@ -23,19 +23,41 @@ import (
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 
+	"sigs.k8s.io/kind/pkg/cluster/constants"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/checkversion"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
 )
 
 // kubeadmInitAction implements action for executing the kubadm init
 // and a set of default post init operations like e.g. install the
 // CNI network plugin.
-type action struct{}
+type action struct {
+	verbose          bool
+	usePatches       bool
+	nodeNameTemplate string
+	expectedVersion  string
+}
 
 // NewAction returns a new action for kubeadm init
-func NewAction() actions.Action {
-	return &action{}
+// if verbose is set kubeadm's output is logged at V(1) as it runs
+// if usePatches is set the kubeadm patches written by the config action are
+// applied
+// nodeNameTemplate is the template the nodes were named from if non-empty,
+// it is used to find the node's skipPhases in the config
+// expectedVersion is the Kubernetes version kubeadm must match if non-empty,
+// see checkversion.Matches
+func NewAction(verbose, usePatches bool, nodeNameTemplate, expectedVersion string) actions.Action {
+	return &action{
+		verbose:          verbose,
+		usePatches:       usePatches,
+		nodeNameTemplate: nodeNameTemplate,
+		expectedVersion:  expectedVersion,
+	}
 }
 
 // Execute runs the action
@@ -56,21 +78,60 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 
+	configNode, err := common.ConfigNode(ctx.Config, a.nodeNameTemplate, node.String())
+	if err != nil {
+		return err
+	}
+	kubeVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	// custom node images may be missing kubeadm or ship the wrong one, which
+	// otherwise fails confusingly part way through init
+	if err := a.checkKubeadm(node, configNode.Image, kubeVersion); err != nil {
+		return err
+	}
+	if err := kubeadm.ValidateSkipPhases("init", configNode.SkipPhases, kubeVersion); err != nil {
+		return err
+	}
+
 	// run kubeadm
-	cmd := node.Command(
+	args := []string{
 		// init because this is the control plane node
-		"kubeadm", "init",
+		"init",
 		// skip preflight checks, as these have undesirable side effects
 		// and don't tell us much. requires kubeadm 1.13+
-		"--skip-phases=preflight",
+		// along with any phases the node is configured to skip
+		kubeadm.SkipPhasesFlag(configNode.SkipPhases),
 		// specify our generated config file
 		"--config=/kind/kubeadm.conf",
 		"--skip-token-print",
 		// increase verbosity for debugging
 		"--v=6",
-	)
-	lines, err := exec.CombinedOutputLines(cmd)
-	ctx.Logger.V(3).Info(strings.Join(lines, "\n"))
+	}
+	if a.usePatches {
+		flag, err := kubeadm.PatchesFlag(kubeVersion)
+		if err != nil {
+			return err
+		}
+		args = append(args, flag)
+	}
+	cmd := node.Command("kubeadm", args...)
+	var lines []string
+	if a.verbose {
+		lines, err = exec.CombinedOutputLinesStreaming(cmd, func(line string) {
+			ctx.Logger.V(1).Info(line)
+		})
+	} else {
+		lines, err = exec.CombinedOutputLines(cmd)
+	}
+	output := strings.Join(lines, "\n")
+	if !a.verbose {
+		ctx.Logger.V(3).Info(output)
+	}
+	// keep the output on the node alongside the other logs for debugging,
+	// this is best effort and shouldn't fail creating the cluster
+	_ = nodeutils.WriteFile(node, "/var/log/kubeadm-init.log", output+"\n")
 	if err != nil {
 		return errors.Wrap(err, "failed to init node with kubeadm")
 	}
@@ -98,9 +159,13 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 		}
 	}
 
-	// if we are only provisioning one node, remove the master taint
+	// if we are only provisioning one kubernetes node, remove the master taint
 	// https://kubernetes.io/docs/setup/independent/create-cluster-kubeadm/#master-isolation
-	if len(allNodes) == 1 {
+	kubeletOnly, err := nodeutils.SelectNodesByRole(allNodes, constants.KubeletOnlyNodeRoleValue)
+	if err != nil {
+		return err
+	}
+	if len(allNodes)-len(kubeletOnly) == 1 {
 		if err := node.Command(
 			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
 			"taint", "nodes", "--all", "node-role.kubernetes.io/master-",
@@ -113,3 +178,23 @@ func (a *action) Execute(ctx *actions.ActionContext) error {
 	ctx.Status.End(true)
 	return nil
 }
+
+// checkKubeadm returns an error pointing at image if node does not have a
+// kubeadm binary compatible with the image's Kubernetes version kubeVersion
+func (a *action) checkKubeadm(node nodes.Node, image, kubeVersion string) error {
+	lines, err := exec.OutputLines(node.Command("kubeadm", "version", "-o", "short"))
+	if err != nil {
+		return errors.Wrapf(err, "node image %s does not have a working kubeadm binary", image)
+	}
+	if len(lines) != 1 {
+		return errors.Errorf("node image %s has an unexpected kubeadm binary, `kubeadm version -o short` printed %v", image, lines)
+	}
+	kubeadmVersion := strings.TrimSpace(lines[0])
+	if err := kubeadm.ValidateKubeadmVersion(kubeadmVersion, kubeVersion); err != nil {
+		return errors.Wrapf(err, "node image %s has an incompatible kubeadm binary", image)
+	}
+	if a.expectedVersion != "" && !checkversion.Matches(a.expectedVersion, kubeadmVersion) {
+		return errors.Errorf("node image %s has kubeadm %s, expected %s", image, kubeadmVersion, a.expectedVersion)
+	}
+	return nil
+}
@@ -29,15 +29,34 @@ import (
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
 )
 
 // Action implements action for creating the kubeadm join
 // and deployng it on the bootrap control-plane node.
-type Action struct{}
+type Action struct {
+	verbose          bool
+	usePatches       bool
+	nodeNameTemplate string
+	concurrency      int
+}
 
 // NewAction returns a new action for creating the kubeadm jion
-func NewAction() actions.Action {
-	return &Action{}
+// if verbose is set kubeadm's output is logged at V(1) as it runs
+// if usePatches is set the kubeadm patches written by the config action are
+// applied to the secondary control plane nodes
+// nodeNameTemplate is the template the nodes were named from if non-empty,
+// it is used to find each node's skipPhases in the config
+// concurrency is the most workers to join at the same time if non-zero,
+// otherwise they all join at once
+func NewAction(verbose, usePatches bool, nodeNameTemplate string, concurrency int) actions.Action {
+	return &Action{
+		verbose:          verbose,
+		usePatches:       usePatches,
+		nodeNameTemplate: nodeNameTemplate,
+		concurrency:      concurrency,
+	}
 }
 
 // Execute runs the action
@@ -53,7 +72,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 	if len(secondaryControlPlanes) > 0 {
-		if err := joinSecondaryControlPlanes(ctx, secondaryControlPlanes); err != nil {
+		if err := joinSecondaryControlPlanes(ctx, secondaryControlPlanes, a.verbose, a.usePatches, a.nodeNameTemplate); err != nil {
 			return err
 		}
 	}
@@ -64,7 +83,7 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 		return err
 	}
 	if len(workers) > 0 {
-		if err := joinWorkers(ctx, workers); err != nil {
+		if err := joinWorkers(ctx, workers, a.verbose, a.nodeNameTemplate, a.concurrency); err != nil {
 			return err
 		}
 	}
@@ -75,6 +94,8 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 func joinSecondaryControlPlanes(
 	ctx *actions.ActionContext,
 	secondaryControlPlanes []nodes.Node,
+	verbose, usePatches bool,
+	nodeNameTemplate string,
 ) error {
 	ctx.Status.Start("Joining more control-plane nodes 🎮")
 	defer ctx.Status.End(false)
@@ -83,7 +104,11 @@ func joinSecondaryControlPlanes(
 	// (this is not safe currently)
 	for _, node := range secondaryControlPlanes {
 		node := node // capture loop variable
-		if err := runKubeadmJoin(ctx.Logger, node); err != nil {
+		configNode, err := common.ConfigNode(ctx.Config, nodeNameTemplate, node.String())
+		if err != nil {
+			return err
+		}
+		if err := runKubeadmJoin(ctx.Logger, node, verbose, usePatches, configNode.SkipPhases); err != nil {
 			return err
 		}
 	}
@@ -95,19 +120,35 @@ func joinSecondaryControlPlanes(
 func joinWorkers(
 	ctx *actions.ActionContext,
 	workers []nodes.Node,
+	verbose bool,
+	nodeNameTemplate string,
+	concurrency int,
 ) error {
 	ctx.Status.Start("Joining worker nodes 🚜")
 	defer ctx.Status.End(false)
 
-	// create the workers concurrently
+	// join the workers concurrently, this is safe as they only read the
+	// shared bootstrap token and discovery settings from their own config
 	fns := []func() error{}
 	for _, node := range workers {
 		node := node // capture loop variable
 		fns = append(fns, func() error {
-			return runKubeadmJoin(ctx.Logger, node)
+			configNode, err := common.ConfigNode(ctx.Config, nodeNameTemplate, node.String())
+			if err != nil {
+				return err
+			}
+			if err := runKubeadmJoin(ctx.Logger, node, verbose, false, configNode.SkipPhases); err != nil {
+				return errors.Wrapf(err, "failed to join worker %s", node.String())
+			}
+			return nil
 		})
 	}
-	if err := errors.UntilErrorConcurrent(fns); err != nil {
+	if concurrency <= 0 {
+		concurrency = len(fns)
+	}
+	// every worker is attempted so that all of the failures are reported,
+	// the partially joined cluster is deleted on failure unless retained
+	if err := errors.AggregateConcurrentLimit(fns, concurrency); err != nil {
 		return err
 	}
 
@@ -116,21 +157,57 @@ func joinWorkers(
 }
 
 // runKubeadmJoin executes kubadm join command
-func runKubeadmJoin(logger log.Logger, node nodes.Node) error {
+func runKubeadmJoin(logger log.Logger, node nodes.Node, verbose, usePatches bool, skipPhases []string) error {
+	var kubeVersion string
+	if usePatches || len(skipPhases) > 0 {
+		var err error
+		kubeVersion, err = nodeutils.KubeVersion(node)
+		if err != nil {
+			return errors.Wrap(err, "failed to get kubernetes version from node")
+		}
+	}
+	if err := kubeadm.ValidateSkipPhases("join", skipPhases, kubeVersion); err != nil {
+		return err
+	}
+
 	// run kubeadm join
 	// TODO(bentheelder): this should be using the config file
-	cmd := node.Command(
-		"kubeadm", "join",
+	args := []string{
+		"join",
 		// the join command uses the config file generated in a well known location
 		"--config", "/kind/kubeadm.conf",
 		// skip preflight checks, as these have undesirable side effects
 		// and don't tell us much. requires kubeadm 1.13+
-		"--skip-phases=preflight",
+		// along with any phases the node is configured to skip
+		kubeadm.SkipPhasesFlag(skipPhases),
 		// increase verbosity for debugging
 		"--v=6",
-	)
-	lines, err := exec.CombinedOutputLines(cmd)
-	logger.V(3).Info(strings.Join(lines, "\n"))
+	}
+	if usePatches {
+		flag, err := kubeadm.PatchesFlag(kubeVersion)
+		if err != nil {
+			return err
+		}
+		args = append(args, flag)
+	}
+	cmd := node.Command("kubeadm", args...)
+	var lines []string
+	var err error
+	if verbose {
+		// workers join concurrently, so identify which node each line is from
+		lines, err = exec.CombinedOutputLinesStreaming(cmd, func(line string) {
+			logger.V(1).Infof("%s: %s", node.String(), line)
+		})
+	} else {
+		lines, err = exec.CombinedOutputLines(cmd)
+	}
+	output := strings.Join(lines, "\n")
+	if !verbose {
+		logger.V(3).Info(output)
+	}
+	// keep the output on the node alongside the other logs for debugging,
+	// this is best effort and shouldn't fail creating the cluster
+	_ = nodeutils.WriteFile(node, "/var/log/kubeadm-join.log", output+"\n")
 	if err != nil {
 		return errors.Wrap(err, "failed to join node with kubeadm")
 	}
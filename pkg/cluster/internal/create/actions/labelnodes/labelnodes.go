@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package labelnodes implements an action to set the configured node labels
+// the kubelet may not set on its own node
+package labelnodes
+
+import (
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct {
+	nodeNameTemplate string
+}
+
+// NewAction returns a new action for labeling the nodes through the API,
+// nodeNameTemplate is the template the nodes were named from if non-empty,
+// it is used to match the nodes to the config
+func NewAction(nodeNameTemplate string) actions.Action {
+	return &action{
+		nodeNameTemplate: nodeNameTemplate,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Labeling nodes 🏷️")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlane, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range allNodes {
+		configNode, err := common.ConfigNode(ctx.Config, a.nodeNameTemplate, node.String())
+		if err != nil {
+			// E.G. the load balancer is not in the config
+			continue
+		}
+		// the kubelet already registered the node with the labels it may set
+		_, labels := config.NodeLabels(configNode)
+		if len(labels) == 0 {
+			continue
+		}
+		// the node is registered by its hostname if it is set
+		name := node.String()
+		if configNode.Hostname != "" {
+			name = configNode.Hostname
+		}
+		pairs := make([]string, 0, len(labels))
+		for key, value := range labels {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		ctx.Logger.V(1).Infof("Labeling node %s with %s", name, strings.Join(pairs, ","))
+		args := append([]string{
+			"--kubeconfig=/etc/kubernetes/admin.conf", "label", "node", name, "--overwrite",
+		}, pairs...)
+		if err := controlPlane.Command("kubectl", args...).Run(); err != nil {
+			return errors.Wrapf(err, "failed to label node %s", name)
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package localregistry implements an action to create a local image registry
+// for the cluster
+package localregistry
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct {
+	hostPort int32
+}
+
+// NewAction returns a new action for creating a local image registry,
+// published on the host at localhost:hostPort
+func NewAction(hostPort int32) actions.Action {
+	return &action{
+		hostPort: hostPort,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Creating local registry 📦")
+	defer ctx.Status.End(false)
+
+	if err := ctx.Provider.ProvisionLocalRegistry(ctx.Config.Name, a.hostPort); err != nil {
+		return err
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// document the local registry for tools, see:
+	// https://github.com/kubernetes/enhancements/tree/master/keps/sig-cluster-lifecycle/generic/1755-communicating-a-local-registry
+	cmd := node.Command(
+		"kubectl",
+		"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+	)
+	cmd.SetStdin(strings.NewReader(fmt.Sprintf(registryHostingManifest, HostEndpoint(a.hostPort))))
+	if err := cmd.Run(); err != nil {
+		return errors.Wrap(err, "failed to create local-registry-hosting configmap")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// HostEndpoint returns the endpoint for a local registry published on the
+// host at hostPort, images pushed here may be pulled by the nodes
+// at the same endpoint, see ContainerdConfigPatch
+func HostEndpoint(hostPort int32) string {
+	return fmt.Sprintf("localhost:%d", hostPort)
+}
+
+// ContainerdConfigPatch returns the containerd config patch for the nodes
+// to pull images from the local registry for cluster published at hostPort
+func ContainerdConfigPatch(cluster string, hostPort int32) string {
+	return fmt.Sprintf(`[plugins."io.containerd.grpc.v1.cri".registry.mirrors.%q]
+  endpoint = ["http://%s:%d"]
+`, HostEndpoint(hostPort), common.LocalRegistryName(cluster), common.LocalRegistryInternalPort)
+}
+
+const registryHostingManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local-registry-hosting
+  namespace: kube-public
+data:
+  localRegistryHosting.v1: |
+    host: "%s"
+    help: "https://kind.sigs.k8s.io/docs/user/local-registry/"
+`
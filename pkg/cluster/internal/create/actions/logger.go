@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// verbosityLogger wraps a log.Logger, also writing Info logs up to
+// verbosity regardless of the wrapped logger's verbosity
+type verbosityLogger struct {
+	log.Logger
+	verbosity log.Level
+}
+
+var _ log.Logger = &verbosityLogger{}
+
+// V is part of the log.Logger interface
+func (l *verbosityLogger) V(level log.Level) log.InfoLogger {
+	if level <= l.verbosity {
+		// V(0) is always enabled
+		return l.Logger.V(0)
+	}
+	return l.Logger.V(level)
+}
+
+// WithVerbosity returns a copy of ac sharing its cached data, with a Logger
+// that also writes Info logs up to verbosity, E.G. to debug a single action
+func (ac *ActionContext) WithVerbosity(verbosity log.Level) *ActionContext {
+	scoped := *ac
+	scoped.Logger = &verbosityLogger{
+		Logger:    ac.Logger,
+		verbosity: verbosity,
+	}
+	return &scoped
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodelocaldns implements an action to install the node-local DNS
+// cache
+package nodelocaldns
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// Image is the node-local DNS cache image
+const Image = "registry.k8s.io/dns/k8s-dns-node-cache:1.22.20"
+
+// the link-local addresses the cache listens on in each node, along with
+// the cluster DNS service IP, these are the upstream defaults
+const (
+	localDNSIPv4 = "169.254.20.10"
+	localDNSIPv6 = "fd00::10"
+)
+
+// dnsServiceIndex is the index of the DNS service IP in the service subnet,
+// kubeadm assigns the kube-dns service the tenth address
+const dnsServiceIndex = 10
+
+// defaultDNSDomain is the kubeadm default DNS domain for services
+const defaultDNSDomain = "cluster.local"
+
+// rolloutTimeout is how long to wait for the cache to run on every node
+const rolloutTimeout = 3 * time.Minute
+
+type action struct {
+	dnsDomain string
+	retries   int
+}
+
+// NewAction returns a new action for installing the node-local DNS cache
+// for the cluster DNS domain dnsDomain, if unset the kubeadm default.
+// retries bounds retrying the install while the API server is not ready,
+// see actions.ActionContext.RetryApply
+func NewAction(dnsDomain string, retries int) actions.Action {
+	return &action{
+		dnsDomain: dnsDomain,
+		retries:   retries,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Installing node-local DNS cache 🗂")
+	defer ctx.Status.End(false)
+
+	dnsServiceIP, err := DNSServiceIP(ctx.Config.Networking.ServiceSubnet)
+	if err != nil {
+		return err
+	}
+	localDNS := localDNSIPv4
+	if ctx.Config.Networking.IPFamily == config.IPv6Family {
+		localDNS = localDNSIPv6
+	}
+	dnsDomain := a.dnsDomain
+	if dnsDomain == "" {
+		dnsDomain = defaultDNSDomain
+	}
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// the cluster DNS and upstream servers are filled in by node-cache
+	manifest := strings.NewReplacer(
+		"__PILLAR__LOCAL__DNS__:8080", net.JoinHostPort(localDNS, "8080"),
+		"__PILLAR__LOCAL__DNS__", localDNS,
+		"__PILLAR__DNS__SERVER__", dnsServiceIP,
+		"__PILLAR__DNS__DOMAIN__", dnsDomain,
+		"__PILLAR__IMAGE__", Image,
+	).Replace(manifestTemplate)
+	if err := ctx.RetryApply(a.retries, func() error {
+		cmd := node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf", "apply", "-f", "-",
+		)
+		cmd.SetStdin(strings.NewReader(manifest))
+		return cmd.Run()
+	}); err != nil {
+		return errors.Wrap(err, "failed to install node-local DNS cache")
+	}
+
+	if err := node.Command(
+		"kubectl",
+		"--kubeconfig=/etc/kubernetes/admin.conf",
+		"--namespace=kube-system",
+		"rollout", "status", "daemonset/node-local-dns",
+		fmt.Sprintf("--timeout=%s", rolloutTimeout),
+	).Run(); err != nil {
+		return errors.Wrap(err, "timed out waiting for the node-local DNS cache")
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// DNSServiceIP returns the cluster DNS service IP kubeadm assigns in
+// serviceSubnet, or an error if it cannot be derived
+func DNSServiceIP(serviceSubnet string) (string, error) {
+	_, subnet, err := net.ParseCIDR(serviceSubnet)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid service subnet %q", serviceSubnet)
+	}
+	ip := make(net.IP, len(subnet.IP))
+	copy(ip, subnet.IP)
+	carry := dnsServiceIndex
+	for i := len(ip) - 1; i >= 0 && carry > 0; i-- {
+		sum := int(ip[i]) + carry
+		ip[i] = byte(sum)
+		carry = sum >> 8
+	}
+	if carry > 0 || !subnet.Contains(ip) {
+		return "", errors.Errorf("service subnet %s is too small for the DNS service IP", serviceSubnet)
+	}
+	return ip.String(), nil
+}
+
+// manifestTemplate is adapted from the upstream addon, see:
+// https://github.com/kubernetes/kubernetes/blob/master/cluster/addons/dns/nodelocaldns/nodelocaldns.yaml
+const manifestTemplate = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: kube-dns-upstream
+  namespace: kube-system
+  labels:
+    k8s-app: kube-dns
+    kubernetes.io/name: KubeDNSUpstream
+spec:
+  ports:
+  - name: dns
+    port: 53
+    protocol: UDP
+    targetPort: 53
+  - name: dns-tcp
+    port: 53
+    protocol: TCP
+    targetPort: 53
+  selector:
+    k8s-app: kube-dns
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+data:
+  Corefile: |
+    __PILLAR__DNS__DOMAIN__:53 {
+        errors
+        cache {
+            success 9984 30
+            denial 9984 5
+        }
+        reload
+        loop
+        bind __PILLAR__LOCAL__DNS__ __PILLAR__DNS__SERVER__
+        forward . __PILLAR__CLUSTER__DNS__ {
+            force_tcp
+        }
+        prometheus :9253
+        health __PILLAR__LOCAL__DNS__:8080
+    }
+    in-addr.arpa:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind __PILLAR__LOCAL__DNS__ __PILLAR__DNS__SERVER__
+        forward . __PILLAR__CLUSTER__DNS__ {
+            force_tcp
+        }
+        prometheus :9253
+    }
+    ip6.arpa:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind __PILLAR__LOCAL__DNS__ __PILLAR__DNS__SERVER__
+        forward . __PILLAR__CLUSTER__DNS__ {
+            force_tcp
+        }
+        prometheus :9253
+    }
+    .:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind __PILLAR__LOCAL__DNS__ __PILLAR__DNS__SERVER__
+        forward . __PILLAR__UPSTREAM__SERVERS__
+        prometheus :9253
+    }
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+  labels:
+    k8s-app: node-local-dns
+spec:
+  updateStrategy:
+    rollingUpdate:
+      maxUnavailable: 10%
+  selector:
+    matchLabels:
+      k8s-app: node-local-dns
+  template:
+    metadata:
+      labels:
+        k8s-app: node-local-dns
+    spec:
+      priorityClassName: system-node-critical
+      serviceAccountName: node-local-dns
+      hostNetwork: true
+      dnsPolicy: Default
+      tolerations:
+      - key: CriticalAddonsOnly
+        operator: Exists
+      - effect: NoExecute
+        operator: Exists
+      - effect: NoSchedule
+        operator: Exists
+      containers:
+      - name: node-cache
+        image: __PILLAR__IMAGE__
+        resources:
+          requests:
+            cpu: 25m
+            memory: 5Mi
+        args: ["-localip", "__PILLAR__LOCAL__DNS__,__PILLAR__DNS__SERVER__", "-conf", "/etc/Corefile", "-upstreamsvc", "kube-dns-upstream"]
+        securityContext:
+          capabilities:
+            add:
+            - NET_ADMIN
+        ports:
+        - containerPort: 53
+          name: dns
+          protocol: UDP
+        - containerPort: 53
+          name: dns-tcp
+          protocol: TCP
+        - containerPort: 9253
+          name: metrics
+          protocol: TCP
+        livenessProbe:
+          httpGet:
+            host: __PILLAR__LOCAL__DNS__
+            path: /health
+            port: 8080
+          initialDelaySeconds: 60
+          timeoutSeconds: 5
+        volumeMounts:
+        - mountPath: /run/xtables.lock
+          name: xtables-lock
+          readOnly: false
+        - name: config-volume
+          mountPath: /etc/coredns
+        - name: kube-dns-config
+          mountPath: /etc/kube-dns
+      volumes:
+      - name: xtables-lock
+        hostPath:
+          path: /run/xtables.lock
+          type: FileOrCreate
+      - name: kube-dns-config
+        configMap:
+          name: kube-dns
+          optional: true
+      - name: config-volume
+        configMap:
+          name: node-local-dns
+          items:
+          - key: Corefile
+            path: Corefile.base
+`
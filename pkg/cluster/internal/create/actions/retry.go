@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actions
+
+import (
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// DefaultApplyRetries is how many times RetryApply retries if retries is zero
+const DefaultApplyRetries = 5
+
+// NoApplyRetries disables retrying in RetryApply
+const NoApplyRetries = -1
+
+// maxApplyBackoff bounds the backoff between RetryApply attempts
+const maxApplyBackoff = 10 * time.Second
+
+// transientApplyErrors are kubectl output indicating the API server was not
+// ready to serve the request, E.G. just after it started
+var transientApplyErrors = []string{
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"the server is currently unable to handle the request",
+	"etcdserver: request timed out",
+}
+
+// RetryApply runs apply, retrying up to retries times with backoff if it
+// fails because the API server was not ready, other errors such as an
+// invalid manifest are returned immediately.
+// If retries is zero DefaultApplyRetries is used, if it is NoApplyRetries
+// apply is only run once.
+func (ac *ActionContext) RetryApply(retries int, apply func() error) error {
+	switch retries {
+	case 0:
+		retries = DefaultApplyRetries
+	case NoApplyRetries:
+		retries = 0
+	}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		err := apply()
+		if err == nil || attempt >= retries || !isTransientApplyError(err) {
+			return err
+		}
+		ac.Logger.V(1).Infof("Retrying in %s after a transient error: %v", backoff, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxApplyBackoff {
+			backoff = maxApplyBackoff
+		}
+	}
+}
+
+// isTransientApplyError returns true if err is from a command whose output
+// matches transientApplyErrors
+func isTransientApplyError(err error) bool {
+	runErr := exec.RunErrorForError(err)
+	if runErr == nil {
+		return false
+	}
+	output := string(runErr.Output)
+	for _, transient := range transientApplyErrors {
+		if strings.Contains(output, transient) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package seedobjects implements an action to create objects in the cluster
+// once it is ready
+package seedobjects
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+type action struct {
+	objects []interface{}
+}
+
+// NewAction returns a new action for creating objects in the cluster, in
+// order, see Validate for the supported objects
+func NewAction(objects []interface{}) actions.Action {
+	return &action{
+		objects: objects,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Creating seed objects 🌱")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// create every object even if some fail, so that all failures are reported
+	errs := []error{}
+	for i, obj := range a.objects {
+		o, err := parse(obj)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid seed object %d", i))
+			continue
+		}
+		if err := create(node, o); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to create seed object %d (%s)", i, o))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// Validate returns an error if any of objects cannot be created, objects
+// must marshal to JSON Kubernetes objects with an apiVersion, kind, and name,
+// E.G. typed API objects with their TypeMeta set, or map[string]interface{}
+func Validate(objects []interface{}) error {
+	errs := []error{}
+	for i, obj := range objects {
+		if _, err := parse(obj); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid seed object %d", i))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// object is a seed object marshalled to JSON
+type object struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	raw []byte
+}
+
+func (o *object) String() string {
+	if o.Metadata.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", o.Kind, o.Metadata.Namespace, o.Metadata.Name)
+	}
+	return fmt.Sprintf("%s %s", o.Kind, o.Metadata.Name)
+}
+
+func parse(obj interface{}) (*object, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal object")
+	}
+	o := &object{raw: raw}
+	if err := json.Unmarshal(raw, o); err != nil {
+		return nil, errors.Wrap(err, "object is not a Kubernetes object")
+	}
+	if o.APIVersion == "" || o.Kind == "" || o.Metadata.Name == "" {
+		return nil, errors.New("object must have an apiVersion, kind, and metadata.name")
+	}
+	return o, nil
+}
+
+// create creates o in the cluster, first creating its namespace if missing
+func create(node nodes.Node, o *object) error {
+	if ns := o.Metadata.Namespace; ns != "" {
+		if err := kubectl(node, nil, "get", "namespace", ns); err != nil {
+			if err := kubectl(node, nil, "create", "namespace", ns); err != nil {
+				return errors.Wrapf(err, "failed to create namespace %s", ns)
+			}
+		}
+	}
+	return kubectl(node, o.raw, "create", "-f", "-")
+}
+
+func kubectl(node nodes.Node, stdin []byte, args ...string) error {
+	cmd := node.Command(
+		"kubectl",
+		append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	)
+	if stdin != nil {
+		cmd.SetStdin(bytes.NewReader(stdin))
+	}
+	return cmd.Run()
+}
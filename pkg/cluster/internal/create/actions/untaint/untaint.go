@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package untaint implements an action to allow scheduling workloads on the
+// control plane nodes
+package untaint
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// controlPlaneTaints are the NoSchedule taint keys kubeadm may set on
+// control plane nodes, depending on the Kubernetes version
+var controlPlaneTaints = []string{
+	"node-role.kubernetes.io/master",
+	"node-role.kubernetes.io/control-plane",
+}
+
+type action struct{}
+
+// NewAction returns a new action for removing the control plane taints
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Removing control-plane taints 🧹")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+
+	for _, controlPlane := range controlPlanes {
+		name := controlPlane.String()
+		taints, err := taintKeys(node, name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get taints for node %s", name)
+		}
+		// only remove the taints that are present, so that this is
+		// idempotent and does not fail on nodes that were already untainted
+		for _, key := range controlPlaneTaints {
+			if !taints[key] {
+				ctx.Logger.V(1).Infof("Node %s does not have the %s taint", name, key)
+				continue
+			}
+			if err := node.Command(
+				"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+				"taint", "nodes", name, key+":NoSchedule-",
+			).Run(); err != nil {
+				return errors.Wrapf(err, "failed to remove %s taint from node %s", key, name)
+			}
+		}
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// taintKeys returns the keys of the NoSchedule taints on the node nodeName,
+// running kubectl on node
+func taintKeys(node nodes.Node, nodeName string) (map[string]bool, error) {
+	lines, err := exec.OutputLines(node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "node", nodeName,
+		"-o=jsonpath={range .spec.taints[?(@.effect==\"NoSchedule\")]}{.key}{\"\\n\"}{end}",
+	))
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for _, line := range lines {
+		if key := strings.TrimSpace(line); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package verifyha implements an action to verify that every control plane
+// node is serving the API server behind the control plane endpoint
+package verifyha
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+type action struct{}
+
+// NewAction returns a new action for verifying the API server is HA
+func NewAction() actions.Action {
+	return &action{}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Verifying the API server is highly available 🩺")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// the apiservers registered in the kubernetes service endpoints are the
+	// ones the cluster itself considers to be serving
+	endpoints, err := exec.OutputLines(node.Command(
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "endpoints", "kubernetes", "--namespace=default",
+		"-o=jsonpath={range .subsets[*].addresses[*]}{.ip}{\"\\n\"}{end}",
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to get the kubernetes service endpoints")
+	}
+	registered := map[string]bool{}
+	for _, ip := range endpoints {
+		registered[strings.TrimSpace(ip)] = true
+	}
+
+	// check each node on the address the load balancer dials concurrently
+	ipv6 := ctx.Config.Networking.IPFamily == config.IPv6Family
+	var mu sync.Mutex
+	failures := []error{}
+	fns := []func() error{}
+	for _, n := range controlPlanes {
+		n := n // capture loop variable
+		fns = append(fns, func() error {
+			err := checkNode(n, ipv6, registered)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				ctx.Logger.V(0).Infof("Control plane node %s: API server unhealthy: %v", n.String(), err)
+				failures = append(failures, errors.Wrapf(err, "node %s", n.String()))
+			} else {
+				ctx.Logger.V(0).Infof("Control plane node %s: API server healthy", n.String())
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return errors.Wrapf(
+			errors.NewAggregate(failures),
+			"only %d of %d control plane nodes are serving the API server",
+			len(controlPlanes)-len(failures), len(controlPlanes),
+		)
+	}
+
+	// check the kind load balancer routes to every control plane node
+	// NOTE: there is none with an external load balancer endpoint, which is
+	// managed outside of kind and not checked
+	lbNode, err := nodeutils.ExternalLoadBalancerNode(allNodes)
+	if err != nil {
+		return err
+	}
+	if lbNode == nil {
+		ctx.Logger.V(0).Info("No kind load balancer, skipping the load balancer check")
+	} else {
+		if err := checkLoadBalancer(lbNode, node, ipv6, controlPlanes); err != nil {
+			return errors.Wrap(err, "the load balancer is not routing to every control plane node")
+		}
+		ctx.Logger.V(0).Infof("Load balancer %s: routing to all %d control plane nodes", lbNode.String(), len(controlPlanes))
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// checkNode returns an error if the API server on node is not healthy on its
+// container address or is not registered as a kubernetes service endpoint
+func checkNode(node nodes.Node, ipv6 bool, registered map[string]bool) error {
+	ipv4, ipv6Address, err := node.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get node IP")
+	}
+	ip := ipv4
+	if ipv6 {
+		ip = ipv6Address
+	}
+	url := fmt.Sprintf("https://%s/healthz", net.JoinHostPort(ip, fmt.Sprintf("%d", common.APIServerInternalPort)))
+	lines, err := exec.OutputLines(node.Command("curl", "--insecure", "--silent", "--fail", url))
+	if err != nil {
+		return errors.Wrapf(err, "health check of %s failed", url)
+	}
+	if len(lines) != 1 || lines[0] != "ok" {
+		return errors.Errorf("health check of %s returned %v", url, lines)
+	}
+	if !registered[ip] {
+		return errors.Errorf("%s is not registered as a kubernetes service endpoint", ip)
+	}
+	return nil
+}
+
+// checkLoadBalancer returns an error if the load balancer config on lbNode
+// is missing any of controlPlanes as a backend, or if the API server is not
+// healthy through the load balancer from node
+func checkLoadBalancer(lbNode, node nodes.Node, ipv6 bool, controlPlanes []nodes.Node) error {
+	var buff bytes.Buffer
+	if err := lbNode.Command("cat", loadbalancer.ConfigPath).SetStdout(&buff).Run(); err != nil {
+		return errors.Wrap(err, "failed to read the load balancer config")
+	}
+	names := make([]string, 0, len(controlPlanes))
+	for _, n := range controlPlanes {
+		names = append(names, n.String())
+	}
+	if missing := missingBackends(buff.String(), names); len(missing) > 0 {
+		return errors.Errorf("the load balancer config has no backend for %s", strings.Join(missing, ", "))
+	}
+	ipv4, ipv6Address, err := lbNode.IP()
+	if err != nil {
+		return errors.Wrap(err, "failed to get load balancer IP")
+	}
+	ip := ipv4
+	if ipv6 {
+		ip = ipv6Address
+	}
+	url := fmt.Sprintf("https://%s/healthz", net.JoinHostPort(ip, fmt.Sprintf("%d", common.APIServerInternalPort)))
+	lines, err := exec.OutputLines(node.Command("curl", "--insecure", "--silent", "--fail", url))
+	if err != nil {
+		return errors.Wrapf(err, "health check of %s failed", url)
+	}
+	if len(lines) != 1 || lines[0] != "ok" {
+		return errors.Errorf("health check of %s returned %v", url, lines)
+	}
+	return nil
+}
+
+// backendRE matches the servers of the load balancer config, see
+// loadbalancer.Config
+var backendRE = regexp.MustCompile(`(?m)^\s*server\s+(\S+)\s+(\S+)`)
+
+// missingBackends returns the control plane node names which lbConfig does
+// not route to on common.APIServerInternalPort
+func missingBackends(lbConfig string, controlPlanes []string) []string {
+	backends := map[string]bool{}
+	for _, match := range backendRE.FindAllStringSubmatch(lbConfig, -1) {
+		backends[match[1]+" "+match[2]] = true
+	}
+	missing := []string{}
+	for _, name := range controlPlanes {
+		address := net.JoinHostPort(name, fmt.Sprintf("%d", common.APIServerInternalPort))
+		if !backends[name+" "+address] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
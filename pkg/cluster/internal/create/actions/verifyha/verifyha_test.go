@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package verifyha
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestMissingBackends(t *testing.T) {
+	t.Parallel()
+	lbConfig, err := loadbalancer.Config(&loadbalancer.ConfigData{
+		ControlPlanePort: common.APIServerInternalPort,
+		BackendServers: map[string]string{
+			"kind-control-plane":  "kind-control-plane:6443",
+			"kind-control-plane2": "kind-control-plane2:6443",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to generate the load balancer config: %v", err)
+	}
+	cases := []struct {
+		Name          string
+		ControlPlanes []string
+		Expected      []string
+	}{
+		{
+			Name:          "all routed",
+			ControlPlanes: []string{"kind-control-plane", "kind-control-plane2"},
+			Expected:      []string{},
+		},
+		{
+			Name:          "one missing",
+			ControlPlanes: []string{"kind-control-plane", "kind-control-plane2", "kind-control-plane3"},
+			Expected:      []string{"kind-control-plane3"},
+		},
+		{
+			Name:          "name prefix is not a backend",
+			ControlPlanes: []string{"kind-control"},
+			Expected:      []string{"kind-control"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, tc.Expected, missingBackends(lbConfig, tc.ControlPlanes))
+		})
+	}
+}
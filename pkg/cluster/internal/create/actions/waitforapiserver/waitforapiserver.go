@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package waitforapiserver implements an action for waiting for the API
+// server to be serving before installing anything into the cluster
+package waitforapiserver
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// DefaultTimeout is the timeout used when none is specified
+const DefaultTimeout = 30 * time.Second
+
+// pollInterval is the time between checks
+const pollInterval = time.Second
+
+type action struct {
+	timeout time.Duration
+}
+
+// NewAction returns a new action for waiting for the API server to be serving
+// if timeout is zero DefaultTimeout is used
+func NewAction(timeout time.Duration) actions.Action {
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &action{
+		timeout: timeout,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+
+	// check from the bootstrap control plane node, which is where the
+	// add-on actions apply manifests from
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	// /readyz is only available in Kubernetes 1.16+
+	endpoint := "/readyz"
+	rawVersion, err := nodeutils.KubeVersion(node)
+	if err != nil {
+		return errors.Wrap(err, "failed to get kubernetes version from node")
+	}
+	ver, err := version.ParseGeneric(rawVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubernetes version %q", rawVersion)
+	}
+	if ver.LessThan(version.MustParseGeneric("v1.16.0")) {
+		endpoint = "/healthz"
+	}
+
+	until := time.Now().Add(a.timeout)
+	for {
+		err = node.Command(
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"get", "--raw", endpoint,
+		).Run()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(pollInterval).After(until) {
+			return errors.Wrapf(err, "timed out after %s waiting for the API server to answer %s", a.timeout, endpoint)
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -22,34 +22,67 @@ import (
 	"strings"
 	"time"
 
+	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
 // Action implements an action for waiting for the cluster to be ready
 type Action struct {
-	waitTime time.Duration
+	opts Options
+}
+
+// Options configures the wait for ready action
+type Options struct {
+	// WaitTime is how long to wait in total, if zero the action is skipped
+	WaitTime time.Duration
+	// PollInterval is the minimum time between checks, if zero the cluster
+	// is checked again as soon as the previous check completes
+	PollInterval time.Duration
+	// Condition is the node condition type to check instead of Ready if set
+	Condition string
+	// Label is a label (key or key=value) the nodes must also have if set
+	Label string
+	// SystemPods also waits for the kube-system deployments and daemonsets
+	// to be ready within the same WaitTime
+	SystemPods bool
+	// ContinueOnNodeFailure waits for all of the nodes rather than only the
+	// control plane, and skips the nodes that are not ready in time, failing
+	// only if fewer than MinReadyNodes are ready
+	ContinueOnNodeFailure bool
+	// MinReadyNodes is the minimum number of ready nodes with
+	// ContinueOnNodeFailure
+	MinReadyNodes int
+	// CNIDaemonSet is the namespace/name of a CNI daemonset to also wait for
+	// if set, otherwise the CNI is only checked by way of the nodes being ready
+	CNIDaemonSet string
+	// IgnoreNodes are the names of nodes not to wait for, they are reported
+	// as not waited on instead
+	IgnoreNodes []string
+	// ExcludeNames and ExcludeLabels are kube-system workloads not to wait
+	// for with SystemPods, see waitForSystemWorkloads
+	ExcludeNames  []string
+	ExcludeLabels []string
 }
 
 // NewAction returns a new action for waiting for the cluster to be ready
-func NewAction(waitTime time.Duration) actions.Action {
-	return &Action{
-		waitTime: waitTime,
-	}
+func NewAction(opts Options) actions.Action {
+	return &Action{opts: opts}
 }
 
 // Execute runs the action
 func (a *Action) Execute(ctx *actions.ActionContext) error {
 	// skip entirely if the wait time is 0
-	if a.waitTime == time.Duration(0) {
+	if a.opts.WaitTime == time.Duration(0) {
 		return nil
 	}
 	ctx.Status.Start(
 		fmt.Sprintf(
 			"Waiting ≤ %s for control-plane = Ready ⏳",
-			formatDuration(a.waitTime),
+			formatDuration(a.opts.WaitTime),
 		),
 	)
 
@@ -64,25 +97,77 @@ func (a *Action) Execute(ctx *actions.ActionContext) error {
 	}
 	node := controlPlanes[0] // kind expects at least one always
 
+	ignored := make(map[string]bool, len(a.opts.IgnoreNodes))
+	for _, name := range a.opts.IgnoreNodes {
+		ignored[name] = true
+	}
+
 	// Wait for the nodes to reach Ready status.
 	startTime := time.Now()
-	isReady := waitForReady(node, startTime.Add(a.waitTime))
-	if !isReady {
-		ctx.Status.End(false)
-		ctx.Logger.V(0).Info(" • WARNING: Timed out waiting for Ready ⚠️")
-		return nil
+	var skipped []string
+	if a.opts.ContinueOnNodeFailure {
+		workers, err := nodeutils.SelectNodesByRole(allNodes, constants.WorkerNodeRoleValue)
+		if err != nil {
+			return err
+		}
+		expected := len(controlPlanes) + len(workers) - len(ignored)
+		skipped, err = waitForNodes(node, startTime.Add(a.opts.WaitTime), a.opts.PollInterval, a.opts.Condition, expected, a.opts.MinReadyNodes, ignored)
+		if err != nil {
+			return err
+		}
+		for _, name := range skipped {
+			ctx.Logger.Warnf(" • WARNING: Skipping %s, it is not Ready ⚠️", name)
+		}
+	} else {
+		var isReady bool
+		if a.opts.Condition == "" && a.opts.Label == "" && len(ignored) == 0 {
+			isReady = waitForReady(node, startTime.Add(a.opts.WaitTime), a.opts.PollInterval)
+		} else {
+			isReady = waitForCustomReady(node, startTime.Add(a.opts.WaitTime), a.opts.PollInterval, a.opts.Condition, a.opts.Label, ignored)
+		}
+		if !isReady {
+			ctx.Status.End(false)
+			ctx.Logger.V(0).Info(" • WARNING: Timed out waiting for Ready ⚠️")
+			return nil
+		}
+	}
+
+	// optionally wait for a third party CNI as well
+	if a.opts.CNIDaemonSet != "" {
+		if lagging := waitForDaemonSet(node, startTime.Add(a.opts.WaitTime), a.opts.PollInterval, a.opts.CNIDaemonSet); len(lagging) > 0 {
+			ctx.Status.End(false)
+			ctx.Logger.V(0).Infof(" • WARNING: Timed out waiting for CNI: %s ⚠️", strings.Join(lagging, ", "))
+			return nil
+		}
+	}
+
+	// optionally wait for the system workloads as well
+	if a.opts.SystemPods {
+		if lagging := waitForSystemWorkloads(node, startTime.Add(a.opts.WaitTime), a.opts.PollInterval, a.opts.ExcludeNames, a.opts.ExcludeLabels); len(lagging) > 0 {
+			ctx.Status.End(false)
+			ctx.Logger.V(0).Infof(" • WARNING: Timed out waiting for system workloads: %s ⚠️", strings.Join(lagging, ", "))
+			return nil
+		}
 	}
 
 	// mark success
 	ctx.Status.End(true)
-	ctx.Logger.V(0).Infof(" • Ready after %s 💚", formatDuration(time.Since(startTime)))
+	var notWaitedOn string
+	if len(a.opts.IgnoreNodes) > 0 {
+		notWaitedOn = ", not waited on: " + strings.Join(a.opts.IgnoreNodes, ", ")
+	}
+	if len(skipped) > 0 {
+		ctx.Logger.V(0).Infof(" • Ready after %s, skipped: %s%s 💛", formatDuration(time.Since(startTime)), strings.Join(skipped, ", "), notWaitedOn)
+		return nil
+	}
+	ctx.Logger.V(0).Infof(" • Ready after %s%s 💚", formatDuration(time.Since(startTime)), notWaitedOn)
 	return nil
 }
 
 // WaitForReady uses kubectl inside the "node" container to check if the
 // control plane nodes are "Ready".
-func waitForReady(node nodes.Node, until time.Time) bool {
-	return tryUntil(until, func() bool {
+func waitForReady(node nodes.Node, until time.Time, interval time.Duration) bool {
+	return tryUntil(until, interval, func() bool {
 		cmd := node.Command(
 			"kubectl",
 			"--kubeconfig=/etc/kubernetes/admin.conf",
@@ -113,13 +198,257 @@ func waitForReady(node nodes.Node, until time.Time) bool {
 	})
 }
 
+// waitForCustomReady uses kubectl inside the "node" container to check if the
+// control plane nodes have condition set to True (Ready if unset) and all
+// match label if set, the ignored nodes are not checked
+func waitForCustomReady(node nodes.Node, until time.Time, interval time.Duration, condition, label string, ignored map[string]bool) bool {
+	if condition == "" {
+		condition = "Ready"
+	}
+	const selector = "node-role.kubernetes.io/master"
+	return tryUntil(until, interval, func() bool {
+		// one line per control plane node with the status of the condition,
+		// which is empty if the node does not have the condition yet
+		statuses, err := exec.OutputLines(node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"get",
+			"nodes",
+			"--selector="+selector,
+			fmt.Sprintf(`-o=jsonpath={range .items[*]}{.metadata.name}={.status.conditions[?(@.type=="%s")].status}{"\n"}{end}`, condition),
+		))
+		if err != nil || len(statuses) == 0 {
+			return false
+		}
+		waited := 0
+		for _, status := range statuses {
+			if ignored[strings.SplitN(status, "=", 2)[0]] {
+				continue
+			}
+			if !strings.HasSuffix(status, "=True") {
+				return false
+			}
+			waited++
+		}
+		if label == "" {
+			return true
+		}
+		// every control plane node must also match the label
+		labeled, err := exec.OutputLines(node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"get",
+			"nodes",
+			"--selector="+selector+","+label,
+			`-o=jsonpath={range .items[*]}{.metadata.name}{"\n"}{end}`,
+		))
+		if err != nil {
+			return false
+		}
+		for _, name := range labeled {
+			if !ignored[name] {
+				waited--
+			}
+		}
+		return waited == 0
+	})
+}
+
+// waitForNodes uses kubectl inside the "node" container to wait for all
+// expected nodes to have condition set to True (Ready if unset), returning
+// the nodes that do not when until has passed, or an error if fewer than
+// minReady nodes do, the ignored nodes are not checked or counted
+func waitForNodes(node nodes.Node, until time.Time, interval time.Duration, condition string, expected, minReady int, ignored map[string]bool) ([]string, error) {
+	if condition == "" {
+		condition = "Ready"
+	}
+	var ready, notReady []string
+	tryUntil(until, interval, func() bool {
+		lines, err := exec.OutputLines(node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"get",
+			"nodes",
+			fmt.Sprintf(`-o=jsonpath={range .items[*]}{.metadata.name}={.status.conditions[?(@.type=="%s")].status}{"\n"}{end}`, condition),
+		))
+		if err != nil {
+			return false
+		}
+		ready, notReady = []string{}, []string{}
+		for _, line := range lines {
+			parts := strings.SplitN(line, "=", 2)
+			if ignored[parts[0]] {
+				continue
+			}
+			if len(parts) == 2 && parts[1] == "True" {
+				ready = append(ready, parts[0])
+			} else {
+				notReady = append(notReady, parts[0])
+			}
+		}
+		return len(ready) >= expected
+	})
+	// nodes that failed to register are not listed at all
+	if missing := expected - len(ready) - len(notReady); missing > 0 {
+		notReady = append(notReady, fmt.Sprintf("%d unregistered node(s)", missing))
+	}
+	if len(ready) < minReady {
+		return nil, errors.Errorf(
+			"only %d of %d nodes are Ready, at least %d are required, not Ready: %s",
+			len(ready), expected, minReady, strings.Join(notReady, ", "),
+		)
+	}
+	return notReady, nil
+}
+
+// waitForSystemWorkloads uses kubectl inside the "node" container to check if
+// the kube-system deployments and daemonsets have all of their desired pods
+// ready, returning the ones that are not when until has passed
+// the workloads named in excludeNames (name or kind/name) or with any of
+// excludeLabels (key or key=value) are not checked
+func waitForSystemWorkloads(node nodes.Node, until time.Time, interval time.Duration, excludeNames, excludeLabels []string) []string {
+	// kind -> jsonpath for "name ready desired" lines
+	workloads := []struct {
+		kind     string
+		jsonpath string
+	}{
+		{
+			kind:     "deployment",
+			jsonpath: `{range .items[*]}{.metadata.name} {.status.readyReplicas} {.spec.replicas}{"\n"}{end}`,
+		},
+		{
+			kind:     "daemonset",
+			jsonpath: `{range .items[*]}{.metadata.name} {.status.numberReady} {.status.desiredNumberScheduled}{"\n"}{end}`,
+		},
+	}
+	lagging := []string{"kube-system workloads"}
+	tryUntil(until, interval, func() bool {
+		current := []string{}
+		for _, w := range workloads {
+			lines, err := exec.OutputLines(node.Command(
+				"kubectl",
+				"--kubeconfig=/etc/kubernetes/admin.conf",
+				"get",
+				w.kind,
+				"--namespace=kube-system",
+				"-o=jsonpath="+w.jsonpath,
+			))
+			if err != nil {
+				return false
+			}
+			excluded, err := excludedWorkloads(node, w.kind, excludeNames, excludeLabels)
+			if err != nil {
+				return false
+			}
+			waited := []string{}
+			for _, line := range lines {
+				if fields := strings.Fields(line); len(fields) > 0 && excluded[fields[0]] {
+					continue
+				}
+				waited = append(waited, line)
+			}
+			current = append(current, notReady(w.kind, waited)...)
+		}
+		lagging = current
+		return len(lagging) == 0
+	})
+	return lagging
+}
+
+// excludedWorkloads returns the names of the kube-system workloads of kind
+// named in excludeNames (name or kind/name) or with any of excludeLabels
+func excludedWorkloads(node nodes.Node, kind string, excludeNames, excludeLabels []string) (map[string]bool, error) {
+	excluded := make(map[string]bool)
+	for _, name := range excludeNames {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) == 1 {
+			excluded[name] = true
+		} else if parts[0] == kind {
+			excluded[parts[1]] = true
+		}
+	}
+	for _, label := range excludeLabels {
+		names, err := exec.OutputLines(node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"get",
+			kind,
+			"--namespace=kube-system",
+			"--selector="+label,
+			`-o=jsonpath={range .items[*]}{.metadata.name}{"\n"}{end}`,
+		))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			excluded[name] = true
+		}
+	}
+	return excluded, nil
+}
+
+// waitForDaemonSet uses kubectl inside the "node" container to check if the
+// daemonset namespacedName (namespace/name) has all of its desired pods ready,
+// returning it if it does not when until has passed
+func waitForDaemonSet(node nodes.Node, until time.Time, interval time.Duration, namespacedName string) []string {
+	parts := strings.SplitN(namespacedName, "/", 2)
+	namespace, name := parts[0], parts[1]
+	lagging := []string{"daemonset/" + namespacedName}
+	tryUntil(until, interval, func() bool {
+		lines, err := exec.OutputLines(node.Command(
+			"kubectl",
+			"--kubeconfig=/etc/kubernetes/admin.conf",
+			"get",
+			"daemonset",
+			"--namespace="+namespace,
+			name,
+			`-o=jsonpath={.metadata.name} {.status.numberReady} {.status.desiredNumberScheduled}{"\n"}`,
+		))
+		if err != nil {
+			return false
+		}
+		lagging = notReady("daemonset", lines)
+		return len(lagging) == 0
+	})
+	return lagging
+}
+
+// notReady returns kind/name for each of the "name ready desired" lines
+// with fewer ready than desired pods
+func notReady(kind string, lines []string) []string {
+	result := []string{}
+	for _, line := range lines {
+		// ready is omitted entirely while there are none
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, desired := fields[0], fields[len(fields)-1]
+		ready := "0"
+		if len(fields) == 3 {
+			ready = fields[1]
+		}
+		if ready != desired {
+			result = append(result, fmt.Sprintf("%s/%s (%s/%s ready)", kind, name, ready, desired))
+		}
+	}
+	return result
+}
+
 // helper that calls `try()`` in a loop until the deadline `until`
 // has passed or `try()`returns true, returns whether try ever returned true
-func tryUntil(until time.Time, try func() bool) bool {
+// successive calls to `try()` start at least `interval` apart
+func tryUntil(until time.Time, interval time.Duration, try func() bool) bool {
 	for until.After(time.Now()) {
+		next := time.Now().Add(interval)
 		if try() {
 			return true
 		}
+		// don't sleep past the deadline
+		if next.After(until) {
+			next = until
+		}
+		time.Sleep(time.Until(next))
 	}
 	return false
 }
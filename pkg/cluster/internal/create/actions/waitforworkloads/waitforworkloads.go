@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package waitforworkloads implements an action to wait for workloads in
+// the cluster to be ready
+package waitforworkloads
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+)
+
+// DefaultTimeout is how long each workload is waited for if its Timeout is
+// not set
+const DefaultTimeout = 5 * time.Minute
+
+// WorkloadRef identifies a workload to wait for
+type WorkloadRef struct {
+	// Namespace is the workload's namespace, if unset "default" is used
+	Namespace string
+	// Kind is one of Deployment, StatefulSet, DaemonSet or Job
+	Kind string
+	// Name is the workload's name
+	Name string
+	// Timeout is how long to wait for the workload to be ready, if zero
+	// DefaultTimeout is used
+	Timeout time.Duration
+}
+
+func (w *WorkloadRef) String() string {
+	return fmt.Sprintf("%s %s/%s", w.Kind, w.namespace(), w.Name)
+}
+
+func (w *WorkloadRef) namespace() string {
+	if w.Namespace == "" {
+		return "default"
+	}
+	return w.Namespace
+}
+
+// resource is the kubectl resource for the workload's kind
+func (w *WorkloadRef) resource() string {
+	return strings.ToLower(w.Kind) + "/" + w.Name
+}
+
+type action struct {
+	workloads []WorkloadRef
+}
+
+// NewAction returns a new action for waiting for workloads to be ready, in
+// order, see Validate for the supported workloads
+func NewAction(workloads []WorkloadRef) actions.Action {
+	return &action{
+		workloads: workloads,
+	}
+}
+
+// Execute runs the action
+func (a *action) Execute(ctx *actions.ActionContext) error {
+	ctx.Status.Start("Waiting for workloads ⏳")
+	defer ctx.Status.End(false)
+
+	allNodes, err := ctx.Nodes()
+	if err != nil {
+		return err
+	}
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+
+	for i := range a.workloads {
+		w := &a.workloads[i]
+		start := time.Now()
+		if err := wait(node, w); err != nil {
+			return errors.Wrapf(err, "%s is not ready, last status: %s", w, lastStatus(node, w))
+		}
+		ctx.Logger.V(1).Infof("%s is ready after %s", w, time.Since(start).Round(time.Second))
+	}
+
+	// mark success
+	ctx.Status.End(true)
+	return nil
+}
+
+// Validate returns an error if any of workloads cannot be waited for
+func Validate(workloads []WorkloadRef) error {
+	errs := []error{}
+	for i := range workloads {
+		w := &workloads[i]
+		switch w.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "Job":
+		default:
+			errs = append(errs, errors.Errorf("invalid workload %d kind %q: must be one of Deployment, StatefulSet, DaemonSet or Job", i, w.Kind))
+		}
+		if w.Name == "" {
+			errs = append(errs, errors.Errorf("invalid workload %d: name must be set", i))
+		}
+		if w.Timeout < 0 {
+			errs = append(errs, errors.Errorf("invalid workload %d timeout %s: must not be negative", i, w.Timeout))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// wait waits for w to be ready, a job is ready once it has completed
+func wait(node nodes.Node, w *WorkloadRef) error {
+	timeout := w.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// the workload may not exist yet if an operator creates it, so retry
+	// until it does, kubectl then waits for it within the timeout
+	for {
+		err := kubectl(ctx, node, nil, "get", "--namespace", w.namespace(), w.resource())
+		if err == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("timed out after %s waiting for %s to exist", timeout, w)
+		case <-time.After(time.Second):
+		}
+	}
+	timeoutFlag := "--timeout=" + timeout.String()
+	if w.Kind == "Job" {
+		return kubectl(ctx, node, nil, "wait", "--namespace", w.namespace(), "--for=condition=complete", timeoutFlag, w.resource())
+	}
+	return kubectl(ctx, node, nil, "rollout", "status", "--namespace", w.namespace(), timeoutFlag, w.resource())
+}
+
+// lastStatus returns w's status for reporting why it is not ready
+func lastStatus(node nodes.Node, w *WorkloadRef) string {
+	var out bytes.Buffer
+	if err := kubectl(context.Background(), node, &out, "get", "--namespace", w.namespace(), w.resource(), "-o=jsonpath={.status}"); err != nil {
+		return "unknown"
+	}
+	if status := strings.TrimSpace(out.String()); status != "" {
+		return status
+	}
+	return "none"
+}
+
+func kubectl(ctx context.Context, node nodes.Node, stdout *bytes.Buffer, args ...string) error {
+	cmd := node.CommandContext(ctx,
+		"kubectl",
+		append([]string{"--kubeconfig=/etc/kubernetes/admin.conf"}, args...)...,
+	)
+	if stdout != nil {
+		cmd.SetStdout(stdout)
+	}
+	return cmd.Run()
+}
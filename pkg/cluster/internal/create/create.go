@@ -18,14 +18,27 @@ package create
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alessio/shellescape"
+	"k8s.io/apimachinery/pkg/util/version"
 
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
+	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/internal/delete"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
@@ -33,165 +46,1608 @@ import (
 	"sigs.k8s.io/kind/pkg/log"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
-	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
-	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/checkversion"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/corednsupstreams"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcacerts"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/localregistry"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/nodelocaldns"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/seedobjects"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforworkloads"
 	"sigs.k8s.io/kind/pkg/cluster/internal/kubeconfig"
 )
 
 const (
 	// Typical host name max limit is 64 characters (https://linux.die.net/man/2/sethostname)
+	hostNameMax = 64
 	// We append -control-plane (14 characters) to the cluster name on the control plane container
-	clusterNameMax = 50
+	clusterNameMax = hostNameMax - 14
 )
 
+// nodeImageEnv may be set to choose the node image for any nodes that do
+// not otherwise have an image specified, see fixupNodeImages
+const nodeImageEnv = "KIND_NODE_IMAGE"
+
 // similar to valid docker container names, but since we will prefix
 // and suffix this name, we can relax it a little
 // see NewContext() for usage
 // https://godoc.org/github.com/docker/docker/daemon/names#pkg-constants
 var validNameRE = regexp.MustCompile(`^[a-z0-9_.-]+$`)
 
-// ClusterOptions holds cluster creation options
-type ClusterOptions struct {
-	Config       *config.Cluster
-	NameOverride string // overrides config.Name
-	// NodeImage overrides the nodes' images in Config if non-zero
-	NodeImage      string
-	Retain         bool
-	WaitForReady   time.Duration
-	KubeconfigPath string
-	// see https://github.com/kubernetes-sigs/kind/issues/324
-	StopBeforeSettingUpKubernetes bool // if false kind should setup kubernetes after creating nodes
-	// Options to control output
-	DisplayUsage      bool
-	DisplaySalutation bool
+// ClusterOptions holds cluster creation options
+type ClusterOptions struct {
+	Config *config.Cluster
+	// ConfigPaths are config files merged in order to replace Config if set,
+	// see encoding.LoadMerged
+	ConfigPaths []string
+	// ConfigTemplatePath is a config template rendered with ConfigVars to
+	// replace Config if set, see encoding.LoadTemplate
+	ConfigTemplatePath string
+	ConfigVars         map[string]string
+	NameOverride       string // overrides config.Name
+	// NodeImage overrides the nodes' images in Config if non-zero
+	NodeImage string
+	// KubeProxyMode overrides the kube-proxy mode in Config if non-zero
+	KubeProxyMode string
+	// RespectPerNodeImages limits NodeImage to nodes without an image in Config
+	RespectPerNodeImages bool
+	Retain               bool
+	// MutateConfig is called with the defaulted config before it is
+	// validated if set, an error aborts creating the cluster
+	MutateConfig func(*config.Cluster) error
+	// ImageResolver, if set, is applied to every node image after defaulting
+	// E.G. to rewrite the registry to a mirror or pin the image by digest
+	ImageResolver func(image string) (string, error)
+	// FailureCleanupDelay is how long to wait before deleting the cluster
+	// after a failure to create it, ignored if Retain is set
+	FailureCleanupDelay time.Duration
+	// WaitForReady is the maximum time to wait for the control plane to be
+	// ready, if zero the wait-for-ready action is not run at all
+	WaitForReady time.Duration
+	// ReadyPollInterval is the minimum time between checks while waiting
+	// for the control plane to be ready, if zero checks are back to back
+	ReadyPollInterval time.Duration
+	// WaitForSystemPods also waits for the kube-system deployments and
+	// daemonsets to be ready while waiting for the control plane to be ready
+	WaitForSystemPods bool
+	// SystemPodsExcludeNames are kube-system deployments and daemonsets not
+	// to wait for with WaitForSystemPods, by name or kind/name
+	SystemPodsExcludeNames []string
+	// SystemPodsExcludeLabels are labels, key or key=value, of kube-system
+	// deployments and daemonsets not to wait for with WaitForSystemPods
+	SystemPodsExcludeLabels []string
+	// ContinueOnNodeFailure waits for all of the nodes to be ready rather than
+	// only the control plane while waiting for ready, and continues creating
+	// the cluster without the nodes that are not ready in time
+	ContinueOnNodeFailure bool
+	// MinReadyNodes is the minimum number of ready nodes required to
+	// continue with ContinueOnNodeFailure, if zero there is no minimum
+	MinReadyNodes int
+	// IgnoreNodesForReady are the Kubernetes node names of nodes not to wait
+	// for while waiting for ready, they are still created and joined
+	IgnoreNodesForReady []string
+	// CNIReadyDaemonSet is the namespace/name of a third party CNI daemonset
+	// to also wait for while waiting for ready, if set
+	CNIReadyDaemonSet string
+	// ExpectKubernetesVersion fails creating the cluster if the API server
+	// is not running this version after waiting for ready, or the bootstrap
+	// control plane's kubeadm is not this version before kubeadm init, if
+	// set, see checkversion.Matches
+	ExpectKubernetesVersion string
+	// VerifyAPIServerHA fails creating the cluster if any control plane node
+	// is not serving a healthy API server after waiting for ready, this
+	// requires multiple control plane nodes
+	VerifyAPIServerHA bool
+	// UntaintControlPlane removes the NoSchedule taints from the control
+	// plane nodes after waiting for ready, so that workloads may run on them
+	UntaintControlPlane bool
+	// CNIImage replaces the default CNI's kindnetd image if set,
+	// E.G. with an image mirrored for air-gapped environments
+	CNIImage string
+	// AddonApplyRetries bounds retrying installing the default CNI, storage
+	// and node-local DNS cache while the API server is not ready, if zero
+	// actions.DefaultApplyRetries is used, actions.NoApplyRetries disables
+	// retrying
+	AddonApplyRetries int
+	// NetworkMTU is the MTU of the node network when the provider creates it,
+	// and of the default CNI's pod interfaces, if zero they are detected
+	NetworkMTU int
+	// NodeCIDRMaskSizeIPv4 and NodeCIDRMaskSizeIPv6 are the prefix lengths of
+	// the pod CIDR assigned to each node by the kube-controller-manager in
+	// ipv4 and ipv6 clusters, if zero the kube-controller-manager defaults of
+	// 24 and 64 are used
+	NodeCIDRMaskSizeIPv4 int32
+	NodeCIDRMaskSizeIPv6 int32
+	// NodeLocalDNS installs the node-local DNS cache after waiting for ready,
+	// this requires the default CNI and kube-proxy in iptables mode
+	NodeLocalDNS bool
+	// CoreDNSUpstreams are the DNS servers CoreDNS forwards to instead of the
+	// node's resolv.conf if set, see corednsupstreams.ValidateUpstream
+	CoreDNSUpstreams []string
+	// SchedulableControlPlane registers the control plane nodes without the
+	// NoSchedule taint and also removes the taints after waiting for ready,
+	// it is intended for single node clusters
+	SchedulableControlPlane bool
+	// SeedObjects are created in the cluster in order after waiting for ready,
+	// see seedobjects.Validate for the supported objects
+	SeedObjects []interface{}
+	// WaitForWorkloads are waited for in order after creating the seed
+	// objects, each within its own timeout
+	WaitForWorkloads []WorkloadRef
+	// ReadyCondition is the node condition type to wait for instead of Ready
+	// while waiting for the control plane to be ready, if set
+	ReadyCondition string
+	// ReadyLabel is a label (key or key=value) the control plane nodes must
+	// also have while waiting for the control plane to be ready, if set
+	ReadyLabel string
+	// APIServerReadyTimeout is the maximum time to wait for the API server
+	// to be serving before installing add-ons, if zero a short default is used
+	APIServerReadyTimeout time.Duration
+	KubeconfigPath        string
+	// KubeconfigStandalone writes a kubeconfig containing only this cluster
+	// to KubeconfigPath, replacing rather than merging into any existing file
+	KubeconfigStandalone bool
+	// KubeconfigCAPath is a host path the cluster CA is also written to if
+	// set, the exported kubeconfig references it rather than embedding it
+	KubeconfigCAPath string
+	// CACertPath, ClientCertPath, and ClientKeyPath are host paths the
+	// cluster CA and the admin client certificate and key are written to
+	// after creating the cluster, each if set
+	CACertPath     string
+	ClientCertPath string
+	ClientKeyPath  string
+	// KubeconfigInsecureSkipTLSVerify exports a kubeconfig that does not
+	// verify the API server certificate, this is only meant for local dev
+	KubeconfigInsecureSkipTLSVerify bool
+	// KubeconfigEncryptionKey encrypts the kubeconfig written to
+	// KubeconfigPath with this 32 byte key, hex or base64 encoded, if set
+	KubeconfigEncryptionKey string
+	// IgnoreKubeconfigContextClash disables warning when merging the
+	// kubeconfig replaces an existing context with the cluster's name that
+	// points at another server or CA, E.G. left behind by a dead cluster
+	IgnoreKubeconfigContextClash bool
+	// see https://github.com/kubernetes-sigs/kind/issues/324
+	StopBeforeSettingUpKubernetes bool // if false kind should setup kubernetes after creating nodes
+	// KubeletConfigVersion overrides the KubeletConfiguration apiVersion
+	// detected from each node's Kubernetes version if set, it is validated
+	// against the known apiVersions (currently only v1beta1)
+	KubeletConfigVersion string
+	// APIServerAdvertiseAddress overrides the address advertised by the
+	// bootstrap control plane node's API server if set
+	APIServerAdvertiseAddress string
+	// OnKubeadmConfig is called with each node's generated kubeadm config
+	// before it is written to the node if set
+	OnKubeadmConfig func(nodeName string, config []byte)
+	// OnBeforeCleanup is called with the error creating the cluster right
+	// before the cluster is deleted because of it, E.G. to collect
+	// diagnostics, if set. An error it returns is logged and the cluster is
+	// still deleted
+	OnBeforeCleanup func(ctx *CleanupContext, err error) error
+	// ControlPlaneLivenessInitialDelay and ControlPlaneLivenessTimeout relax
+	// the API server and etcd liveness probes if non-zero, in whole seconds
+	ControlPlaneLivenessInitialDelay time.Duration
+	ControlPlaneLivenessTimeout      time.Duration
+	// ShutdownGracePeriod and ShutdownGracePeriodCriticalPods enable the
+	// kubelet's graceful node shutdown and its feature gate if non-zero, see
+	// kubeadm.GracefulShutdown
+	ShutdownGracePeriod             time.Duration
+	ShutdownGracePeriodCriticalPods time.Duration
+	// APIServerRequestTimeout, APIServerDefaultWatchCacheSize, and
+	// APIServerWatchCacheSizes set the corresponding API server flags for
+	// scale testing if set, see kubeadm.APIServerTuning
+	APIServerRequestTimeout        time.Duration
+	APIServerDefaultWatchCacheSize *int32
+	APIServerWatchCacheSizes       map[string]int32
+	// EnableAdmissionPlugins and DisableAdmissionPlugins are the API server's
+	// --enable-admission-plugins and --disable-admission-plugins if set,
+	// kubeadm.RequiredAdmissionPlugins may only be disabled if
+	// ForceDisableAdmissionPlugins is also set
+	EnableAdmissionPlugins       []string
+	DisableAdmissionPlugins      []string
+	ForceDisableAdmissionPlugins bool
+	// CloudProvider configures the kubelet and control plane for the cloud
+	// provider if set, currently only "external" is supported
+	CloudProvider string
+	// DNSDomain is the cluster's DNS domain for services, if unset
+	// the kubeadm default (cluster.local) is used
+	DNSDomain string
+	// BootstrapToken is the kubeadm bootstrap token for joining the nodes,
+	// if unset a well known token is used
+	BootstrapToken string
+	// BootstrapTokenTTL is the lifetime of the bootstrap token, if zero the
+	// kubeadm default is used
+	BootstrapTokenTTL time.Duration
+	// PodSecurityConfigPath is the host path of an AdmissionConfiguration
+	// for the API server's PodSecurity admission plugin, if set
+	PodSecurityConfigPath string
+	// OIDC configures the API server to authenticate OIDC ID tokens if not
+	// zero valued, see kubeadm.OIDCConfig
+	OIDC OIDCConfig
+	// Konnectivity routes the API server's traffic to the nodes and pods
+	// through a konnectivity server on the control plane and agents on every
+	// node, as in network isolated control planes. This requires Kubernetes
+	// v1.20.0 or newer and a single control plane node, see
+	// kubeadm.ValidateKonnectivity
+	Konnectivity bool
+	// NodeCACerts are paths to PEM encoded CA certificates on the host to
+	// install into every node's trust store before starting Kubernetes
+	NodeCACerts []string
+	// ContainerdVersion replaces the node image's containerd with this
+	// release (E.G. v1.6.8) in every node before starting Kubernetes, if set
+	ContainerdVersion string
+	// SharedImageCache is a host directory for a pull through registry
+	// cache of docker.io images shared by all nodes, if set
+	SharedImageCache string
+	// NodeLogDir is a host directory to persist each node's /var/log in if
+	// set, under a directory per cluster and then per node
+	NodeLogDir string
+	// LocalRegistry creates a local image registry for the cluster if set,
+	// published on the host at localhost:LocalRegistryPort
+	LocalRegistry     bool
+	LocalRegistryPort int32
+	// RestartPolicy overrides the node container restart policy if set
+	RestartPolicy string
+	// SecurityProfile hardens the node containers if set, the only
+	// supported profile is common.SecurityProfileHardened
+	SecurityProfile string
+	// ExternalLoadBalancerEndpoint is the host:port of a load balancer
+	// managed outside of kind to use as the control plane endpoint if set,
+	// in which case no load balancer is created
+	ExternalLoadBalancerEndpoint string
+	// HostAddressOverride is the address the ports published by the container
+	// runtime are reachable at if set, E.G. when the address of a remote
+	// DOCKER_HOST reported by docker is not reachable from here
+	HostAddressOverride string
+	// NodeNameTemplate is a text/template the node container names are
+	// rendered from if set, E.G. "{{.Cluster}}-{{.Role}}-{{.Index}}", see
+	// common.NodeNameData for the available fields
+	NodeNameTemplate string
+	// ControlPlaneEndpoint is the host:port kubeadm uses as the cluster's
+	// controlPlaneEndpoint if set, E.G. a stable DNS name, it must be
+	// reachable from the nodes and is used by the kubeconfig and joins
+	ControlPlaneEndpoint string
+	// ControlPlaneStagger is the delay between starting each control plane
+	// node container, if zero they are all started at once
+	ControlPlaneStagger time.Duration
+	// ImagePullTimeout bounds pulling each node image, if zero a generous
+	// default is used, see common.DefaultImagePullTimeout
+	ImagePullTimeout time.Duration
+	// NodePlatform is the os/arch[/variant] platform to pull and run the node
+	// images for if set, E.G. linux/arm64, overriding the runtime's own
+	NodePlatform string
+	// KubeletRootDir overrides the kubelet's root directory in the nodes if
+	// set, see common.ValidateKubeletRootDir
+	KubeletRootDir string
+	// ExistingNetworkID is the ID of a docker network created outside of
+	// kind, E.G. with a custom subnet or MTU, to attach the nodes to if set.
+	// kind never creates or deletes it, and its subnets must not overlap
+	// the cluster's pod and service subnets
+	ExistingNetworkID string
+	// SecondaryNetworks are extra networks attached to every node after the
+	// primary node network, E.G. for Multus, the nodes have the interfaces
+	// in order, see SecondaryNetworkInterface. Networks that do not exist are
+	// created and are not deleted with the cluster, their subnets must not
+	// overlap the primary network's or the cluster's pod and service subnets
+	SecondaryNetworks []NetworkSpec
+	// NodeExecEnv is set on every command the actions run in the nodes,
+	// E.G. HTTP_PROXY for add-on installs that reach the internet. It
+	// overrides the node container's environment for those commands only,
+	// and any env an action sets on its own commands overrides it
+	NodeExecEnv map[string]string
+	// Timezone is the tz database name of the nodes' timezone, this affects
+	// the timestamps of the processes and logs in the node containers but not
+	// Kubernetes scheduling, if unset the nodes are in
+	// common.DefaultTimezone
+	Timezone string
+	// AutoPort picks and reserves the unset published host ports (the API
+	// server port and extra port mappings with hostPort 0) before creating
+	// the nodes, so that clusters created concurrently by multiple kind
+	// processes do not collide
+	AutoPort bool
+	// OnAutoPorts is called with the cluster config after AutoPort has
+	// filled in the chosen host ports, if set
+	OnAutoPorts func(cfg *config.Cluster)
+	// FailOnLowDisk fails creating the cluster if there may not be enough
+	// free disk space for it, instead of only warning
+	FailOnLowDisk bool
+	// FailOnInsufficientResources fails creating the cluster if the host may
+	// not have enough CPUs or memory for it, instead of only warning
+	FailOnInsufficientResources bool
+	// DiagnosticsBundlePath is a host path to write a tarball of logs and
+	// other debug info to after creating the cluster (or failing to), if set
+	DiagnosticsBundlePath string
+	// ImageInventoryPath is a host path to write the images present in each
+	// node to as JSON after creating the cluster, if set
+	ImageInventoryPath string
+	// VerboseKubeadm logs kubeadm init / join output at V(1) as it runs
+	VerboseKubeadm bool
+	// JoinConcurrency is the most worker nodes to join at the same time if
+	// non-zero, otherwise all of the workers join at once
+	JoinConcurrency int
+	// PrintJoinCommand logs a kubeadm join command for joining nodes from
+	// outside of kind to the cluster at the published API server endpoint
+	PrintJoinCommand bool
+	// JoinTokenTTL is the lifetime of the bootstrap token in the join command,
+	// if zero the kubeadm default is used
+	JoinTokenTTL time.Duration
+	// StopAfterAction stops creating the cluster after running the named
+	// action if set, the cluster is always retained in this mode
+	StopAfterAction string
+	// OnExisting is what to do if a cluster with the same name already exists
+	OnExisting OnExisting
+	// Actions is the exact ordered list of built-in actions to run after
+	// creating the nodes, if unset the default actions are run
+	Actions []string
+	// ActionVerbosity maps action names to the log verbosity to use while
+	// running that action, if greater than the logger's verbosity
+	ActionVerbosity map[string]int
+	// PlanOutput writes what would be created to stdout in this format,
+	// "json" or "yaml", instead of creating the cluster if set
+	PlanOutput string
+	// LogWriter receives a copy of each message logged while creating the
+	// cluster as a line without terminal color codes if set, the V(0)
+	// messages are always copied and the others if enabled on the logger
+	LogWriter io.Writer
+	// Quiet only logs warnings and errors, without the status spinner, the
+	// usage, or the salutation regardless of DisplayUsage and
+	// DisplaySalutation. LogWriter still receives the V(0) messages.
+	Quiet bool
+	// Options to control output
+	DisplayUsage      bool
+	DisplaySalutation bool
+}
+
+// Cluster creates a cluster
+func Cluster(logger log.Logger, p providers.Provider, opts *ClusterOptions) error {
+	if opts.Quiet {
+		logger = quietLogger{logger}
+	}
+	if opts.LogWriter != nil {
+		logger = newWriterLogger(logger, opts.LogWriter)
+	}
+
+	// default / process options (namely config)
+	if err := fixupOptions(logger, opts); err != nil {
+		return err
+	}
+
+	// TODO: move to config validation
+	// validate the name
+	if !validNameRE.MatchString(opts.Config.Name) {
+		return errors.Errorf(
+			"'%s' is not a valid cluster name, cluster names must match `%s`",
+			opts.Config.Name, validNameRE.String(),
+		)
+	}
+	// warn if cluster name might typically be too long, templated node
+	// names are instead validated along with the other options
+	if opts.NodeNameTemplate == "" && len(opts.Config.Name) > clusterNameMax {
+		logger.Warnf("cluster name %q is probably too long, this might not work properly on some systems", opts.Config.Name)
+	}
+
+	// then validate, first the structure for clearer errors
+	if err := config.ValidateSchema(opts.Config); err != nil {
+		return errors.Wrap(err, "invalid cluster config")
+	}
+	if err := opts.Config.Validate(); err != nil {
+		return err
+	}
+	if err := validateOptions(opts); err != nil {
+		return err
+	}
+	for i, node := range opts.Config.Nodes {
+		if len(node.Entrypoint) > 0 {
+			logger.Warnf("WARNING: node %d overrides the node entrypoint with %v, this is NOT supported!", i, node.Entrypoint)
+			logger.Warn("WARNING: kind will fail to set up Kubernetes on this node unless the entrypoint starts systemd")
+		}
+		if node.GPUs != "" {
+			logger.Warnf("WARNING: node %d uses host GPUs, the node image must include the container runtime hooks for them (E.G. the NVIDIA container toolkit)", i)
+		}
+	}
+
+	if opts.KubeletRootDir != "" && opts.KubeletRootDir != common.DefaultKubeletRootDir {
+		logger.Warnf("WARNING: the kubelet root dir is %s, some CSI drivers assume the default %s and will not work", opts.KubeletRootDir, common.DefaultKubeletRootDir)
+	}
+
+	if n := kubernetesNodeCount(opts.Config); opts.SchedulableControlPlane && n > 1 {
+		logger.Warnf(
+			"WARNING: a schedulable control plane is intended for single node clusters, workloads will be scheduled on the control plane nodes of this %d node cluster",
+			n,
+		)
+	}
+
+	if capacity, n := nodeCIDRCapacity(opts), kubernetesNodeCount(opts.Config); capacity > 0 && capacity < n {
+		logger.Warnf(
+			"WARNING: the pod subnet %s only fits %d node CIDRs of size /%d, %d of the %d nodes will not be assigned a pod CIDR",
+			opts.Config.Networking.PodSubnet, capacity, nodeCIDRMaskSize(opts), n-capacity, n,
+		)
+	}
+
+	// plan the actions to run after the nodes are created
+	actionsToRun, err := planActions(opts)
+	if err != nil {
+		return err
+	}
+
+	// only show the plan if requested, without touching the provider
+	if opts.PlanOutput != "" {
+		return writePlan(os.Stdout, opts, actionsToRun)
+	}
+
+	// handle an existing cluster with the same name before creating anything
+	if exists, err := clusterExists(p, opts.Config.Name); err != nil {
+		return err
+	} else if exists {
+		switch opts.OnExisting {
+		case OnExistingReuse:
+			logger.V(0).Infof("Reusing existing cluster %q", opts.Config.Name)
+			if err := exportKubeconfig(logger, p, opts); err != nil {
+				return err
+			}
+			return exportCredentials(p, opts)
+		case OnExistingRecreate:
+			logger.V(0).Infof("Deleting existing cluster %q ...", opts.Config.Name)
+			if err := delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath); err != nil {
+				return errors.Wrapf(err, "failed to delete existing cluster %q", opts.Config.Name)
+			}
+		default:
+			return &AlreadyExistsError{Name: opts.Config.Name}
+		}
+	}
+
+	// creation fails late and confusingly when out of disk, so check early
+	if err := checkDiskSpace(logger, p, opts); err != nil {
+		return err
+	}
+
+	// overcommitted hosts time out waiting for nodes, so check early
+	if err := checkCapacity(logger, p, opts); err != nil {
+		return err
+	}
+
+	// MTU mismatches break pod networking silently, so warn early
+	checkHostMTU(logger, opts.NetworkMTU)
+
+	if err := prepareNodeLogDir(opts); err != nil {
+		return err
+	}
+
+	// setup a status object to show progress to the user
+	status := cli.StatusForLogger(logger)
+
+	// we're going to start creating now, tell the user
+	logger.V(0).Infof("Creating cluster %q ...\n", opts.Config.Name)
+
+	// pick ports up front if requested, these are held until the nodes exist
+	releasePorts := func() {}
+	if opts.AutoPort {
+		releasePorts, err = reservePorts(logger, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create node containers implementing defined config Nodes
+	err = p.Provision(status, opts.Config, providers.ProvisionOptions{
+		RestartPolicy:        opts.RestartPolicy,
+		ExternalLoadBalancer: opts.ExternalLoadBalancerEndpoint != "",
+		ControlPlaneStagger:  opts.ControlPlaneStagger,
+		SecurityProfile:      opts.SecurityProfile,
+		HostAddress:          opts.HostAddressOverride,
+		NodeNameTemplate:     opts.NodeNameTemplate,
+		ImagePullTimeout:     opts.ImagePullTimeout,
+		KubeletRootDir:       opts.KubeletRootDir,
+		NetworkMTU:           opts.NetworkMTU,
+		NodePlatform:         opts.NodePlatform,
+		Timezone:             opts.Timezone,
+		ExistingNetworkID:    opts.ExistingNetworkID,
+		SecondaryNetworks:    opts.SecondaryNetworks,
+		LocalRegistry:        opts.LocalRegistry,
+		// NOTE: this is absolute after fixupOptions
+		SharedImageCache: opts.SharedImageCache,
+	})
+	releasePorts()
+	if err != nil {
+		// In case of errors nodes are deleted (except if retain is explicitly set)
+		cleanupOnFailure(logger, p, opts, err)
+		return err
+	}
+
+	// run all actions
+	actionsContext := actions.NewActionContext(logger, status, p, opts.Config, opts.NodeExecEnv)
+	for _, planned := range actionsToRun {
+		actionContext := actionsContext
+		if verbosity, ok := opts.ActionVerbosity[planned.name]; ok {
+			actionContext = actionsContext.WithVerbosity(log.Level(verbosity))
+		}
+		if err := planned.action.Execute(actionContext); err != nil {
+			cleanupOnFailure(logger, p, opts, err)
+			return err
+		}
+	}
+
+	// skip the rest if we've been asked to stop early
+	if opts.StopAfterAction != "" {
+		logger.V(0).Infof("Stopped after action %q as requested, the cluster has been retained", opts.StopAfterAction)
+		collectDiagnostics(logger, p, opts)
+		return nil
+	}
+
+	// skip the rest if we're not setting up kubernetes
+	if opts.StopBeforeSettingUpKubernetes {
+		collectDiagnostics(logger, p, opts)
+		return nil
+	}
+
+	if err := exportKubeconfig(logger, p, opts); err != nil {
+		return err
+	}
+
+	if err := exportCredentials(p, opts); err != nil {
+		return err
+	}
+
+	if err := writeImageInventory(p, opts); err != nil {
+		return err
+	}
+
+	collectDiagnostics(logger, p, opts)
+
+	// optionally display usage
+	if opts.DisplayUsage && !opts.Quiet {
+		logUsage(logger, opts.Config.Name, opts.KubeconfigPath)
+		logSecondaryNetworks(logger, opts.SecondaryNetworks)
+	}
+	// optionally give the user a friendly salutation
+	if opts.DisplaySalutation && !opts.Quiet {
+		// only space out the salutation for humans, a blank line is just
+		// noise to structured log consumers
+		if isSmartLogger(logger) {
+			logger.V(0).Info("")
+		}
+		logSalutation(logger)
+	}
+	return nil
+}
+
+// validateOptions returns an error for any invalid options other than Config
+func validateOptions(opts *ClusterOptions) error {
+	errs := []error{}
+	if opts.KubeletConfigVersion != "" {
+		if err := kubeadm.ValidateKubeletConfigAPIVersion(opts.KubeletConfigVersion, ""); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.APIServerReadyTimeout < 0 {
+		errs = append(errs, errors.Errorf("invalid API server ready timeout %s: must not be negative", opts.APIServerReadyTimeout))
+	}
+	if opts.ControlPlaneStagger < 0 {
+		errs = append(errs, errors.Errorf("invalid control plane stagger %s: must not be negative", opts.ControlPlaneStagger))
+	}
+	if opts.AddonApplyRetries < actions.NoApplyRetries {
+		errs = append(errs, errors.Errorf("invalid addon apply retries %d: must not be negative, except %d to disable retrying", opts.AddonApplyRetries, actions.NoApplyRetries))
+	}
+	if opts.ImagePullTimeout < 0 {
+		errs = append(errs, errors.Errorf("invalid image pull timeout %s: must not be negative", opts.ImagePullTimeout))
+	}
+	if opts.ExistingNetworkID != "" && !validNetworkIDRE.MatchString(opts.ExistingNetworkID) {
+		errs = append(errs, errors.Errorf("invalid existing network ID %q: must be a full or short hex network ID", opts.ExistingNetworkID))
+	}
+	errs = append(errs, validateSecondaryNetworks(opts)...)
+	for key := range opts.NodeExecEnv {
+		if !validEnvKeyRE.MatchString(key) {
+			errs = append(errs, errors.Errorf("invalid node exec env key %q: must be a valid environment variable name", key))
+		}
+	}
+	if opts.NodePlatform != "" && !validNodePlatformRE.MatchString(opts.NodePlatform) {
+		errs = append(errs, errors.Errorf("invalid node platform %q: must be of the form os/arch or os/arch/variant, E.G. linux/arm64", opts.NodePlatform))
+	}
+	if err := validatePlanOutput(opts.PlanOutput); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateCredentialPaths(opts); err != nil {
+		errs = append(errs, err)
+	}
+	if opts.JoinConcurrency < 0 {
+		errs = append(errs, errors.Errorf("invalid join concurrency %d: must not be negative", opts.JoinConcurrency))
+	}
+	if opts.JoinTokenTTL < 0 {
+		errs = append(errs, errors.Errorf("invalid join token TTL %s: must not be negative", opts.JoinTokenTTL))
+	} else if opts.JoinTokenTTL%time.Second != 0 {
+		errs = append(errs, errors.Errorf("invalid join token TTL %s: must be a whole number of seconds", opts.JoinTokenTTL))
+	}
+	if opts.ReadyPollInterval < 0 {
+		errs = append(errs, errors.Errorf("invalid ready poll interval %s: must not be negative", opts.ReadyPollInterval))
+	} else if opts.ReadyPollInterval > opts.WaitForReady && opts.WaitForReady > 0 {
+		errs = append(errs, errors.Errorf("invalid ready poll interval %s: must not be larger than the wait for ready timeout %s", opts.ReadyPollInterval, opts.WaitForReady))
+	}
+	if opts.WaitForSystemPods && opts.WaitForReady <= 0 {
+		errs = append(errs, errors.New("waiting for system pods requires waiting for ready"))
+	}
+	if len(opts.SystemPodsExcludeNames) > 0 || len(opts.SystemPodsExcludeLabels) > 0 {
+		if !opts.WaitForSystemPods {
+			errs = append(errs, errors.New("excluding system pods requires waiting for system pods"))
+		}
+		if err := validateSystemPodsExclude(opts.SystemPodsExcludeNames, opts.SystemPodsExcludeLabels); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.ContinueOnNodeFailure {
+		if opts.WaitForReady <= 0 {
+			errs = append(errs, errors.New("continuing on node failure requires waiting for ready"))
+		}
+		if opts.ReadyLabel != "" {
+			errs = append(errs, errors.New("continuing on node failure cannot be combined with a ready label"))
+		}
+	}
+	if opts.MinReadyNodes < 0 {
+		errs = append(errs, errors.Errorf("invalid minimum ready nodes %d: must not be negative", opts.MinReadyNodes))
+	} else if opts.MinReadyNodes > 0 {
+		if !opts.ContinueOnNodeFailure {
+			errs = append(errs, errors.New("minimum ready nodes requires continuing on node failure"))
+		}
+		if n := kubernetesNodeCount(opts.Config) - len(opts.IgnoreNodesForReady); opts.MinReadyNodes > n {
+			errs = append(errs, errors.Errorf("invalid minimum ready nodes %d: the cluster only has %d nodes to wait for", opts.MinReadyNodes, n))
+		}
+	}
+	if len(opts.IgnoreNodesForReady) > 0 {
+		if opts.WaitForReady <= 0 {
+			errs = append(errs, errors.New("ignoring nodes for ready requires waiting for ready"))
+		}
+		if err := validateIgnoreNodesForReady(opts.Config, opts.NodeNameTemplate, opts.IgnoreNodesForReady); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.CNIReadyDaemonSet != "" {
+		if opts.WaitForReady <= 0 {
+			errs = append(errs, errors.New("waiting for the CNI requires waiting for ready"))
+		}
+		if err := validateNamespacedName(opts.CNIReadyDaemonSet); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid CNI daemonset"))
+		}
+	}
+	if opts.OnExisting < OnExistingFail || opts.OnExisting > OnExistingRecreate {
+		errs = append(errs, errors.Errorf("invalid existing cluster behavior %d", opts.OnExisting))
+	}
+	if opts.CNIImage != "" {
+		if opts.Config.Networking.DisableDefaultCNI {
+			errs = append(errs, errors.New("a CNI image cannot be used with disableDefaultCNI"))
+		}
+		if !validImageRE.MatchString(opts.CNIImage) {
+			errs = append(errs, errors.Errorf("invalid CNI image %q: must be an image reference", opts.CNIImage))
+		}
+	}
+	if opts.NetworkMTU != 0 && (opts.NetworkMTU < minNetworkMTU || opts.NetworkMTU > maxNetworkMTU) {
+		errs = append(errs, errors.Errorf("invalid network MTU %d: must be between %d and %d", opts.NetworkMTU, minNetworkMTU, maxNetworkMTU))
+	}
+	if err := validateNodeCIDRMaskSize(opts); err != nil {
+		errs = append(errs, err)
+	}
+	if len(opts.CoreDNSUpstreams) > corednsupstreams.MaxUpstreams {
+		errs = append(errs, errors.Errorf("invalid CoreDNS upstreams: at most %d are supported, got %d", corednsupstreams.MaxUpstreams, len(opts.CoreDNSUpstreams)))
+	}
+	for _, upstream := range opts.CoreDNSUpstreams {
+		if err := corednsupstreams.ValidateUpstream(upstream); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.NodeLocalDNS {
+		if _, err := nodelocaldns.DNSServiceIP(opts.Config.Networking.ServiceSubnet); err != nil {
+			errs = append(errs, errors.Wrap(err, "cannot install node-local DNS"))
+		}
+		// the cache intercepts the DNS service IP with iptables rules in
+		// each node, only kube-proxy's iptables mode leaves that IP unbound
+		if opts.Config.Networking.KubeProxyMode != config.IPTablesMode {
+			errs = append(errs, errors.Errorf("node-local DNS requires kubeProxyMode %s, got %s", config.IPTablesMode, opts.Config.Networking.KubeProxyMode))
+		}
+		// other CNIs may replace kube-proxy or block the link-local address
+		if opts.Config.Networking.DisableDefaultCNI {
+			errs = append(errs, errors.New("node-local DNS is only supported with the default CNI"))
+		}
+	}
+	if opts.ContainerdVersion != "" {
+		if _, err := version.ParseSemantic(opts.ContainerdVersion); err != nil || !strings.HasPrefix(opts.ContainerdVersion, "v") {
+			errs = append(errs, errors.Errorf("invalid containerd version %q: must be a release version, E.G. v1.6.8", opts.ContainerdVersion))
+		}
+		if opts.StopBeforeSettingUpKubernetes {
+			errs = append(errs, errors.New("a containerd version cannot be used without setting up Kubernetes"))
+		}
+		// the release is installed to /usr/local, which is read-only
+		if opts.SecurityProfile == common.SecurityProfileHardened {
+			errs = append(errs, errors.Errorf("a containerd version cannot be used with the %q security profile", common.SecurityProfileHardened))
+		}
+	}
+	if opts.ExpectKubernetesVersion != "" {
+		if err := checkversion.Validate(opts.ExpectKubernetesVersion); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := validateSkipPhases(opts.Config); err != nil {
+		errs = append(errs, err)
+	}
+	if opts.VerifyAPIServerHA && controlPlaneCount(opts.Config) < 2 {
+		errs = append(errs, errors.New("verifying the API server is highly available requires multiple control plane nodes"))
+	}
+	for name, verbosity := range opts.ActionVerbosity {
+		if _, known := builtinActions[name]; !known {
+			errs = append(errs, errors.Errorf("invalid action verbosity: unknown action %q", name))
+		}
+		if verbosity < 0 {
+			errs = append(errs, errors.Errorf("invalid verbosity %d for action %q: must not be negative", verbosity, name))
+		}
+	}
+	if err := waitforworkloads.Validate(opts.WaitForWorkloads); err != nil {
+		errs = append(errs, err)
+	}
+	if err := seedobjects.Validate(opts.SeedObjects); err != nil {
+		errs = append(errs, err)
+	}
+	if opts.ReadyCondition != "" && !validReadyConditionRE.MatchString(opts.ReadyCondition) {
+		errs = append(errs, errors.Errorf("invalid ready condition %q: must match `%s`", opts.ReadyCondition, validReadyConditionRE.String()))
+	}
+	if opts.ReadyLabel != "" {
+		if err := validateReadyLabel(opts.ReadyLabel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.APIServerAdvertiseAddress != "" {
+		ip := net.ParseIP(opts.APIServerAdvertiseAddress)
+		if ip == nil {
+			errs = append(errs, errors.Errorf("invalid API server advertise address: %q", opts.APIServerAdvertiseAddress))
+		} else if (ip.To4() == nil) != (opts.Config.Networking.IPFamily == config.IPv6Family) {
+			errs = append(errs, errors.Errorf("API server advertise address %s does not match the cluster IP family %s", ip, opts.Config.Networking.IPFamily))
+		}
+	}
+	if opts.KubeconfigCAPath != "" && opts.KubeconfigInsecureSkipTLSVerify {
+		errs = append(errs, errors.New("a kubeconfig CA path cannot be used with insecure-skip-tls-verify, which does not verify against any CA"))
+	}
+	if opts.KubeconfigStandalone && opts.KubeconfigPath == "" {
+		errs = append(errs, errors.New("a standalone kubeconfig requires an explicit kubeconfig path"))
+	}
+	if opts.KubeconfigEncryptionKey != "" {
+		if opts.KubeconfigPath == "" {
+			errs = append(errs, errors.New("an encrypted kubeconfig requires an explicit kubeconfig path"))
+		}
+		if _, err := kubeconfig.ParseEncryptionKey(opts.KubeconfigEncryptionKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.ExternalLoadBalancerEndpoint != "" {
+		if err := validateEndpoint("external load balancer endpoint", opts.ExternalLoadBalancerEndpoint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.HostAddressOverride != "" && !validHostRE.MatchString(opts.HostAddressOverride) && net.ParseIP(opts.HostAddressOverride) == nil {
+		errs = append(errs, errors.Errorf("invalid host address override %q: must be an IP address or hostname", opts.HostAddressOverride))
+	}
+	if opts.NodeNameTemplate != "" {
+		if err := validateNodeNames(opts.Config, opts.NodeNameTemplate, opts.ExternalLoadBalancerEndpoint != ""); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.ControlPlaneEndpoint != "" {
+		if err := validateEndpoint("control plane endpoint", opts.ControlPlaneEndpoint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, d := range []time.Duration{opts.ControlPlaneLivenessInitialDelay, opts.ControlPlaneLivenessTimeout} {
+		if d%time.Second != 0 {
+			errs = append(errs, errors.Errorf("invalid control plane liveness probe duration %s: must be whole seconds", d))
+		}
+	}
+	if err := livenessProbeTuning(opts).Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if shutdown := gracefulShutdown(opts); !shutdown.IsZero() {
+		if err := shutdown.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if enabled, ok := opts.Config.FeatureGates[kubeadm.GracefulNodeShutdownFeatureGate]; ok && !enabled {
+			errs = append(errs, errors.Errorf("invalid shutdown grace period: the %s feature gate is disabled", kubeadm.GracefulNodeShutdownFeatureGate))
+		}
+	}
+	if err := apiServerTuning(opts).Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if opts.DNSDomain != "" {
+		if err := kubeadm.ValidateDNSDomain(opts.DNSDomain); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.BootstrapToken != "" {
+		if err := kubeadm.ValidateToken(opts.BootstrapToken); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.BootstrapTokenTTL < 0 {
+		errs = append(errs, errors.Errorf("invalid bootstrap token TTL %s: must not be negative", opts.BootstrapTokenTTL))
+	} else if opts.BootstrapTokenTTL%time.Second != 0 {
+		errs = append(errs, errors.Errorf("invalid bootstrap token TTL %s: must be a whole number of seconds", opts.BootstrapTokenTTL))
+	}
+	if opts.PodSecurityConfigPath != "" {
+		if info, err := os.Stat(opts.PodSecurityConfigPath); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid pod security admission config"))
+		} else if info.IsDir() {
+			errs = append(errs, errors.Errorf("invalid pod security admission config %q: must be a file", opts.PodSecurityConfigPath))
+		}
+	}
+	if !opts.OIDC.IsZero() {
+		if err := opts.OIDC.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if opts.OIDC.CAPath != "" {
+			if info, err := os.Stat(opts.OIDC.CAPath); err != nil {
+				errs = append(errs, errors.Wrap(err, "invalid OIDC issuer CA"))
+			} else if info.IsDir() {
+				errs = append(errs, errors.Errorf("invalid OIDC issuer CA %q: must be a file", opts.OIDC.CAPath))
+			}
+		}
+	}
+	if opts.Konnectivity {
+		if n := controlPlaneCount(opts.Config); n != 1 {
+			errs = append(errs, errors.Errorf(
+				"invalid konnectivity config: got %d control plane nodes, konnectivity requires a single control plane node (the agents only connect to its konnectivity server) running Kubernetes v1.20.0 or newer",
+				n,
+			))
+		}
+	}
+	if opts.CloudProvider != "" {
+		if err := kubeadm.ValidateCloudProvider(opts.CloudProvider); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := common.ValidateKubeletRootDir(opts.KubeletRootDir); err != nil {
+		errs = append(errs, err)
+	}
+	if err := common.ValidateTimezone(opts.Timezone); err != nil {
+		errs = append(errs, err)
+	}
+	if err := common.ValidateSecurityProfile(opts.SecurityProfile); err != nil {
+		errs = append(errs, err)
+	}
+	if opts.RestartPolicy != "" {
+		if err := common.ValidateRestartPolicy(opts.RestartPolicy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if opts.LocalRegistry {
+		if err := validateLocalRegistryPort(opts.LocalRegistryPort); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, path := range opts.NodeCACerts {
+		if err := installcacerts.ValidateCertFile(path); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid node CA certificate"))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// diskSpacePerNode is a rough estimate of the disk space each node needs,
+// including its share of the node image and the images pulled into it
+const diskSpacePerNode = 2 << 30 // 2 GiB
+
+// checkDiskSpace warns if the provider may not have enough free disk space
+// for the cluster, or fails if opts.FailOnLowDisk is set
+func checkDiskSpace(logger log.Logger, p providers.Provider, opts *ClusterOptions) error {
+	free, err := p.DataRootFreeSpace()
+	if err != nil {
+		// E.G. the data root may be on a remote host or in a VM
+		logger.V(1).Infof("Skipping free disk space check: %v", err)
+		return nil
+	}
+	required := uint64(len(opts.Config.Nodes)) * diskSpacePerNode
+	if free >= required {
+		return nil
+	}
+	msg := fmt.Sprintf(
+		"only %s of disk space is free for the container runtime, an estimated %s is required for %d node(s)",
+		formatBytes(free), formatBytes(required), len(opts.Config.Nodes),
+	)
+	if opts.FailOnLowDisk {
+		return errors.New(msg)
+	}
+	logger.Warnf("WARNING: %s", msg)
+	return nil
+}
+
+// rough estimates of the CPU (in millicores) and memory each node needs,
+// control plane nodes also run etcd and the control plane components
+const (
+	controlPlaneMilliCPU = 1000
+	controlPlaneMemory   = 1 << 30 // 1 GiB
+	nodeMilliCPU         = 500
+	nodeMemory           = 512 << 20 // 512 MiB
+)
+
+// estimateResources returns the estimated CPU in millicores and memory in
+// bytes needed by the nodes in cfg
+func estimateResources(cfg *config.Cluster) (int, uint64) {
+	milliCPU, memory := 0, uint64(0)
+	for _, n := range cfg.Nodes {
+		if n.Role == config.ControlPlaneRole {
+			milliCPU += controlPlaneMilliCPU
+			memory += controlPlaneMemory
+		} else {
+			milliCPU += nodeMilliCPU
+			memory += nodeMemory
+		}
+	}
+	return milliCPU, memory
+}
+
+// checkCapacity warns if the provider's host may not have enough CPUs or
+// memory for the cluster, or fails if opts.FailOnInsufficientResources is set
+func checkCapacity(logger log.Logger, p providers.Provider, opts *ClusterOptions) error {
+	cpus, memory, err := p.Capacity()
+	if err != nil {
+		logger.V(1).Infof("Skipping host capacity check: %v", err)
+		return nil
+	}
+	requiredMilliCPU, requiredMemory := estimateResources(opts.Config)
+	if cpus*1000 >= requiredMilliCPU && memory >= requiredMemory {
+		return nil
+	}
+	msg := fmt.Sprintf(
+		"the host has %d CPU(s) and %s of memory, an estimated %.1f CPU(s) and %s are required for %d node(s)",
+		cpus, formatBytes(memory), float64(requiredMilliCPU)/1000, formatBytes(requiredMemory), len(opts.Config.Nodes),
+	)
+	if opts.FailOnInsufficientResources {
+		return errors.New(msg)
+	}
+	logger.Warnf("WARNING: %s", msg)
+	return nil
+}
+
+// the valid range of ClusterOptions.NetworkMTU, IPv6 requires at least 1280
+const (
+	minNetworkMTU = 1280
+	maxNetworkMTU = 65535
+)
+
+// defaultNetworkMTU is the usual MTU of the node network when it is not set,
+// hosts with a lower MTU, E.G. behind a VPN or nested in another container
+// network, may drop the larger packets between the nodes
+const defaultNetworkMTU = 1500
+
+// checkHostMTU warns if the host's outbound interface has an MTU below
+// defaultNetworkMTU and networkMTU is not set to fit within it
+func checkHostMTU(logger log.Logger, networkMTU int) {
+	name, mtu, err := hostMTU()
+	if err != nil {
+		logger.V(1).Infof("Skipping host MTU check: %v", err)
+		return
+	}
+	if mtu >= defaultNetworkMTU || (networkMTU > 0 && networkMTU <= mtu) {
+		return
+	}
+	logger.Warnf(
+		"WARNING: the host interface %s has MTU %d, below the usual node network MTU of %d, pod networking may fail for large packets unless the network MTU is set to at most %d",
+		name, mtu, defaultNetworkMTU, mtu,
+	)
+}
+
+// hostMTU returns the name and MTU of the host's outbound interface
+func hostMTU() (string, int, error) {
+	// connecting a UDP socket only picks the route, nothing is sent
+	conn, err := net.Dial("udp", "192.0.2.1:9")
+	if err != nil {
+		return "", 0, err
+	}
+	defer conn.Close()
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", 0, err
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(local) {
+				return iface.Name, iface.MTU, nil
+			}
+		}
+	}
+	return "", 0, errors.Errorf("no interface has the outbound address %s", local)
+}
+
+// reservePorts fills in the unset published host ports in opts.Config with
+// free ports reserved by common.ReserveFreePort, returning a func to release
+// the reservations once the nodes have been created
+func reservePorts(logger log.Logger, opts *ClusterOptions) (func(), error) {
+	releases := []func(){}
+	release := func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	reserve := func(port *int32, listenAddr, description string) error {
+		if *port != 0 {
+			return nil
+		}
+		p, r, err := common.ReserveFreePort(listenAddr)
+		if err != nil {
+			return errors.Wrapf(err, "failed to pick a free port for %s", description)
+		}
+		releases = append(releases, r)
+		*port = p
+		logger.V(1).Infof("Picked host port %d for %s", p, description)
+		return nil
+	}
+	cfg := opts.Config
+	if err := reserve(&cfg.Networking.APIServerPort, cfg.Networking.APIServerAddress, "the API server"); err != nil {
+		release()
+		return nil, err
+	}
+	for i := range cfg.Nodes {
+		for j := range cfg.Nodes[i].ExtraPortMappings {
+			pm := &cfg.Nodes[i].ExtraPortMappings[j]
+			description := fmt.Sprintf("node %d container port %d", i, pm.ContainerPort)
+			if err := reserve(&pm.HostPort, pm.ListenAddress, description); err != nil {
+				release()
+				return nil, err
+			}
+		}
+	}
+	if opts.OnAutoPorts != nil {
+		opts.OnAutoPorts(cfg)
+	}
+	return release, nil
+}
+
+// formatBytes formats b in GiB
+func formatBytes(b uint64) string {
+	return fmt.Sprintf("%.1fGiB", float64(b)/(1<<30))
+}
+
+// maxNodeCIDRMaskDiff is how many bits longer the node CIDR mask size may be
+// than the pod subnet prefix length, the kube-controller-manager rejects more
+const maxNodeCIDRMaskDiff = 16
+
+// nodeCIDRMaskSize returns the node CIDR mask size option for the cluster's
+// IP family, or zero for the kube-controller-manager default
+func nodeCIDRMaskSize(opts *ClusterOptions) int32 {
+	if opts.Config.Networking.IPFamily == config.IPv6Family {
+		return opts.NodeCIDRMaskSizeIPv6
+	}
+	return opts.NodeCIDRMaskSizeIPv4
+}
+
+// validateNodeCIDRMaskSize returns an error if the node CIDR mask size
+// options are not consistent with the cluster's IP family and pod subnet
+func validateNodeCIDRMaskSize(opts *ClusterOptions) error {
+	ipv6 := opts.Config.Networking.IPFamily == config.IPv6Family
+	if opts.NodeCIDRMaskSizeIPv4 != 0 && ipv6 {
+		return errors.Errorf("invalid IPv4 node CIDR mask size %d: the cluster IP family is %s", opts.NodeCIDRMaskSizeIPv4, config.IPv6Family)
+	}
+	if opts.NodeCIDRMaskSizeIPv6 != 0 && !ipv6 {
+		return errors.Errorf("invalid IPv6 node CIDR mask size %d: the cluster IP family is %s", opts.NodeCIDRMaskSizeIPv6, opts.Config.Networking.IPFamily)
+	}
+	size := nodeCIDRMaskSize(opts)
+	if size == 0 {
+		return nil
+	}
+	bits := int32(32)
+	if ipv6 {
+		bits = 128
+	}
+	if size < 0 || size > bits {
+		return errors.Errorf("invalid node CIDR mask size %d: must be between 1 and %d", size, bits)
+	}
+	_, subnet, err := net.ParseCIDR(opts.Config.Networking.PodSubnet)
+	if err != nil {
+		// the config validation reports the pod subnet
+		return nil
+	}
+	prefix, _ := subnet.Mask.Size()
+	if int(size) < prefix {
+		return errors.Errorf("invalid node CIDR mask size %d: must be at least the pod subnet %s prefix length", size, opts.Config.Networking.PodSubnet)
+	}
+	if int(size)-prefix > maxNodeCIDRMaskDiff {
+		return errors.Errorf("invalid node CIDR mask size %d: must be at most %d bits longer than the pod subnet %s prefix length", size, maxNodeCIDRMaskDiff, opts.Config.Networking.PodSubnet)
+	}
+	return nil
+}
+
+// nodeCIDRCapacity returns how many node CIDRs fit in the pod subnet with
+// the node CIDR mask size option, or zero if it is not set
+func nodeCIDRCapacity(opts *ClusterOptions) int {
+	size := nodeCIDRMaskSize(opts)
+	if size == 0 {
+		return 0
+	}
+	_, subnet, err := net.ParseCIDR(opts.Config.Networking.PodSubnet)
+	if err != nil {
+		return 0
+	}
+	prefix, _ := subnet.Mask.Size()
+	if int(size) < prefix || int(size)-prefix > maxNodeCIDRMaskDiff {
+		return 0
+	}
+	return 1 << uint(int(size)-prefix)
+}
+
+// kubernetesNodeCount returns the number of nodes in cfg joined to the cluster
+func kubernetesNodeCount(cfg *config.Cluster) int {
+	count := 0
+	for _, n := range cfg.Nodes {
+		if n.Role == config.ControlPlaneRole || n.Role == config.WorkerRole {
+			count++
+		}
+	}
+	return count
 }
 
-// Cluster creates a cluster
-func Cluster(logger log.Logger, p providers.Provider, opts *ClusterOptions) error {
-	// default / process options (namely config)
-	if err := fixupOptions(opts); err != nil {
+// validateNodeNames returns an error if the node names rendered from
+// nodeNameTemplate for cfg are not unique valid names within hostNameMax
+func validateNodeNames(cfg *config.Cluster, nodeNameTemplate string, externalLoadBalancer bool) error {
+	nodeNamer, err := common.MakeTemplateNodeNamer(cfg.Name, nodeNameTemplate)
+	if err != nil {
 		return err
 	}
+	roles := []string{}
+	for _, n := range cfg.Nodes {
+		roles = append(roles, string(n.Role))
+	}
+	// the providers also name the implicit load balancer from the template
+	if controlPlaneCount(cfg) > 1 && !externalLoadBalancer {
+		roles = append(roles, constants.ExternalLoadBalancerNodeRoleValue)
+	}
+	errs := []error{}
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		name, err := nodeNamer(role)
+		if err != nil {
+			return err
+		}
+		switch {
+		case seen[name]:
+			errs = append(errs, errors.Errorf("invalid node name %q rendered from %q: node names must be unique", name, nodeNameTemplate))
+		case len(name) > hostNameMax:
+			errs = append(errs, errors.Errorf("invalid node name %q rendered from %q: must be no more than %d characters", name, nodeNameTemplate, hostNameMax))
+		case !validNameRE.MatchString(name):
+			errs = append(errs, errors.Errorf("invalid node name %q rendered from %q: must match `%s`", name, nodeNameTemplate, validNameRE.String()))
+		}
+		seen[name] = true
+	}
+	return errors.NewAggregate(errs)
+}
 
-	// Check if the cluster name already exists
-	if err := alreadyExists(p, opts.Config.Name); err != nil {
+// validateIgnoreNodesForReady returns an error if ignored are not distinct
+// Kubernetes node names in cfg, or if they include every control plane node
+func validateIgnoreNodesForReady(cfg *config.Cluster, nodeNameTemplate string, ignored []string) error {
+	controlPlanes, workers, err := kubernetesNodeNames(cfg, nodeNameTemplate)
+	if err != nil {
 		return err
 	}
+	known := make(map[string]bool)
+	for _, name := range append(append([]string{}, controlPlanes...), workers...) {
+		known[name] = true
+	}
+	errs := []error{}
+	seen := make(map[string]bool)
+	for _, name := range ignored {
+		switch {
+		case seen[name]:
+			errs = append(errs, errors.Errorf("invalid node to ignore for ready %q: nodes may only be ignored once", name))
+		case !known[name]:
+			errs = append(errs, errors.Errorf("invalid node to ignore for ready %q: not a node in the cluster", name))
+		}
+		seen[name] = true
+	}
+	waited := 0
+	for _, name := range controlPlanes {
+		if !seen[name] {
+			waited++
+		}
+	}
+	if waited == 0 {
+		errs = append(errs, errors.New("cannot ignore every control plane node for ready"))
+	}
+	return errors.NewAggregate(errs)
+}
 
-	// TODO: move to config validation
-	// validate the name
-	if !validNameRE.MatchString(opts.Config.Name) {
-		return errors.Errorf(
-			"'%s' is not a valid cluster name, cluster names must match `%s`",
-			opts.Config.Name, validNameRE.String(),
-		)
+// kubernetesNodeNames returns the Kubernetes node names of the control plane
+// and worker nodes in cfg, which are their hostnames if set or else the node
+// names rendered from nodeNameTemplate
+func kubernetesNodeNames(cfg *config.Cluster, nodeNameTemplate string) (controlPlanes, workers []string, err error) {
+	nodeNamer, err := common.MakeTemplateNodeNamer(cfg.Name, nodeNameTemplate)
+	if err != nil {
+		return nil, nil, err
 	}
-	// warn if cluster name might typically be too long
-	if len(opts.Config.Name) > clusterNameMax {
-		logger.Warnf("cluster name %q is probably too long, this might not work properly on some systems", opts.Config.Name)
+	for _, n := range cfg.Nodes {
+		// every node is named to keep the per role indexes in order
+		name, err := nodeNamer(string(n.Role))
+		if err != nil {
+			return nil, nil, err
+		}
+		if n.Hostname != "" {
+			name = n.Hostname
+		}
+		switch n.Role {
+		case config.ControlPlaneRole:
+			controlPlanes = append(controlPlanes, name)
+		case config.WorkerRole:
+			workers = append(workers, name)
+		}
 	}
+	return controlPlanes, workers, nil
+}
 
-	// then validate
-	if err := opts.Config.Validate(); err != nil {
-		return err
+// validateSkipPhases returns an error if any node's skipPhases are not
+// kubeadm phases kind can do without, the first control plane node is
+// checked against kubeadm init and the other nodes against kubeadm join
+func validateSkipPhases(cfg *config.Cluster) error {
+	errs := []error{}
+	controlPlanes := 0
+	for i, n := range cfg.Nodes {
+		command := "join"
+		if n.Role == config.ControlPlaneRole {
+			if controlPlanes == 0 {
+				command = "init"
+			}
+			controlPlanes++
+		}
+		if len(n.SkipPhases) == 0 {
+			continue
+		}
+		if n.Role == config.KubeletOnlyRole {
+			errs = append(errs, errors.Errorf("invalid configuration for node %d: skipPhases are not supported for %s nodes", i, n.Role))
+			continue
+		}
+		if err := kubeadm.ValidateSkipPhases(command, n.SkipPhases, ""); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid configuration for node %d", i))
+		}
 	}
+	return errors.NewAggregate(errs)
+}
 
-	// setup a status object to show progress to the user
-	status := cli.StatusForLogger(logger)
+// controlPlaneCount returns the number of control plane nodes in cfg
+func controlPlaneCount(cfg *config.Cluster) int {
+	count := 0
+	for _, n := range cfg.Nodes {
+		if n.Role == config.ControlPlaneRole {
+			count++
+		}
+	}
+	return count
+}
 
-	// we're going to start creating now, tell the user
-	logger.V(0).Infof("Creating cluster %q ...\n", opts.Config.Name)
+// validReadyConditionRE matches node condition types, which are
+// CamelCase names that may be prefixed with a domain by convention
+var validReadyConditionRE = regexp.MustCompile(`^([a-z0-9.-]+/)?[A-Za-z][A-Za-z0-9]*$`)
 
-	// Create node containers implementing defined config Nodes
-	if err := p.Provision(status, opts.Config); err != nil {
-		// In case of errors nodes are deleted (except if retain is explicitly set)
-		if !opts.Retain {
-			_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+// validLabelKeyRE and validLabelValueRE match Kubernetes label keys and values
+var (
+	validLabelKeyRE   = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	validLabelValueRE = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// validateReadyLabel ensures label is of the form key or key=value
+func validateReadyLabel(label string) error {
+	parts := strings.SplitN(label, "=", 2)
+	if !validLabelKeyRE.MatchString(parts[0]) {
+		return errors.Errorf("invalid ready label %q: %q is not a valid label key", label, parts[0])
+	}
+	if len(parts) == 2 && !validLabelValueRE.MatchString(parts[1]) {
+		return errors.Errorf("invalid ready label %q: %q is not a valid label value", label, parts[1])
+	}
+	return nil
+}
+
+// validateSystemPodsExclude ensures names are of the form name or kind/name,
+// where kind is deployment or daemonset, and labels of the form key or
+// key=value
+func validateSystemPodsExclude(names, labels []string) error {
+	for _, name := range names {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) == 2 && parts[0] != "deployment" && parts[0] != "daemonset" {
+			return errors.Errorf("invalid system pods exclusion %q: kind %q must be deployment or daemonset", name, parts[0])
 		}
-		return err
+		if !validObjectNameRE.MatchString(parts[len(parts)-1]) {
+			return errors.Errorf("invalid system pods exclusion %q: %q is not a valid object name", name, parts[len(parts)-1])
+		}
+	}
+	for _, label := range labels {
+		parts := strings.SplitN(label, "=", 2)
+		if !validLabelKeyRE.MatchString(parts[0]) {
+			return errors.Errorf("invalid system pods exclusion label %q: %q is not a valid label key", label, parts[0])
+		}
+		if len(parts) == 2 && !validLabelValueRE.MatchString(parts[1]) {
+			return errors.Errorf("invalid system pods exclusion label %q: %q is not a valid label value", label, parts[1])
+		}
+	}
+	return nil
+}
+
+// validNamespaceRE and validObjectNameRE match Kubernetes namespaces, which
+// are DNS-1123 labels, and object names, which are DNS-1123 subdomains
+var (
+	validNamespaceRE  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	validObjectNameRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+)
+
+// validImageRE matches image references: an optional registry host (with an
+// optional port), a lowercase repository path, an optional tag, and an
+// optional sha256 digest
+var validImageRE = regexp.MustCompile(
+	`^([A-Za-z0-9.-]+(:[0-9]+)?/)?[a-z0-9]+([._-][a-z0-9]+)*(/[a-z0-9]+([._-][a-z0-9]+)*)*` +
+		`(:[A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?(@sha256:[a-f0-9]{64})?$`,
+)
+
+// validNodePlatformRE matches os/arch[/variant] image platforms
+var validNodePlatformRE = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// validNetworkIDRE matches full and short (12 character) docker network IDs
+var validNetworkIDRE = regexp.MustCompile(`^[a-f0-9]{12,64}$`)
+
+// validEnvKeyRE matches portable environment variable names
+var validEnvKeyRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validNetworkNameRE matches docker network names
+var validNetworkNameRE = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// validHostRE matches DNS hostnames
+var validHostRE = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([-A-Za-z0-9]{0,61}[A-Za-z0-9])?)*$`)
+
+// validateNamespacedName ensures namespacedName is of the form namespace/name
+func validateNamespacedName(namespacedName string) error {
+	parts := strings.SplitN(namespacedName, "/", 2)
+	if len(parts) != 2 || !validNamespaceRE.MatchString(parts[0]) || !validObjectNameRE.MatchString(parts[1]) {
+		return errors.Errorf("%q is not of the form namespace/name", namespacedName)
 	}
+	return nil
+}
 
-	// TODO(bentheelder): make this controllable from the command line?
-	actionsToRun := []actions.Action{
-		loadbalancer.NewAction(), // setup external loadbalancer
-		configaction.NewAction(), // setup kubeadm config
+// validateEndpoint ensures endpoint is of the form host:port, what describes
+// the endpoint in errors
+func validateEndpoint(what, endpoint string) error {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s %q", what, endpoint)
 	}
-	if !opts.StopBeforeSettingUpKubernetes {
-		actionsToRun = append(actionsToRun,
-			kubeadminit.NewAction(), // run kubeadm init
-		)
-		// this step might be skipped, but is next after init
-		if !opts.Config.Networking.DisableDefaultCNI {
-			actionsToRun = append(actionsToRun,
-				installcni.NewAction(), // install CNI
-			)
-		}
-		// add remaining steps
-		actionsToRun = append(actionsToRun,
-			installstorage.NewAction(),                // install StorageClass
-			kubeadmjoin.NewAction(),                   // run kubeadm join
-			waitforready.NewAction(opts.WaitForReady), // wait for cluster readiness
-		)
+	if host == "" {
+		return errors.Errorf("invalid %s %q: host must not be empty", what, endpoint)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return errors.Errorf("invalid %s %q: port must be within 1-65535", what, endpoint)
+	}
+	return nil
+}
+
+// defaultLocalRegistryPort is the conventional local registry host port
+const defaultLocalRegistryPort = 5000
+
+// validateLocalRegistryPort returns an error if port cannot be used to
+// publish the local registry on the host
+func validateLocalRegistryPort(port int32) error {
+	if port < 1 || port > 65535 {
+		return errors.Errorf("invalid local registry port: %d", port)
 	}
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", fmt.Sprint(port)))
+	if err != nil {
+		return errors.Wrapf(err, "local registry port %d is not available", port)
+	}
+	return l.Close()
+}
 
-	// run all actions
-	actionsContext := actions.NewActionContext(logger, status, p, opts.Config)
-	for _, action := range actionsToRun {
-		if err := action.Execute(actionsContext); err != nil {
-			if !opts.Retain {
-				_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+// validateSecondaryNetworks returns an error for each of
+// ClusterOptions.SecondaryNetworks that is invalid, duplicated, or whose
+// subnet overlaps the cluster's or another secondary network's subnet
+func validateSecondaryNetworks(opts *ClusterOptions) []error {
+	var errs []error
+	var clusterSubnets []*net.IPNet
+	for _, s := range strings.Split(opts.Config.Networking.PodSubnet+","+opts.Config.Networking.ServiceSubnet, ",") {
+		if _, subnet, err := net.ParseCIDR(strings.TrimSpace(s)); err == nil {
+			clusterSubnets = append(clusterSubnets, subnet)
+		}
+	}
+	names := map[string]bool{}
+	subnets := map[string]*net.IPNet{}
+	for _, n := range opts.SecondaryNetworks {
+		if !validNetworkNameRE.MatchString(n.Name) {
+			errs = append(errs, errors.Errorf("invalid secondary network name %q", n.Name))
+		} else if n.Name == "kind" || n.Name == opts.ExistingNetworkID {
+			errs = append(errs, errors.Errorf("invalid secondary network %q: it is the primary node network", n.Name))
+		} else if names[n.Name] {
+			errs = append(errs, errors.Errorf("invalid secondary network %q: duplicated", n.Name))
+		}
+		names[n.Name] = true
+		if n.Subnet == "" {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(n.Subnet)
+		if err != nil {
+			errs = append(errs, errors.Errorf("invalid secondary network %q subnet %q: must be a CIDR", n.Name, n.Subnet))
+			continue
+		}
+		for _, c := range clusterSubnets {
+			if subnet.Contains(c.IP) || c.Contains(subnet.IP) {
+				errs = append(errs, errors.Errorf("invalid secondary network %q subnet %s: overlaps the cluster subnet %s", n.Name, subnet, c))
+			}
+		}
+		for other, s := range subnets {
+			if subnet.Contains(s.IP) || s.Contains(subnet.IP) {
+				errs = append(errs, errors.Errorf("invalid secondary network %q subnet %s: overlaps the secondary network %q subnet %s", n.Name, subnet, other, s))
 			}
-			return err
 		}
+		subnets[n.Name] = subnet
 	}
+	return errs
+}
 
-	// skip the rest if we're not setting up kubernetes
-	if opts.StopBeforeSettingUpKubernetes {
-		return nil
+// logSecondaryNetworks logs the interface in the nodes of each of networks,
+// E.G. to configure Multus with
+func logSecondaryNetworks(logger log.Logger, networks []NetworkSpec) {
+	for i, n := range networks {
+		logger.V(0).Infof("Secondary network %q is attached to the nodes as %s", n.Name, providers.SecondaryNetworkInterface(i))
+	}
+}
+
+// CleanupContext is the cluster that is about to be deleted after failing
+// to create it, see ClusterOptions.OnBeforeCleanup
+type CleanupContext struct {
+	// ClusterName is the name of the cluster
+	ClusterName string
+	// Nodes are the cluster's nodes that were created, if any
+	Nodes []nodes.Node
+}
+
+// cleanupOnFailure deletes the cluster after failing to create it with
+// createErr, unless opts.Retain or opts.StopAfterAction is set
+func cleanupOnFailure(logger log.Logger, p providers.Provider, opts *ClusterOptions, createErr error) {
+	// diagnostics must be collected before the nodes are deleted
+	collectDiagnostics(logger, p, opts)
+	// clusters are always retained when stopping early for debugging
+	if opts.Retain || opts.StopAfterAction != "" {
+		return
+	}
+	// give the user a chance to inspect the failed cluster first if requested
+	if opts.FailureCleanupDelay > 0 {
+		logger.V(0).Infof("Failed to create cluster, deleting it in %s ...", formatDuration(opts.FailureCleanupDelay))
+		time.Sleep(opts.FailureCleanupDelay)
+	}
+	if opts.OnBeforeCleanup != nil {
+		ctx := &CleanupContext{ClusterName: opts.Config.Name}
+		if n, err := p.ListNodes(opts.Config.Name); err == nil {
+			ctx.Nodes = n
+		} else {
+			logger.V(1).Infof("Failed to list nodes before cleanup: %v", err)
+		}
+		if err := opts.OnBeforeCleanup(ctx, createErr); err != nil {
+			logger.Warnf("WARNING: the before cleanup callback failed, deleting the cluster anyway: %v", err)
+		}
 	}
+	_ = delete.Cluster(logger, p, opts.Config.Name, opts.KubeconfigPath)
+}
+
+func formatDuration(duration time.Duration) string {
+	return duration.Round(time.Second).String()
+}
 
+// exportKubeconfig exports the cluster's kubeconfig per opts
+func exportKubeconfig(logger log.Logger, p providers.Provider, opts *ClusterOptions) error {
 	// try exporting kubeconfig with backoff for locking failures
 	// TODO: factor out into a public errors API w/ backoff handling?
 	// for now this is easier than coming up with a good API
+	exportOpts := kubeconfig.ExportOptions{
+		Standalone:            opts.KubeconfigStandalone,
+		CAPath:                opts.KubeconfigCAPath,
+		InsecureSkipTLSVerify: opts.KubeconfigInsecureSkipTLSVerify,
+	}
+	if !opts.IgnoreKubeconfigContextClash {
+		exportOpts.OnContextClash = func(context, reason string) {
+			logger.Warnf("WARNING: Replacing the existing kubeconfig context %q, which points at %s", context, reason)
+		}
+	}
 	var err error
+	if opts.KubeconfigEncryptionKey != "" {
+		if exportOpts.EncryptionKey, err = kubeconfig.ParseEncryptionKey(opts.KubeconfigEncryptionKey); err != nil {
+			return err
+		}
+	}
 	for _, b := range []time.Duration{0, time.Millisecond, time.Millisecond * 50, time.Millisecond * 100} {
 		time.Sleep(b)
-		if err = kubeconfig.Export(p, opts.Config.Name, opts.KubeconfigPath); err == nil {
+		err = kubeconfig.ExportWithOptions(p, opts.Config.Name, opts.KubeconfigPath, exportOpts)
+		if err == nil {
 			break
 		}
 	}
-	if err != nil {
-		return err
-	}
+	return err
+}
 
-	// optionally display usage
-	if opts.DisplayUsage {
-		logUsage(logger, opts.Config.Name, opts.KubeconfigPath)
+// exportCredentials writes the cluster CA and admin client certificate and
+// key to the paths in opts, if any are set
+func exportCredentials(p providers.Provider, opts *ClusterOptions) error {
+	paths := kubeconfig.CredentialPaths{
+		CA:         opts.CACertPath,
+		ClientCert: opts.ClientCertPath,
+		ClientKey:  opts.ClientKeyPath,
 	}
-	// optionally give the user a friendly salutation
-	if opts.DisplaySalutation {
-		logger.V(0).Info("")
-		logSalutation(logger)
+	if paths == (kubeconfig.CredentialPaths{}) {
+		return nil
 	}
-	return nil
+	return kubeconfig.ExportCredentials(p, opts.Config.Name, paths)
+}
+
+// validateCredentialPaths returns an error if the credential paths in opts
+// are not distinct or are not in existing, writable directories
+func validateCredentialPaths(opts *ClusterOptions) error {
+	errs := []error{}
+	seen := make(map[string]bool)
+	for _, path := range []string{opts.CACertPath, opts.ClientCertPath, opts.ClientKeyPath} {
+		if path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid credential path %q", path))
+			continue
+		}
+		if seen[abs] {
+			errs = append(errs, errors.Errorf("invalid credential path %q: must only be used once", path))
+		}
+		seen[abs] = true
+		if err := ensureWritableDir(filepath.Dir(abs)); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid credential path %q", path))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// WorkloadRef identifies a workload to wait for, see
+// ClusterOptions.WaitForWorkloads
+type WorkloadRef = waitforworkloads.WorkloadRef
+
+// OIDCConfig configures API server OIDC authentication, see
+// ClusterOptions.OIDC
+type OIDCConfig = kubeadm.OIDCConfig
+
+// NetworkSpec is a network attached to the nodes in addition to the primary
+// node network, see ClusterOptions.SecondaryNetworks
+type NetworkSpec = providers.NetworkSpec
+
+// SecondaryNetworkInterface returns the name of the network interface in the
+// nodes of the i-th of ClusterOptions.SecondaryNetworks
+func SecondaryNetworkInterface(i int) string {
+	return providers.SecondaryNetworkInterface(i)
+}
+
+// OnExisting is what to do when creating a cluster whose name is in use
+type OnExisting int
+
+const (
+	// OnExistingFail fails with an AlreadyExistsError, this is the default
+	OnExistingFail OnExisting = iota
+	// OnExistingReuse keeps the existing cluster and only exports its kubeconfig
+	OnExistingReuse
+	// OnExistingRecreate deletes the existing cluster and creates it again
+	OnExistingRecreate
+)
+
+// AlreadyExistsError is returned when creating a cluster whose name is
+// already in use with OnExistingFail
+type AlreadyExistsError struct {
+	// Name is the name of the existing cluster
+	Name string
 }
 
-// alreadyExists returns an error if the cluster name already exists
-// or if we had an error checking
-func alreadyExists(p providers.Provider, name string) error {
+func (e *AlreadyExistsError) Error() string {
+	return fmt.Sprintf("node(s) already exist for a cluster with the name %q", e.Name)
+}
+
+// clusterExists returns true if the cluster name already has nodes
+func clusterExists(p providers.Provider, name string) (bool, error) {
 	n, err := p.ListNodes(name)
 	if err != nil {
-		return err
-	}
-	if len(n) != 0 {
-		return errors.Errorf("node(s) already exist for a cluster with the name %q", name)
+		return false, err
 	}
-	return nil
+	return len(n) != 0, nil
 }
 
 func logUsage(logger log.Logger, name, explicitKubeconfigPath string) {
@@ -206,6 +1662,16 @@ func logUsage(logger log.Logger, name, explicitKubeconfigPath string) {
 	logger.V(0).Infof("You can now use your cluster with:\n\n" + sampleCommand)
 }
 
+// isSmartLogger returns true if logger is writing to a smart terminal,
+// like cmd.ColorEnabled
+func isSmartLogger(logger log.Logger) bool {
+	type maybeColorer interface {
+		ColorEnabled() bool
+	}
+	v, ok := logger.(maybeColorer)
+	return ok && v.ColorEnabled()
+}
+
 func logSalutation(logger log.Logger) {
 	salutations := []string{
 		"Have a nice day! 👋",
@@ -218,8 +1684,27 @@ func logSalutation(logger log.Logger) {
 	logger.V(0).Info(s)
 }
 
-func fixupOptions(opts *ClusterOptions) error {
+func fixupOptions(logger log.Logger, opts *ClusterOptions) error {
 	// do post processing for options
+	// merged config files take precedence over any other config
+	if len(opts.ConfigPaths) > 0 && opts.ConfigTemplatePath != "" {
+		return errors.New("config files cannot be merged with a config template")
+	}
+	if len(opts.ConfigPaths) > 0 {
+		cfg, err := encoding.LoadMerged(opts.ConfigPaths...)
+		if err != nil {
+			return err
+		}
+		opts.Config = cfg
+	}
+	if opts.ConfigTemplatePath != "" {
+		cfg, err := encoding.LoadTemplate(opts.ConfigTemplatePath, opts.ConfigVars)
+		if err != nil {
+			return err
+		}
+		opts.Config = cfg
+	}
+
 	// first ensure we at least have a default cluster config
 	if opts.Config == nil {
 		cfg, err := encoding.Load("")
@@ -233,19 +1718,206 @@ func fixupOptions(opts *ClusterOptions) error {
 		opts.Config.Name = opts.NameOverride
 	}
 
-	// if NodeImage was set, override the image on all nodes
-	if opts.NodeImage != "" {
-		// Apply image override to all the Nodes defined in Config
-		// TODO(fabrizio pandini): this should be reconsidered when implementing
-		//     https://github.com/kubernetes-sigs/kind/issues/133
-		for i := range opts.Config.Nodes {
-			opts.Config.Nodes[i].Image = opts.NodeImage
-		}
+	if opts.KubeProxyMode != "" {
+		opts.Config.Networking.KubeProxyMode = config.ProxyMode(opts.KubeProxyMode)
 	}
 
 	// default config fields (important for usage as a library, where the config
 	// may be constructed in memory rather than from disk)
 	config.SetDefaultsCluster(opts.Config)
 
+	// allow the caller to modify the defaulted config, re-defaulting after
+	// in case it added anything (E.G. nodes)
+	if opts.MutateConfig != nil {
+		if err := opts.MutateConfig(opts.Config); err != nil {
+			return errors.Wrap(err, "failed to mutate config")
+		}
+		config.SetDefaultsCluster(opts.Config)
+	}
+
+	// resolve the image for each node
+	fixupNodeImages(logger, opts, os.Getenv)
+	if opts.ImageResolver != nil {
+		for i := range opts.Config.Nodes {
+			node := &opts.Config.Nodes[i]
+			resolved, err := opts.ImageResolver(node.Image)
+			if err != nil {
+				return errors.Wrapf(err, "failed to resolve image %q for node %d", node.Image, i)
+			}
+			logger.V(1).Infof("Resolved node image %q to %q for node %d", node.Image, resolved, i)
+			node.Image = resolved
+		}
+	}
+
+	// point the nodes at the local registry if requested
+	if opts.LocalRegistry {
+		if opts.LocalRegistryPort == 0 {
+			opts.LocalRegistryPort = defaultLocalRegistryPort
+		}
+		opts.Config.ContainerdConfigPatches = append(opts.Config.ContainerdConfigPatches,
+			localregistry.ContainerdConfigPatch(opts.Config.Name, opts.LocalRegistryPort),
+		)
+	}
+
+	// share the containerd content store with the host if requested
+	if opts.SharedImageCache != "" {
+		if err := fixupSharedImageCache(opts); err != nil {
+			return err
+		}
+	}
+
+	// persist the node logs on the host if requested
+	if opts.NodeLogDir != "" {
+		if err := fixupNodeLogDir(opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fixupNodeImages sets the image on each node in opts.Config, in order of
+// precedence from:
+// - opts.NodeImage (--image)
+// - the image set on the node in the config
+// - $KIND_NODE_IMAGE
+// - defaults.Image
+// If opts.RespectPerNodeImages is set, the image set on the node in the config
+// instead takes precedence over opts.NodeImage
+func fixupNodeImages(logger log.Logger, opts *ClusterOptions, getEnv func(string) string) {
+	envImage := getEnv(nodeImageEnv)
+	for i := range opts.Config.Nodes {
+		node := &opts.Config.Nodes[i]
+		var source string
+		switch {
+		case opts.RespectPerNodeImages && !nodeImageUnset(node.Image):
+			source = "the node config"
+		case opts.NodeImage != "":
+			// TODO(fabrizio pandini): this should be reconsidered when implementing
+			//     https://github.com/kubernetes-sigs/kind/issues/133
+			node.Image = opts.NodeImage
+			source = "the node image option"
+		case !nodeImageUnset(node.Image):
+			source = "the node config"
+		case envImage != "":
+			node.Image = envImage
+			source = "$" + nodeImageEnv
+		default:
+			node.Image = defaults.Image
+			source = "the default"
+		}
+		logger.V(1).Infof("Using node image %q from %s for node %d", node.Image, source, i)
+	}
+}
+
+// fixupSharedImageCache resolves opts.SharedImageCache and configures the
+// nodes' containerd to pull docker.io images through the registry cache the
+// provider runs for it
+//
+// NOTE: the nodes' own content stores are left alone, each containerd only
+// ever garbage collects its own content. The cache directory is only written
+// by the single registry cache serving every node of every cluster using it,
+// which serializes concurrent pulls of the same content.
+func fixupSharedImageCache(opts *ClusterOptions) error {
+	cacheDir, err := filepath.Abs(opts.SharedImageCache)
+	if err != nil {
+		return errors.Wrapf(err, "unable to resolve absolute path for shared image cache: %q", opts.SharedImageCache)
+	}
+	if err := ensureWritableDir(cacheDir); err != nil {
+		return errors.Wrap(err, "invalid shared image cache")
+	}
+	opts.SharedImageCache = cacheDir
+	opts.Config.ContainerdConfigPatches = append(opts.Config.ContainerdConfigPatches, common.RegistryCacheContainerdConfigPatch(cacheDir))
+	return nil
+}
+
+// nodeLogPath is the path to the logs in nodes, see ClusterOptions.NodeLogDir
+const nodeLogPath = "/var/log"
+
+// clusterLogDir returns the host directory for the cluster's node logs,
+// opts.NodeLogDir must be set
+func clusterLogDir(opts *ClusterOptions) (string, error) {
+	dir, err := filepath.Abs(opts.NodeLogDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to resolve absolute path for node log dir: %q", opts.NodeLogDir)
+	}
+	return filepath.Join(dir, opts.Config.Name), nil
+}
+
+// fixupNodeLogDir mounts a directory per node under the cluster's log
+// directory into every node at nodeLogPath, the directories are created by
+// prepareNodeLogDir
+func fixupNodeLogDir(opts *ClusterOptions) error {
+	clusterDir, err := clusterLogDir(opts)
+	if err != nil {
+		return err
+	}
+	// these are named by the providers in the same order
+	nodeNamer, err := common.MakeTemplateNodeNamer(opts.Config.Name, opts.NodeNameTemplate)
+	if err != nil {
+		return err
+	}
+	for i := range opts.Config.Nodes {
+		node := &opts.Config.Nodes[i]
+		name, err := nodeNamer(string(node.Role))
+		if err != nil {
+			return err
+		}
+		node.ExtraMounts = append(node.ExtraMounts, config.Mount{
+			HostPath:      filepath.Join(clusterDir, name),
+			ContainerPath: nodeLogPath,
+		})
+	}
 	return nil
 }
+
+// prepareNodeLogDir creates the cluster's node log directories if
+// opts.NodeLogDir is set, failing if the cluster's directory is already in
+// use so that the logs of clusters with the same name are never mixed
+func prepareNodeLogDir(opts *ClusterOptions) error {
+	if opts.NodeLogDir == "" {
+		return nil
+	}
+	clusterDir, err := clusterLogDir(opts)
+	if err != nil {
+		return err
+	}
+	if entries, err := ioutil.ReadDir(clusterDir); err == nil && len(entries) > 0 {
+		return errors.Errorf("invalid node log dir: %q is already in use, remove it or use another node log dir", clusterDir)
+	}
+	for _, node := range opts.Config.Nodes {
+		for _, m := range node.ExtraMounts {
+			if m.ContainerPath != nodeLogPath || filepath.Dir(m.HostPath) != clusterDir {
+				continue
+			}
+			if err := os.MkdirAll(m.HostPath, 0755); err != nil {
+				return errors.Wrap(err, "failed to create node log dir")
+			}
+		}
+	}
+	return ensureWritableDir(clusterDir)
+}
+
+// ensureWritableDir returns an error if dir is not an existing, writable directory
+func ensureWritableDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.Errorf("%q is not a directory", dir)
+	}
+	f, err := ioutil.TempFile(dir, ".kind-write-check")
+	if err != nil {
+		return errors.Wrapf(err, "%q is not writable", dir)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// nodeImageUnset returns true if image was not explicitly set in the config
+// NOTE: configs are defaulted when loaded, so a node image matching the
+// built-in default is indistinguishable from an unset node image
+func nodeImageUnset(image string) bool {
+	return image == "" || image == defaults.Image
+}
@@ -0,0 +1,1723 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/apis/config/defaults"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+	"sigs.k8s.io/kind/pkg/internal/assert"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+func TestFixupNodeImages(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name                 string
+		NodeImage            string
+		RespectPerNodeImages bool
+		NodeImages           []string
+		EnvImage             string
+		Expected             []string
+	}{
+		{
+			Name:       "built-in default",
+			NodeImages: []string{"", defaults.Image},
+			Expected:   []string{defaults.Image, defaults.Image},
+		},
+		{
+			Name:       "env overrides the default",
+			NodeImages: []string{"", defaults.Image},
+			EnvImage:   "env:image",
+			Expected:   []string{"env:image", "env:image"},
+		},
+		{
+			Name:       "node config overrides env",
+			NodeImages: []string{"node:image", ""},
+			EnvImage:   "env:image",
+			Expected:   []string{"node:image", "env:image"},
+		},
+		{
+			Name:       "option overrides everything",
+			NodeImage:  "option:image",
+			NodeImages: []string{"node:image", ""},
+			EnvImage:   "env:image",
+			Expected:   []string{"option:image", "option:image"},
+		},
+		{
+			Name:                 "option fills unset images when respecting node config",
+			NodeImage:            "option:image",
+			RespectPerNodeImages: true,
+			NodeImages:           []string{"node:image", "", defaults.Image},
+			EnvImage:             "env:image",
+			Expected:             []string{"node:image", "option:image", "option:image"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			opts := &ClusterOptions{
+				Config:               &config.Cluster{},
+				NodeImage:            tc.NodeImage,
+				RespectPerNodeImages: tc.RespectPerNodeImages,
+			}
+			for _, image := range tc.NodeImages {
+				opts.Config.Nodes = append(opts.Config.Nodes, config.Node{Image: image})
+			}
+			fixupNodeImages(log.NoopLogger{}, opts, func(name string) string {
+				if name == nodeImageEnv {
+					return tc.EnvImage
+				}
+				return ""
+			})
+			result := []string{}
+			for _, node := range opts.Config.Nodes {
+				result = append(result, node.Image)
+			}
+			assert.DeepEqual(t, tc.Expected, result)
+		})
+	}
+}
+
+func TestPlanActions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		Actions           []string
+		DisableDefaultCNI bool
+		WaitForReady      time.Duration
+		LocalRegistry     bool
+		StopAfterAction   string
+		ExternalLB        string
+		JoinCommand       bool
+		ExpectVersion     string
+		VerifyHA          bool
+		ContainerdVersion string
+		NodeCACerts       []string
+		Untaint           bool
+		Schedulable       bool
+		SeedObjects       []interface{}
+		Workloads         []WorkloadRef
+		NodeLocalDNS      bool
+		CoreDNSUpstreams  []string
+		NodeLabels        map[string]string
+		Konnectivity      bool
+		Expected          []string
+		ExpectError       bool
+	}{
+		{
+			Name:         "default actions",
+			WaitForReady: time.Minute,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady,
+			},
+		},
+		{
+			Name: "default actions without waiting",
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin,
+			},
+		},
+		{
+			Name:       "default actions with kubelet node labels",
+			NodeLabels: map[string]string{"tier": "frontend"},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin,
+			},
+		},
+		{
+			Name:       "default actions with API node labels",
+			NodeLabels: map[string]string{"node-role.kubernetes.io/ingress": ""},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionLabelNodes,
+			},
+		},
+		{
+			Name:              "default actions without CNI",
+			DisableDefaultCNI: true,
+			WaitForReady:      time.Minute,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer,
+				actionStorage, actionKubeadmJoin, actionWaitForReady,
+			},
+		},
+		{
+			Name: "storage before CNI",
+			Actions: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit,
+				actionStorage, actionInstallCNI, actionKubeadmJoin,
+			},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit,
+				actionStorage, actionInstallCNI, actionKubeadmJoin,
+			},
+		},
+		{
+			Name:            "stop after init",
+			StopAfterAction: actionKubeadmInit,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit,
+			},
+		},
+		{
+			Name:            "stop after an action that won't run",
+			StopAfterAction: actionWaitForReady,
+			ExpectError:     true,
+		},
+		{
+			Name:        "unknown action",
+			Actions:     []string{actionLoadBalancer, "bogus"},
+			ExpectError: true,
+		},
+		{
+			Name:        "duplicate action",
+			Actions:     []string{actionLoadBalancer, actionLoadBalancer},
+			ExpectError: true,
+		},
+		{
+			Name:        "missing requirement",
+			Actions:     []string{actionConfig, actionKubeadmInit},
+			ExpectError: true,
+		},
+		{
+			Name:          "default actions with local registry",
+			LocalRegistry: true,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionRegistry,
+			},
+		},
+		{
+			Name:         "default actions with join command",
+			WaitForReady: time.Minute,
+			JoinCommand:  true,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionJoinCommand, actionWaitForReady,
+			},
+		},
+		{
+			Name:         "default actions with seed objects",
+			WaitForReady: time.Minute,
+			SeedObjects:  []interface{}{map[string]interface{}{}},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionSeedObjects,
+			},
+		},
+		{
+			Name:         "default actions waiting for workloads",
+			WaitForReady: time.Minute,
+			SeedObjects:  []interface{}{map[string]interface{}{}},
+			Workloads:    []WorkloadRef{{Kind: "Deployment", Name: "webhook"}},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionSeedObjects, actionWaitForWorkloads,
+			},
+		},
+		{
+			Name:         "default actions untainting the control plane",
+			WaitForReady: time.Minute,
+			Untaint:      true,
+			SeedObjects:  []interface{}{map[string]interface{}{}},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionUntaint, actionSeedObjects,
+			},
+		},
+		{
+			Name:          "default actions with node-local DNS",
+			WaitForReady:  time.Minute,
+			NodeLocalDNS:  true,
+			ExpectVersion: "v1.19",
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionNodeLocalDNS, actionCheckVersion,
+			},
+		},
+		{
+			Name:         "default actions with konnectivity",
+			WaitForReady: time.Minute,
+			Konnectivity: true,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionKonnectivity, actionStorage, actionKubeadmJoin, actionWaitForReady,
+			},
+		},
+		{
+			Name:        "konnectivity agents without konnectivity",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionKonnectivity},
+			ExpectError: true,
+		},
+		{
+			Name:             "default actions with CoreDNS upstreams",
+			WaitForReady:     time.Minute,
+			CoreDNSUpstreams: []string{"8.8.8.8"},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionCoreDNSUpstreams,
+				actionInstallCNI, actionStorage, actionKubeadmJoin, actionWaitForReady,
+			},
+		},
+		{
+			Name:          "default actions checking the version",
+			WaitForReady:  time.Minute,
+			ExpectVersion: "v1.19",
+			Untaint:       true,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionCheckVersion, actionUntaint,
+			},
+		},
+		{
+			Name:        "check version without an expected version",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionCheckVersion},
+			ExpectError: true,
+		},
+		{
+			Name:         "default actions with a schedulable control plane",
+			WaitForReady: time.Minute,
+			Schedulable:  true,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionUntaint,
+			},
+		},
+		{
+			Name:              "containerd version without the containerd action",
+			Actions:           []string{actionLoadBalancer, actionConfig, actionKubeadmInit},
+			ContainerdVersion: "v1.6.8",
+			ExpectError:       true,
+		},
+		{
+			Name:        "default actions with node CA certs",
+			NodeCACerts: []string{"/ca.crt"},
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionCACerts, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin,
+			},
+		},
+		{
+			Name:        "CA certs before init",
+			Actions:     []string{actionLoadBalancer, actionCACerts, actionConfig, actionKubeadmInit},
+			NodeCACerts: []string{"/ca.crt"},
+			Expected:    []string{actionLoadBalancer, actionCACerts, actionConfig, actionKubeadmInit},
+		},
+		{
+			Name:        "CA certs after init",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionCACerts},
+			NodeCACerts: []string{"/ca.crt"},
+			ExpectError: true,
+		},
+		{
+			Name:        "node CA certs without the CA certs action",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit},
+			NodeCACerts: []string{"/ca.crt"},
+			ExpectError: true,
+		},
+		{
+			Name:        "CA certs action without node CA certs",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionCACerts, actionKubeadmInit},
+			ExpectError: true,
+		},
+		{
+			Name: "containerd after init",
+			Actions: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionContainerd,
+			},
+			ContainerdVersion: "v1.6.8",
+			ExpectError:       true,
+		},
+		{
+			Name:              "default actions with a containerd version",
+			ContainerdVersion: "v1.6.8",
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionContainerd, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin,
+			},
+		},
+		{
+			Name:         "default actions verifying HA",
+			WaitForReady: time.Minute,
+			VerifyHA:     true,
+			Expected: []string{
+				actionLoadBalancer, actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin, actionWaitForReady, actionVerifyHA,
+			},
+		},
+		{
+			Name:        "verify HA before join",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionVerifyHA, actionKubeadmJoin},
+			ExpectError: true,
+		},
+		{
+			Name:        "untaint before join",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionUntaint, actionKubeadmJoin},
+			ExpectError: true,
+		},
+		{
+			Name:       "default actions with an external load balancer",
+			ExternalLB: "192.168.1.10:6443",
+			Expected: []string{
+				actionConfig, actionKubeadmInit, actionWaitForAPIServer, actionInstallCNI,
+				actionStorage, actionKubeadmJoin,
+			},
+		},
+		{
+			Name:       "external load balancer without loadbalancer action",
+			ExternalLB: "192.168.1.10:6443",
+			Actions:    []string{actionConfig, actionKubeadmInit},
+			Expected:   []string{actionConfig, actionKubeadmInit},
+		},
+		{
+			Name:        "loadbalancer action with an external load balancer",
+			ExternalLB:  "192.168.1.10:6443",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit},
+			ExpectError: true,
+		},
+		{
+			Name:        "local registry without the option",
+			Actions:     []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionRegistry},
+			ExpectError: true,
+		},
+		{
+			Name:              "CNI with default CNI disabled",
+			Actions:           []string{actionLoadBalancer, actionConfig, actionKubeadmInit, actionInstallCNI},
+			DisableDefaultCNI: true,
+			ExpectError:       true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			opts := &ClusterOptions{
+				Config:                       &config.Cluster{},
+				Actions:                      tc.Actions,
+				WaitForReady:                 tc.WaitForReady,
+				LocalRegistry:                tc.LocalRegistry,
+				StopAfterAction:              tc.StopAfterAction,
+				ExternalLoadBalancerEndpoint: tc.ExternalLB,
+				PrintJoinCommand:             tc.JoinCommand,
+				ExpectKubernetesVersion:      tc.ExpectVersion,
+				VerifyAPIServerHA:            tc.VerifyHA,
+				ContainerdVersion:            tc.ContainerdVersion,
+				NodeCACerts:                  tc.NodeCACerts,
+				UntaintControlPlane:          tc.Untaint,
+				SchedulableControlPlane:      tc.Schedulable,
+				SeedObjects:                  tc.SeedObjects,
+				WaitForWorkloads:             tc.Workloads,
+				NodeLocalDNS:                 tc.NodeLocalDNS,
+				CoreDNSUpstreams:             tc.CoreDNSUpstreams,
+				Konnectivity:                 tc.Konnectivity,
+			}
+			opts.Config.Networking.DisableDefaultCNI = tc.DisableDefaultCNI
+			if tc.NodeLabels != nil {
+				opts.Config.Nodes = []config.Node{{Role: config.WorkerRole, Labels: tc.NodeLabels}}
+			}
+			planned, err := planActions(opts)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err != nil {
+				return
+			}
+			result := []string{}
+			for _, action := range planned {
+				result = append(result, action.name)
+			}
+			assert.DeepEqual(t, tc.Expected, result)
+		})
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Opts        ClusterOptions
+		ExpectError bool
+	}{
+		{
+			Name: "defaults",
+		},
+		{
+			Name: "poll interval within wait",
+			Opts: ClusterOptions{
+				WaitForReady:      time.Minute,
+				ReadyPollInterval: time.Second,
+			},
+		},
+		{
+			Name: "negative poll interval",
+			Opts: ClusterOptions{
+				WaitForReady:      time.Minute,
+				ReadyPollInterval: -time.Second,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "poll interval larger than wait",
+			Opts: ClusterOptions{
+				WaitForReady:      time.Second,
+				ReadyPollInterval: time.Minute,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "hardened security profile",
+			Opts: ClusterOptions{
+				SecurityProfile: "hardened",
+			},
+		},
+		{
+			Name: "unknown security profile",
+			Opts: ClusterOptions{
+				SecurityProfile: "bogus",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "bootstrap token",
+			Opts: ClusterOptions{
+				BootstrapToken:    "kind00.0123456789abcdef",
+				BootstrapTokenTTL: time.Hour,
+			},
+		},
+		{
+			Name: "invalid bootstrap token",
+			Opts: ClusterOptions{
+				BootstrapToken: "kind.0123456789abcdef",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "negative bootstrap token TTL",
+			Opts: ClusterOptions{
+				BootstrapTokenTTL: -time.Hour,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "missing pod security admission config",
+			Opts: ClusterOptions{
+				PodSecurityConfigPath: "./testdata/does-not-exist.yaml",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "existing network",
+			Opts: ClusterOptions{ExistingNetworkID: "0123456789ab"},
+		},
+		{
+			Name:        "existing network by name",
+			Opts:        ClusterOptions{ExistingNetworkID: "my-network"},
+			ExpectError: true,
+		},
+		{
+			Name: "graceful node shutdown",
+			Opts: ClusterOptions{
+				ShutdownGracePeriod:             30 * time.Second,
+				ShutdownGracePeriodCriticalPods: 10 * time.Second,
+			},
+		},
+		{
+			Name: "critical pods shutdown grace period longer than the total",
+			Opts: ClusterOptions{
+				ShutdownGracePeriod:             10 * time.Second,
+				ShutdownGracePeriodCriticalPods: 30 * time.Second,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "graceful node shutdown with the feature gate disabled",
+			Opts: ClusterOptions{
+				Config:              &config.Cluster{FeatureGates: map[string]bool{"GracefulNodeShutdown": false}},
+				ShutdownGracePeriod: 30 * time.Second,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "konnectivity",
+			Opts: ClusterOptions{Konnectivity: true},
+		},
+		{
+			Name: "konnectivity with multiple control planes",
+			Opts: ClusterOptions{
+				Config: &config.Cluster{Nodes: []config.Node{
+					{Role: config.ControlPlaneRole},
+					{Role: config.ControlPlaneRole},
+					{Role: config.ControlPlaneRole},
+				}},
+				Konnectivity: true,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node exec env",
+			Opts: ClusterOptions{NodeExecEnv: map[string]string{"HTTP_PROXY": "http://proxy:3128", "no_proxy": ""}},
+		},
+		{
+			Name:        "invalid node exec env key",
+			Opts:        ClusterOptions{NodeExecEnv: map[string]string{"HTTP-PROXY": "http://proxy:3128"}},
+			ExpectError: true,
+		},
+		{
+			Name: "secondary networks",
+			Opts: ClusterOptions{SecondaryNetworks: []NetworkSpec{
+				{Name: "net1", Subnet: "192.168.100.0/24"},
+				{Name: "net2"},
+			}},
+		},
+		{
+			Name:        "secondary network named like the primary network",
+			Opts:        ClusterOptions{SecondaryNetworks: []NetworkSpec{{Name: "kind"}}},
+			ExpectError: true,
+		},
+		{
+			Name:        "duplicated secondary network",
+			Opts:        ClusterOptions{SecondaryNetworks: []NetworkSpec{{Name: "net1"}, {Name: "net1"}}},
+			ExpectError: true,
+		},
+		{
+			Name:        "secondary network subnet overlapping the pod subnet",
+			Opts:        ClusterOptions{SecondaryNetworks: []NetworkSpec{{Name: "net1", Subnet: "10.244.10.0/24"}}},
+			ExpectError: true,
+		},
+		{
+			Name: "overlapping secondary network subnets",
+			Opts: ClusterOptions{SecondaryNetworks: []NetworkSpec{
+				{Name: "net1", Subnet: "192.168.0.0/16"},
+				{Name: "net2", Subnet: "192.168.100.0/24"},
+			}},
+			ExpectError: true,
+		},
+		{
+			Name: "timezone",
+			Opts: ClusterOptions{Timezone: "Asia/Tokyo"},
+		},
+		{
+			Name:        "invalid timezone",
+			Opts:        ClusterOptions{Timezone: "Asia Tokyo"},
+			ExpectError: true,
+		},
+		{
+			Name: "node CIDR mask size",
+			Opts: ClusterOptions{NodeCIDRMaskSizeIPv4: 26},
+		},
+		{
+			Name:        "node CIDR mask size shorter than the pod subnet",
+			Opts:        ClusterOptions{NodeCIDRMaskSizeIPv4: 8},
+			ExpectError: true,
+		},
+		{
+			Name:        "node CIDR mask size too long",
+			Opts:        ClusterOptions{NodeCIDRMaskSizeIPv4: 33},
+			ExpectError: true,
+		},
+		{
+			Name:        "IPv6 node CIDR mask size in an ipv4 cluster",
+			Opts:        ClusterOptions{NodeCIDRMaskSizeIPv6: 64},
+			ExpectError: true,
+		},
+		{
+			Name: "IPv6 node CIDR mask size",
+			Opts: ClusterOptions{
+				Config:               &config.Cluster{Networking: config.Networking{IPFamily: config.IPv6Family}},
+				NodeCIDRMaskSizeIPv6: 72,
+			},
+		},
+		{
+			Name: "IPv6 node CIDR mask size too many bits longer than the pod subnet",
+			Opts: ClusterOptions{
+				Config:               &config.Cluster{Networking: config.Networking{IPFamily: config.IPv6Family}},
+				NodeCIDRMaskSizeIPv6: 96,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "OIDC",
+			Opts: ClusterOptions{
+				OIDC: OIDCConfig{IssuerURL: "https://dex.example.com", ClientID: "kubernetes"},
+			},
+		},
+		{
+			Name: "OIDC issuer is not a URL",
+			Opts: ClusterOptions{
+				OIDC: OIDCConfig{IssuerURL: "dex.example.com", ClientID: "kubernetes"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "missing OIDC issuer CA",
+			Opts: ClusterOptions{
+				OIDC: OIDCConfig{
+					IssuerURL: "https://dex.example.com",
+					ClientID:  "kubernetes",
+					CAPath:    "./testdata/does-not-exist.crt",
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "control plane stagger",
+			Opts: ClusterOptions{
+				ControlPlaneStagger: 10 * time.Second,
+			},
+		},
+		{
+			Name: "negative control plane stagger",
+			Opts: ClusterOptions{
+				ControlPlaneStagger: -time.Second,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "image pull timeout",
+			Opts: ClusterOptions{
+				ImagePullTimeout: time.Hour,
+			},
+		},
+		{
+			Name: "negative image pull timeout",
+			Opts: ClusterOptions{
+				ImagePullTimeout: -time.Second,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "kubelet root dir",
+			Opts: ClusterOptions{
+				KubeletRootDir: "/data/kubelet",
+			},
+		},
+		{
+			Name: "relative kubelet root dir",
+			Opts: ClusterOptions{
+				KubeletRootDir: "data/kubelet",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "wait for workloads",
+			Opts: ClusterOptions{
+				WaitForWorkloads: []WorkloadRef{
+					{Namespace: "operators", Kind: "Deployment", Name: "webhook", Timeout: time.Minute},
+					{Kind: "Job", Name: "migrate"},
+				},
+			},
+		},
+		{
+			Name: "invalid workload kind",
+			Opts: ClusterOptions{
+				WaitForWorkloads: []WorkloadRef{{Kind: "Pod", Name: "webhook"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "workload without a name",
+			Opts: ClusterOptions{
+				WaitForWorkloads: []WorkloadRef{{Kind: "DaemonSet"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "disabling a required admission plugin",
+			Opts: ClusterOptions{
+				DisableAdmissionPlugins: []string{"NodeRestriction"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "force disabling a required admission plugin",
+			Opts: ClusterOptions{
+				EnableAdmissionPlugins:       []string{"AlwaysPullImages"},
+				DisableAdmissionPlugins:      []string{"NodeRestriction"},
+				ForceDisableAdmissionPlugins: true,
+			},
+		},
+		{
+			Name: "disabled addon apply retries",
+			Opts: ClusterOptions{
+				AddonApplyRetries: -1,
+			},
+		},
+		{
+			Name: "negative addon apply retries",
+			Opts: ClusterOptions{
+				AddonApplyRetries: -2,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "client credentials",
+			Opts: ClusterOptions{
+				CACertPath:     "ca.crt",
+				ClientCertPath: "client.crt",
+				ClientKeyPath:  "client.key",
+			},
+		},
+		{
+			Name: "client credentials in a missing directory",
+			Opts: ClusterOptions{
+				ClientKeyPath: "missing/client.key",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "client credentials at the same path",
+			Opts: ClusterOptions{
+				ClientCertPath: "client.pem",
+				ClientKeyPath:  "./client.pem",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "yaml plan output",
+			Opts: ClusterOptions{
+				PlanOutput: "yaml",
+			},
+		},
+		{
+			Name: "invalid plan output",
+			Opts: ClusterOptions{
+				PlanOutput: "toml",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "join token TTL",
+			Opts: ClusterOptions{
+				JoinTokenTTL: 2 * time.Hour,
+			},
+		},
+		{
+			Name: "negative join token TTL",
+			Opts: ClusterOptions{
+				JoinTokenTTL: -time.Hour,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "fractional join token TTL",
+			Opts: ClusterOptions{
+				JoinTokenTTL: 1500 * time.Millisecond,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "join concurrency",
+			Opts: ClusterOptions{
+				JoinConcurrency: 2,
+			},
+		},
+		{
+			Name: "negative join concurrency",
+			Opts: ClusterOptions{
+				JoinConcurrency: -1,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "wait for system pods",
+			Opts: ClusterOptions{
+				WaitForReady:      time.Minute,
+				WaitForSystemPods: true,
+			},
+		},
+		{
+			Name: "wait for system pods without waiting",
+			Opts: ClusterOptions{
+				WaitForSystemPods: true,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "exclude system pods",
+			Opts: ClusterOptions{
+				WaitForReady:            time.Minute,
+				WaitForSystemPods:       true,
+				SystemPodsExcludeNames:  []string{"coredns", "daemonset/kube-proxy"},
+				SystemPodsExcludeLabels: []string{"k8s-app=metrics-server", "example.com/optional"},
+			},
+		},
+		{
+			Name: "exclude system pods without waiting for system pods",
+			Opts: ClusterOptions{
+				WaitForReady:           time.Minute,
+				SystemPodsExcludeNames: []string{"coredns"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "exclude system pods of unknown kind",
+			Opts: ClusterOptions{
+				WaitForReady:           time.Minute,
+				WaitForSystemPods:      true,
+				SystemPodsExcludeNames: []string{"statefulset/etcd"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "exclude system pods by invalid name",
+			Opts: ClusterOptions{
+				WaitForReady:           time.Minute,
+				WaitForSystemPods:      true,
+				SystemPodsExcludeNames: []string{"CoreDNS"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "exclude system pods by invalid label",
+			Opts: ClusterOptions{
+				WaitForReady:            time.Minute,
+				WaitForSystemPods:       true,
+				SystemPodsExcludeLabels: []string{"k8s-app=not valid"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "continue on node failure with quorum",
+			Opts: ClusterOptions{
+				WaitForReady:          time.Minute,
+				ContinueOnNodeFailure: true,
+				MinReadyNodes:         1,
+			},
+		},
+		{
+			Name: "minimum ready nodes larger than the cluster",
+			Opts: ClusterOptions{
+				WaitForReady:          time.Minute,
+				ContinueOnNodeFailure: true,
+				MinReadyNodes:         2,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "minimum ready nodes without continuing on node failure",
+			Opts: ClusterOptions{
+				WaitForReady:  time.Minute,
+				MinReadyNodes: 1,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "CNI ready check",
+			Opts: ClusterOptions{
+				WaitForReady:      time.Minute,
+				CNIReadyDaemonSet: "kube-system/calico-node",
+			},
+		},
+		{
+			Name: "CNI ready check without a namespace",
+			Opts: ClusterOptions{
+				WaitForReady:      time.Minute,
+				CNIReadyDaemonSet: "calico-node",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "CNI ready check without waiting",
+			Opts: ClusterOptions{
+				CNIReadyDaemonSet: "kube-system/calico-node",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "custom ready condition and label",
+			Opts: ClusterOptions{
+				ReadyCondition: "example.com/NetworkReady",
+				ReadyLabel:     "example.com/cni=ready",
+			},
+		},
+		{
+			Name: "invalid ready condition",
+			Opts: ClusterOptions{
+				ReadyCondition: "Network Ready",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "invalid ready label",
+			Opts: ClusterOptions{
+				ReadyLabel: "cni=not ready",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "host address override",
+			Opts: ClusterOptions{
+				HostAddressOverride: "docker.example.com",
+			},
+		},
+		{
+			Name: "invalid host address override",
+			Opts: ClusterOptions{
+				HostAddressOverride: "docker.example.com:2376",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "control plane endpoint",
+			Opts: ClusterOptions{
+				ControlPlaneEndpoint: "api.example.com:6443",
+			},
+		},
+		{
+			Name: "control plane endpoint without a port",
+			Opts: ClusterOptions{
+				ControlPlaneEndpoint: "api.example.com",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "recreate existing cluster",
+			Opts: ClusterOptions{
+				OnExisting: OnExistingRecreate,
+			},
+		},
+		{
+			Name: "invalid existing cluster behavior",
+			Opts: ClusterOptions{
+				OnExisting: OnExisting(42),
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "API server tuning",
+			Opts: ClusterOptions{
+				APIServerRequestTimeout:  5 * time.Minute,
+				APIServerWatchCacheSizes: map[string]int32{"pods": 5000},
+			},
+		},
+		{
+			Name: "invalid API server watch cache size",
+			Opts: ClusterOptions{
+				APIServerWatchCacheSizes: map[string]int32{"pods": -1},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "action verbosity",
+			Opts: ClusterOptions{
+				ActionVerbosity: map[string]int{actionKubeadmInit: 3},
+			},
+		},
+		{
+			Name: "verbosity for an unknown action",
+			Opts: ClusterOptions{
+				ActionVerbosity: map[string]int{"kubeadm-bogus": 3},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "containerd version",
+			Opts: ClusterOptions{
+				ContainerdVersion: "v1.7.0",
+			},
+		},
+		{
+			Name: "containerd version with the hardened security profile",
+			Opts: ClusterOptions{
+				ContainerdVersion: "v1.7.0",
+				SecurityProfile:   common.SecurityProfileHardened,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "invalid containerd version",
+			Opts: ClusterOptions{
+				ContainerdVersion: "1.7",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "expected Kubernetes version",
+			Opts: ClusterOptions{
+				ExpectKubernetesVersion: "v1.19",
+			},
+		},
+		{
+			Name: "invalid expected Kubernetes version",
+			Opts: ClusterOptions{
+				ExpectKubernetesVersion: "latest",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "CNI image from a mirror",
+			Opts: ClusterOptions{
+				CNIImage: "registry.example.com:5000/kindest/kindnetd:v20200725-4d6bea59",
+			},
+		},
+		{
+			Name: "CNI image by digest",
+			Opts: ClusterOptions{
+				CNIImage: "mirror.example.com/kindnetd@sha256:" + strings.Repeat("a", 64),
+			},
+		},
+		{
+			Name: "invalid CNI image",
+			Opts: ClusterOptions{
+				CNIImage: "Kindnetd:latest tag",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "seed objects",
+			Opts: ClusterOptions{
+				SeedObjects: []interface{}{
+					map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Secret",
+						"metadata":   map[string]interface{}{"name": "creds", "namespace": "app"},
+						"stringData": map[string]string{"token": "secret"},
+					},
+				},
+			},
+		},
+		{
+			Name: "seed object without a kind",
+			Opts: ClusterOptions{
+				SeedObjects: []interface{}{
+					map[string]interface{}{
+						"apiVersion": "v1",
+						"metadata":   map[string]interface{}{"name": "config"},
+					},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "seed object that is not an object",
+			Opts: ClusterOptions{
+				SeedObjects: []interface{}{"kind: Secret"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "external load balancer",
+			Opts: ClusterOptions{
+				ExternalLoadBalancerEndpoint: "lb.example.com:6443",
+			},
+		},
+		{
+			Name: "verify HA with a single control plane",
+			Opts: ClusterOptions{
+				VerifyAPIServerHA: true,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "kubeconfig CA path",
+			Opts: ClusterOptions{
+				KubeconfigCAPath: "/etc/kind/ca.crt",
+			},
+		},
+		{
+			Name: "kubeconfig insecure skip TLS verify",
+			Opts: ClusterOptions{
+				KubeconfigInsecureSkipTLSVerify: true,
+			},
+		},
+		{
+			Name: "kubeconfig CA path with insecure skip TLS verify",
+			Opts: ClusterOptions{
+				KubeconfigCAPath:                "/etc/kind/ca.crt",
+				KubeconfigInsecureSkipTLSVerify: true,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "encrypted kubeconfig",
+			Opts: ClusterOptions{
+				KubeconfigPath:          "/tmp/kind/kubeconfig.enc",
+				KubeconfigEncryptionKey: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			},
+		},
+		{
+			Name: "encrypted kubeconfig without an explicit path",
+			Opts: ClusterOptions{
+				KubeconfigEncryptionKey: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "encrypted kubeconfig with a short key",
+			Opts: ClusterOptions{
+				KubeconfigPath:          "/tmp/kind/kubeconfig.enc",
+				KubeconfigEncryptionKey: "000102030405060708090a0b0c0d0e0f",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node name template",
+			Opts: ClusterOptions{
+				NodeNameTemplate: "{{.Cluster}}-{{.Role}}-{{.Index}}",
+			},
+		},
+		{
+			Name: "invalid node name template",
+			Opts: ClusterOptions{
+				NodeNameTemplate: "{{.Cluster}",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "ignore nodes for ready",
+			Opts: ClusterOptions{
+				WaitForReady:        time.Minute,
+				IgnoreNodesForReady: []string{"kind-worker2", "slow-worker"},
+				Config: &config.Cluster{
+					Nodes: []config.Node{
+						{Role: config.ControlPlaneRole},
+						{Role: config.WorkerRole},
+						{Role: config.WorkerRole},
+						{Role: config.WorkerRole, Hostname: "slow-worker"},
+					},
+				},
+			},
+		},
+		{
+			Name: "ignore nodes for ready without waiting for ready",
+			Opts: ClusterOptions{
+				IgnoreNodesForReady: []string{"kind-worker"},
+				Config: &config.Cluster{
+					Nodes: []config.Node{{Role: config.ControlPlaneRole}, {Role: config.WorkerRole}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "ignore unknown node for ready",
+			Opts: ClusterOptions{
+				WaitForReady:        time.Minute,
+				IgnoreNodesForReady: []string{"kind-worker2"},
+				Config: &config.Cluster{
+					Nodes: []config.Node{{Role: config.ControlPlaneRole}, {Role: config.WorkerRole}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "ignore every control plane node for ready",
+			Opts: ClusterOptions{
+				WaitForReady:        time.Minute,
+				IgnoreNodesForReady: []string{"kind-control-plane"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "minimum ready nodes including ignored nodes",
+			Opts: ClusterOptions{
+				WaitForReady:          time.Minute,
+				ContinueOnNodeFailure: true,
+				MinReadyNodes:         2,
+				IgnoreNodesForReady:   []string{"kind-worker"},
+				Config: &config.Cluster{
+					Nodes: []config.Node{{Role: config.ControlPlaneRole}, {Role: config.WorkerRole}},
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node platform",
+			Opts: ClusterOptions{
+				NodePlatform: "linux/arm64",
+			},
+		},
+		{
+			Name: "node platform with variant",
+			Opts: ClusterOptions{
+				NodePlatform: "linux/arm/v7",
+			},
+		},
+		{
+			Name: "node platform without arch",
+			Opts: ClusterOptions{
+				NodePlatform: "arm64",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "network MTU",
+			Opts: ClusterOptions{
+				NetworkMTU: 1400,
+			},
+		},
+		{
+			Name: "network MTU too small",
+			Opts: ClusterOptions{
+				NetworkMTU: 576,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "negative network MTU",
+			Opts: ClusterOptions{
+				NetworkMTU: -1,
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node-local DNS",
+			Opts: ClusterOptions{
+				NodeLocalDNS: true,
+			},
+		},
+		{
+			Name: "CoreDNS upstreams",
+			Opts: ClusterOptions{
+				CoreDNSUpstreams: []string{"8.8.8.8", "1.1.1.1:5353", "2001:4860:4860::8888", "[2606:4700:4700::1111]:53"},
+			},
+		},
+		{
+			Name: "CoreDNS upstream hostname",
+			Opts: ClusterOptions{
+				CoreDNSUpstreams: []string{"dns.google"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "CoreDNS upstream with invalid port",
+			Opts: ClusterOptions{
+				CoreDNSUpstreams: []string{"8.8.8.8:0"},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "too many CoreDNS upstreams",
+			Opts: ClusterOptions{
+				CoreDNSUpstreams: []string{
+					"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6", "10.0.0.7", "10.0.0.8",
+					"10.0.0.9", "10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.13", "10.0.0.14", "10.0.0.15", "10.0.0.16",
+				},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node-local DNS with a service subnet too small for the DNS service IP",
+			Opts: ClusterOptions{
+				NodeLocalDNS: true,
+				Config: func() *config.Cluster {
+					c := &config.Cluster{}
+					c.Networking.ServiceSubnet = "10.96.0.0/29"
+					return c
+				}(),
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node-local DNS with ipvs",
+			Opts: ClusterOptions{
+				NodeLocalDNS: true,
+				Config: func() *config.Cluster {
+					c := &config.Cluster{}
+					c.Networking.KubeProxyMode = config.IPVSMode
+					return c
+				}(),
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "node-local DNS without the default CNI",
+			Opts: ClusterOptions{
+				NodeLocalDNS: true,
+				Config: func() *config.Cluster {
+					c := &config.Cluster{}
+					c.Networking.DisableDefaultCNI = true
+					return c
+				}(),
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "external load balancer without port",
+			Opts: ClusterOptions{
+				ExternalLoadBalancerEndpoint: "lb.example.com",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "external load balancer with invalid port",
+			Opts: ClusterOptions{
+				ExternalLoadBalancerEndpoint: "lb.example.com:0",
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			opts := tc.Opts
+			if opts.Config == nil {
+				opts.Config = &config.Cluster{}
+			}
+			config.SetDefaultsCluster(opts.Config)
+			assert.ExpectError(t, tc.ExpectError, validateOptions(&opts))
+		})
+	}
+}
+
+func TestNodeCIDRCapacity(t *testing.T) {
+	t.Parallel()
+	opts := &ClusterOptions{Config: &config.Cluster{}}
+	config.SetDefaultsCluster(opts.Config)
+	assert.DeepEqual(t, 0, nodeCIDRCapacity(opts))
+	opts.Config.Networking.PodSubnet = "10.244.0.0/24"
+	opts.NodeCIDRMaskSizeIPv4 = 26
+	assert.DeepEqual(t, 4, nodeCIDRCapacity(opts))
+	opts.NodeCIDRMaskSizeIPv4 = 24
+	assert.DeepEqual(t, 1, nodeCIDRCapacity(opts))
+}
+
+func TestEstimateResources(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Cluster{
+		Nodes: []config.Node{
+			{Role: config.ControlPlaneRole},
+			{Role: config.WorkerRole},
+			{Role: config.WorkerRole},
+		},
+	}
+	milliCPU, memory := estimateResources(cfg)
+	if milliCPU != 2000 {
+		t.Errorf("expected 2000 millicores, got %d", milliCPU)
+	}
+	if memory != 2<<30 {
+		t.Errorf("expected 2GiB, got %s", formatBytes(memory))
+	}
+}
+
+func TestValidateNodeNames(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name                 string
+		Roles                []config.NodeRole
+		Template             string
+		ExternalLoadBalancer bool
+		ExpectError          bool
+	}{
+		{
+			Name:     "unique names",
+			Roles:    []config.NodeRole{config.ControlPlaneRole, config.WorkerRole, config.WorkerRole},
+			Template: "{{.Cluster}}-{{.Role}}-{{.Index}}",
+		},
+		{
+			Name:        "names missing the role are not unique",
+			Roles:       []config.NodeRole{config.ControlPlaneRole, config.WorkerRole},
+			Template:    "node-{{.Index}}",
+			ExpectError: true,
+		},
+		{
+			Name:        "names missing the index are not unique",
+			Roles:       []config.NodeRole{config.ControlPlaneRole, config.WorkerRole, config.WorkerRole},
+			Template:    "{{.Cluster}}-{{.Role}}",
+			ExpectError: true,
+		},
+		{
+			Name:        "implicit load balancer is named too",
+			Roles:       []config.NodeRole{config.ControlPlaneRole, config.ControlPlaneRole, config.WorkerRole},
+			Template:    "{{.Cluster}}-{{if eq .Role \"control-plane\"}}cp{{else}}other{{end}}-{{.Index}}",
+			ExpectError: true,
+		},
+		{
+			Name:                 "no load balancer with an external load balancer",
+			Roles:                []config.NodeRole{config.ControlPlaneRole, config.ControlPlaneRole, config.WorkerRole},
+			Template:             "{{.Cluster}}-{{if eq .Role \"control-plane\"}}cp{{else}}other{{end}}-{{.Index}}",
+			ExternalLoadBalancer: true,
+		},
+		{
+			Name:        "names too long",
+			Roles:       []config.NodeRole{config.ControlPlaneRole},
+			Template:    strings.Repeat("a", 65),
+			ExpectError: true,
+		},
+		{
+			Name:        "invalid characters",
+			Roles:       []config.NodeRole{config.ControlPlaneRole},
+			Template:    "{{.Cluster}}/{{.Role}}",
+			ExpectError: true,
+		},
+		{
+			Name:        "unknown field",
+			Roles:       []config.NodeRole{config.ControlPlaneRole},
+			Template:    "{{.Zone}}",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{Name: "kind"}
+			for _, role := range tc.Roles {
+				cfg.Nodes = append(cfg.Nodes, config.Node{Role: role})
+			}
+			assert.ExpectError(t, tc.ExpectError, validateNodeNames(cfg, tc.Template, tc.ExternalLoadBalancer))
+		})
+	}
+}
+
+func TestValidateSkipPhases(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Nodes       []config.Node
+		ExpectError bool
+	}{
+		{
+			Name: "no skip phases",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.WorkerRole},
+			},
+		},
+		{
+			Name: "init phase on the first control plane",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole, SkipPhases: []string{"addon/kube-proxy"}},
+				{Role: config.WorkerRole},
+			},
+		},
+		{
+			Name: "init phase on a secondary control plane",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.ControlPlaneRole, SkipPhases: []string{"addon/kube-proxy"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "init phase on a worker",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.WorkerRole, SkipPhases: []string{"addon/kube-proxy"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "phase kind depends on",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.WorkerRole, SkipPhases: []string{"kubelet-start"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "kubelet only node",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.KubeletOnlyRole, SkipPhases: []string{"preflight"}},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Cluster{Nodes: tc.Nodes}
+			assert.ExpectError(t, tc.ExpectError, validateSkipPhases(cfg))
+		})
+	}
+}
+
+func TestReservePorts(t *testing.T) {
+	t.Parallel()
+	called := false
+	opts := &ClusterOptions{
+		Config: &config.Cluster{
+			Nodes: []config.Node{
+				{
+					ExtraPortMappings: []config.PortMapping{
+						{ContainerPort: 80, ListenAddress: "127.0.0.1"},
+						{ContainerPort: 443, HostPort: 8443, ListenAddress: "127.0.0.1"},
+					},
+				},
+			},
+		},
+		OnAutoPorts: func(*config.Cluster) { called = true },
+	}
+	opts.Config.Networking.APIServerAddress = "127.0.0.1"
+	release, err := reservePorts(log.NoopLogger{}, opts)
+	if err != nil {
+		t.Fatalf("unexpected error reserving ports: %v", err)
+	}
+	defer release()
+	mappings := opts.Config.Nodes[0].ExtraPortMappings
+	if opts.Config.Networking.APIServerPort == 0 || mappings[0].HostPort == 0 {
+		t.Errorf("expected unset ports to be picked, got API server port %d and host port %d",
+			opts.Config.Networking.APIServerPort, mappings[0].HostPort)
+	}
+	if opts.Config.Networking.APIServerPort == mappings[0].HostPort {
+		t.Errorf("expected distinct ports to be picked, got %d twice", mappings[0].HostPort)
+	}
+	if mappings[1].HostPort != 8443 {
+		t.Errorf("expected explicit host port to be kept, got %d", mappings[1].HostPort)
+	}
+	assert.BoolEqual(t, true, called)
+}
+
+func TestWritePlan(t *testing.T) {
+	t.Parallel()
+	opts := &ClusterOptions{
+		PlanOutput: "json",
+		Config: &config.Cluster{
+			Name: "kind",
+			Nodes: []config.Node{
+				{
+					Role:  config.ControlPlaneRole,
+					Image: "kindest/node:v1.19.1",
+					ExtraPortMappings: []config.PortMapping{
+						{ContainerPort: 80, HostPort: 8080, ListenAddress: "127.0.0.1", Protocol: config.PortMappingProtocolTCP},
+					},
+				},
+				{Role: config.WorkerRole, Image: "kindest/node:v1.19.1"},
+				{Role: config.WorkerRole, Image: "kindest/node:v1.19.1"},
+			},
+		},
+	}
+	config.SetDefaultsCluster(opts.Config)
+	opts.Config.Networking.APIServerPort = 6443
+	planned, err := planActions(opts)
+	if err != nil {
+		t.Fatalf("unexpected error planning actions: %v", err)
+	}
+	var out bytes.Buffer
+	if err := writePlan(&out, opts, planned); err != nil {
+		t.Fatalf("unexpected error writing plan: %v", err)
+	}
+	plan := clusterPlan{}
+	if err := json.Unmarshal(out.Bytes(), &plan); err != nil {
+		t.Fatalf("failed to decode plan: %v", err)
+	}
+	names := []string{}
+	for _, n := range plan.Nodes {
+		names = append(names, n.Name)
+	}
+	assert.DeepEqual(t, []string{"kind-control-plane", "kind-worker", "kind-worker2"}, names)
+	assert.StringEqual(t, "kindest/node:v1.19.1", plan.Nodes[0].Image)
+	assert.DeepEqual(t, []plannedPort{{ListenAddress: "127.0.0.1", HostPort: 8080, ContainerPort: 80, Protocol: "TCP"}}, plan.Nodes[0].Ports)
+	assert.DeepEqual(t, plannedPort{ListenAddress: "127.0.0.1", HostPort: 6443}, plan.APIServer)
+	assert.DeepEqual(t, defaultActionNames(opts), plan.Actions)
+	assert.StringEqual(t, "kind", plan.Config.Name)
+}
+
+func TestNodeLogDir(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-node-logs")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := &ClusterOptions{
+		NodeLogDir: dir,
+		Config: &config.Cluster{
+			Name: "logs",
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.WorkerRole},
+			},
+		},
+	}
+	if err := fixupNodeLogDir(opts); err != nil {
+		t.Fatalf("unexpected error fixing up node log dir: %v", err)
+	}
+	assert.DeepEqual(t, []config.Mount{{HostPath: filepath.Join(dir, "logs", "logs-control-plane"), ContainerPath: nodeLogPath}}, opts.Config.Nodes[0].ExtraMounts)
+	assert.DeepEqual(t, []config.Mount{{HostPath: filepath.Join(dir, "logs", "logs-worker"), ContainerPath: nodeLogPath}}, opts.Config.Nodes[1].ExtraMounts)
+
+	if err := prepareNodeLogDir(opts); err != nil {
+		t.Fatalf("unexpected error preparing node log dir: %v", err)
+	}
+	for _, node := range opts.Config.Nodes {
+		if info, err := os.Stat(node.ExtraMounts[0].HostPath); err != nil || !info.IsDir() {
+			t.Errorf("expected node log dir %q to be created: %v", node.ExtraMounts[0].HostPath, err)
+		}
+	}
+	// the same cluster's log dir is now in use
+	assert.ExpectError(t, true, prepareNodeLogDir(opts))
+}
+
+func TestSharedImageCache(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-image-cache")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	opts := &ClusterOptions{
+		SharedImageCache: dir,
+		Config: &config.Cluster{
+			Nodes: []config.Node{
+				{Role: config.ControlPlaneRole},
+				{Role: config.WorkerRole},
+			},
+		},
+	}
+	if err := fixupSharedImageCache(opts); err != nil {
+		t.Fatalf("unexpected error fixing up shared image cache: %v", err)
+	}
+	// the nodes' content stores must not be shared
+	for _, node := range opts.Config.Nodes {
+		assert.DeepEqual(t, []config.Mount(nil), node.ExtraMounts)
+	}
+	assert.DeepEqual(t, []string{common.RegistryCacheContainerdConfigPatch(dir)}, opts.Config.ContainerdConfigPatches)
+
+	opts.SharedImageCache = filepath.Join(dir, "missing")
+	assert.ExpectError(t, true, fixupSharedImageCache(opts))
+}
+
+func TestWriterLogger(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	logger := newWriterLogger(log.NoopLogger{}, &out)
+	logger.V(0).Infof("Creating cluster %q ...\n", "kind")
+	logger.V(0).Info(" \x1b[32m✓\x1b[0m Preparing nodes")
+	logger.V(1).Info("debug messages are only copied if enabled")
+	logger.Warnf("WARNING: %s", "low disk")
+	logger.Error("failed")
+	assert.BoolEqual(t, true, logger.V(0).Enabled())
+	assert.BoolEqual(t, false, logger.V(1).Enabled())
+	assert.BoolEqual(t, false, isSmartLogger(logger))
+	assert.StringEqual(t, "Creating cluster \"kind\" ...\n ✓ Preparing nodes\nWARNING: low disk\nfailed\n", out.String())
+}
+
+func TestQuietLogger(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	logger := quietLogger{newWriterLogger(log.NoopLogger{}, &out)}
+	logger.V(0).Infof("Creating cluster %q ...\n", "kind")
+	logger.Warn("WARNING: low disk")
+	logger.Errorf("failed: %s", "oops")
+	assert.BoolEqual(t, false, logger.V(0).Enabled())
+	assert.BoolEqual(t, false, isSmartLogger(logger))
+	assert.StringEqual(t, "WARNING: low disk\nfailed: oops\n", out.String())
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/logs"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// collectDiagnostics writes the diagnostics bundle to
+// opts.DiagnosticsBundlePath if set
+// Failures are only logged, so as not to mask the result of creating the cluster
+func collectDiagnostics(logger log.Logger, p providers.Provider, opts *ClusterOptions) {
+	if opts.DiagnosticsBundlePath == "" {
+		return
+	}
+	if err := writeDiagnosticsBundle(logger, p, opts.Config.Name, opts.DiagnosticsBundlePath); err != nil {
+		logger.Warnf("Failed to write diagnostics bundle: %v", err)
+		return
+	}
+	logger.V(0).Infof("Wrote diagnostics bundle to %s", opts.DiagnosticsBundlePath)
+}
+
+// writeDiagnosticsBundle collects the logs for the cluster's nodes along with
+// the cluster's resources and writes them to a gzipped tarball at bundlePath
+// Collection is best effort, whatever could be collected is written
+func writeDiagnosticsBundle(logger log.Logger, p providers.Provider, name, bundlePath string) error {
+	dir, err := ioutil.TempDir("", "kind-diagnostics-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	allNodes, err := p.ListNodes(name)
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if err := p.CollectLogs(dir, internalNodes); err != nil {
+		logger.Warnf("Failed to collect some node logs for diagnostics: %v", err)
+	}
+	if err := collectClusterResources(allNodes, filepath.Join(dir, "resources.txt")); err != nil {
+		logger.Warnf("Failed to collect cluster resources for diagnostics: %v", err)
+	}
+
+	return logs.ArchiveDir(dir, name, bundlePath)
+}
+
+// collectClusterResources writes the output of `kubectl get all -A` to path
+// The API server may not be reachable (E.G. if kubeadm init failed),
+// in which case the error output is still written to path
+func collectClusterResources(allNodes []nodes.Node, path string) error {
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return err
+	}
+	f, err := common.FileOnHost(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cmd := node.Command(
+		"kubectl",
+		"--kubeconfig=/etc/kubernetes/admin.conf",
+		// don't hang on an unreachable API server
+		"--request-timeout=10s",
+		"get", "all", "--all-namespaces", "-o", "wide",
+	)
+	if err := cmd.SetStdout(f).SetStderr(f).Run(); err != nil {
+		return errors.Wrap(err, "failed to get cluster resources")
+	}
+	return nil
+}
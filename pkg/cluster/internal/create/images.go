@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// imageInventory is the content of the image inventory file
+type imageInventory struct {
+	// Nodes are the cluster's nodes, sorted by name
+	Nodes []nodeImages `json:"nodes"`
+}
+
+// nodeImages are the images present in a node's containerd
+type nodeImages struct {
+	Name   string      `json:"name"`
+	Images []nodeImage `json:"images"`
+}
+
+// nodeImage is an image as reported by crictl
+type nodeImage struct {
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repoTags"`
+	RepoDigests []string `json:"repoDigests"`
+}
+
+// writeImageInventory writes the images present in each of the cluster's
+// nodes to opts.ImageInventoryPath as JSON if set
+func writeImageInventory(p providers.Provider, opts *ClusterOptions) error {
+	if opts.ImageInventoryPath == "" {
+		return nil
+	}
+	allNodes, err := p.ListNodes(opts.Config.Name)
+	if err != nil {
+		return err
+	}
+	internalNodes, err := nodeutils.InternalNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	sort.Slice(internalNodes, func(i, j int) bool {
+		return internalNodes[i].String() < internalNodes[j].String()
+	})
+
+	inventory := imageInventory{
+		Nodes: make([]nodeImages, len(internalNodes)),
+	}
+	fns := []func() error{}
+	for i, node := range internalNodes {
+		i, node := i, node // capture loop variables
+		fns = append(fns, func() error {
+			images, err := listImages(node)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list images on node %s", node.String())
+			}
+			inventory.Nodes[i] = nodeImages{
+				Name:   node.String(),
+				Images: images,
+			}
+			return nil
+		})
+	}
+	if err := errors.UntilErrorConcurrent(fns); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(opts.ImageInventoryPath, append(b, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "failed to write image inventory")
+	}
+	return nil
+}
+
+// listImages returns the images in the node's containerd, sorted by ID
+func listImages(n nodes.Node) ([]nodeImage, error) {
+	var out bytes.Buffer
+	if err := n.Command("crictl", "images", "-o", "json").SetStdout(&out).Run(); err != nil {
+		return nil, err
+	}
+	crictlOut := struct {
+		Images []nodeImage `json:"images"`
+	}{}
+	if err := json.Unmarshal(out.Bytes(), &crictlOut); err != nil {
+		return nil, err
+	}
+	sort.Slice(crictlOut.Images, func(i, j int) bool {
+		return crictlOut.Images[i].ID < crictlOut.Images[j].ID
+	})
+	return crictlOut.Images, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kind/pkg/log"
+)
+
+// writerLogger wraps a log.Logger, also writing a copy of each message to a
+// writer, see ClusterOptions.LogWriter
+type writerLogger struct {
+	log.Logger
+	w *lineWriter
+}
+
+var _ log.Logger = &writerLogger{}
+
+// newWriterLogger returns logger also writing each message to w as a line,
+// the V(0) messages are always written and the others only if enabled on
+// logger
+func newWriterLogger(logger log.Logger, w io.Writer) log.Logger {
+	return &writerLogger{
+		Logger: logger,
+		w:      &lineWriter{w: w},
+	}
+}
+
+// Warn is part of the log.Logger interface
+func (l *writerLogger) Warn(message string) {
+	l.Logger.Warn(message)
+	l.w.writeLine(message)
+}
+
+// Warnf is part of the log.Logger interface
+func (l *writerLogger) Warnf(format string, args ...interface{}) {
+	l.Warn(fmt.Sprintf(format, args...))
+}
+
+// Error is part of the log.Logger interface
+func (l *writerLogger) Error(message string) {
+	l.Logger.Error(message)
+	l.w.writeLine(message)
+}
+
+// Errorf is part of the log.Logger interface
+func (l *writerLogger) Errorf(format string, args ...interface{}) {
+	l.Error(fmt.Sprintf(format, args...))
+}
+
+// V is part of the log.Logger interface
+func (l *writerLogger) V(level log.Level) log.InfoLogger {
+	return &writerInfoLogger{
+		InfoLogger: l.Logger.V(level),
+		always:     level <= 0,
+		w:          l.w,
+	}
+}
+
+// ColorEnabled reports whether the wrapped logger is colored, see
+// isSmartLogger
+func (l *writerLogger) ColorEnabled() bool {
+	return isSmartLogger(l.Logger)
+}
+
+// Unwrap returns the wrapped logger, see cli.StatusForLogger
+func (l *writerLogger) Unwrap() log.Logger {
+	return l.Logger
+}
+
+// writerInfoLogger is the log.InfoLogger of a writerLogger
+type writerInfoLogger struct {
+	log.InfoLogger
+	always bool
+	w      *lineWriter
+}
+
+// Info is part of the log.InfoLogger interface
+func (l *writerInfoLogger) Info(message string) {
+	l.InfoLogger.Info(message)
+	if l.Enabled() {
+		l.w.writeLine(message)
+	}
+}
+
+// Infof is part of the log.InfoLogger interface
+func (l *writerInfoLogger) Infof(format string, args ...interface{}) {
+	l.Info(fmt.Sprintf(format, args...))
+}
+
+// Enabled is part of the log.InfoLogger interface
+func (l *writerInfoLogger) Enabled() bool {
+	return l.always || l.InfoLogger.Enabled()
+}
+
+// ansiEscapeRE matches the terminal color codes in messages
+var ansiEscapeRE = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// lineWriter writes messages to w as lines without terminal color codes,
+// the messages may be logged concurrently
+type lineWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lineWriter) writeLine(message string) {
+	message = ansiEscapeRE.ReplaceAllString(message, "")
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = io.WriteString(l.w, message)
+}
+
+// quietLogger wraps a log.Logger, only logging warnings and errors, see
+// ClusterOptions.Quiet
+// NOTE: this deliberately does not expose the wrapped logger, so that
+// cli.StatusForLogger does not find and render the spinner
+type quietLogger struct {
+	log.Logger
+}
+
+// V is part of the log.Logger interface
+func (l quietLogger) V(level log.Level) log.InfoLogger {
+	return log.NoopInfoLogger{}
+}
@@ -0,0 +1,489 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/checkversion"
+	configaction "sigs.k8s.io/kind/pkg/cluster/internal/create/actions/config"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/corednsupstreams"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcacerts"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcni"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installcontainerd"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installkonnectivity"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/installstorage"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/joincommand"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadminit"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/kubeadmjoin"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/labelnodes"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/localregistry"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/nodelocaldns"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/seedobjects"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/untaint"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/verifyha"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforapiserver"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforready"
+	"sigs.k8s.io/kind/pkg/cluster/internal/create/actions/waitforworkloads"
+	"sigs.k8s.io/kind/pkg/cluster/internal/kubeadm"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// names of the built-in actions, see ClusterOptions.Actions
+const (
+	actionLoadBalancer     = "loadbalancer"
+	actionConfig           = "config"
+	actionCACerts          = "install-ca-certs"
+	actionContainerd       = "install-containerd"
+	actionKubeadmInit      = "kubeadm-init"
+	actionWaitForAPIServer = "wait-for-apiserver"
+	actionCoreDNSUpstreams = "configure-coredns"
+	actionInstallCNI       = "install-cni"
+	actionStorage          = "install-storage"
+	actionKubeadmJoin      = "kubeadm-join"
+	actionWaitForReady     = "wait-for-ready"
+	actionRegistry         = "local-registry"
+	actionJoinCommand      = "print-join-command"
+	actionSeedObjects      = "seed-objects"
+	actionUntaint          = "untaint-control-plane"
+	actionCheckVersion     = "check-version"
+	actionVerifyHA         = "verify-apiserver-ha"
+	actionNodeLocalDNS     = "install-node-local-dns"
+	actionWaitForWorkloads = "wait-for-workloads"
+	actionLabelNodes       = "label-nodes"
+	actionKonnectivity     = "install-konnectivity-agent"
+)
+
+// builtinAction describes how to plan a built-in action
+type builtinAction struct {
+	// newAction returns the action configured from the cluster options
+	newAction func(opts *ClusterOptions) actions.Action
+	// requires lists the actions which must run before this action
+	requires []string
+}
+
+var builtinActions = map[string]builtinAction{
+	actionLoadBalancer: {
+		newAction: func(*ClusterOptions) actions.Action { return loadbalancer.NewAction() },
+	},
+	actionConfig: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return configaction.NewAction(configaction.Options{
+				KubeletConfigVersion: opts.KubeletConfigVersion,
+				AdvertiseAddress:     opts.APIServerAdvertiseAddress,
+				CloudProvider:        opts.CloudProvider,
+				DNSDomain:            opts.DNSDomain,
+				PodSecurityConfig:    opts.PodSecurityConfigPath,
+				Token:                opts.BootstrapToken,
+				TokenTTL:             opts.BootstrapTokenTTL,
+				Schedulable:          opts.SchedulableControlPlane,
+				ExternalLoadBalancer: opts.ExternalLoadBalancerEndpoint,
+				ControlPlaneEndpoint: opts.ControlPlaneEndpoint,
+				Liveness:             livenessProbeTuning(opts),
+				Shutdown:             gracefulShutdown(opts),
+				APIServer:            apiServerTuning(opts),
+				OIDC:                 opts.OIDC,
+				Konnectivity:         opts.Konnectivity,
+				NodeCIDRMaskSize:     nodeCIDRMaskSize(opts),
+				NodeNameTemplate:     opts.NodeNameTemplate,
+				KubeletRootDir:       opts.KubeletRootDir,
+				OnKubeadmConfig:      opts.OnKubeadmConfig,
+			})
+		},
+	},
+	actionCACerts: {
+		newAction: func(opts *ClusterOptions) actions.Action { return installcacerts.NewAction(opts.NodeCACerts) },
+	},
+	actionContainerd: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return installcontainerd.NewAction(opts.ContainerdVersion)
+		},
+	},
+	actionKubeadmInit: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return kubeadminit.NewAction(
+				opts.VerboseKubeadm, !livenessProbeTuning(opts).IsZero(), opts.NodeNameTemplate, opts.ExpectKubernetesVersion,
+			)
+		},
+		requires: []string{actionLoadBalancer, actionConfig},
+	},
+	actionWaitForAPIServer: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return waitforapiserver.NewAction(opts.APIServerReadyTimeout)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionInstallCNI: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return installcni.NewAction(opts.CNIImage, opts.NetworkMTU, opts.AddonApplyRetries)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionKonnectivity: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return installkonnectivity.NewAction(opts.AddonApplyRetries)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionStorage: {
+		newAction: func(opts *ClusterOptions) actions.Action { return installstorage.NewAction(opts.AddonApplyRetries) },
+		requires:  []string{actionKubeadmInit},
+	},
+	actionKubeadmJoin: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return kubeadmjoin.NewAction(opts.VerboseKubeadm, !livenessProbeTuning(opts).IsZero(), opts.NodeNameTemplate, opts.JoinConcurrency)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionLabelNodes: {
+		newAction: func(opts *ClusterOptions) actions.Action { return labelnodes.NewAction(opts.NodeNameTemplate) },
+		requires:  []string{actionKubeadmInit, actionKubeadmJoin},
+	},
+	actionRegistry: {
+		newAction: func(opts *ClusterOptions) actions.Action { return localregistry.NewAction(opts.LocalRegistryPort) },
+		requires:  []string{actionKubeadmInit},
+	},
+	actionJoinCommand: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			endpoint := opts.ControlPlaneEndpoint
+			if endpoint == "" {
+				endpoint = opts.ExternalLoadBalancerEndpoint
+			}
+			return joincommand.NewAction(opts.JoinTokenTTL, endpoint)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionWaitForReady: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return waitforready.NewAction(waitforready.Options{
+				WaitTime:              opts.WaitForReady,
+				PollInterval:          opts.ReadyPollInterval,
+				Condition:             opts.ReadyCondition,
+				Label:                 opts.ReadyLabel,
+				SystemPods:            opts.WaitForSystemPods,
+				ContinueOnNodeFailure: opts.ContinueOnNodeFailure,
+				MinReadyNodes:         opts.MinReadyNodes,
+				CNIDaemonSet:          opts.CNIReadyDaemonSet,
+				IgnoreNodes:           opts.IgnoreNodesForReady,
+				ExcludeNames:          opts.SystemPodsExcludeNames,
+				ExcludeLabels:         opts.SystemPodsExcludeLabels,
+			})
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionNodeLocalDNS: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return nodelocaldns.NewAction(opts.DNSDomain, opts.AddonApplyRetries)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionCoreDNSUpstreams: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return corednsupstreams.NewAction(opts.CoreDNSUpstreams, opts.AddonApplyRetries)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionCheckVersion: {
+		newAction: func(opts *ClusterOptions) actions.Action {
+			return checkversion.NewAction(opts.ExpectKubernetesVersion)
+		},
+		requires: []string{actionKubeadmInit},
+	},
+	actionVerifyHA: {
+		newAction: func(*ClusterOptions) actions.Action { return verifyha.NewAction() },
+		requires:  []string{actionKubeadmInit, actionKubeadmJoin},
+	},
+	actionUntaint: {
+		newAction: func(*ClusterOptions) actions.Action { return untaint.NewAction() },
+		requires:  []string{actionKubeadmInit, actionKubeadmJoin},
+	},
+	actionSeedObjects: {
+		newAction: func(opts *ClusterOptions) actions.Action { return seedobjects.NewAction(opts.SeedObjects) },
+		requires:  []string{actionKubeadmInit},
+	},
+	actionWaitForWorkloads: {
+		newAction: func(opts *ClusterOptions) actions.Action { return waitforworkloads.NewAction(opts.WaitForWorkloads) },
+		requires:  []string{actionKubeadmInit},
+	},
+}
+
+// livenessProbeTuning returns the control plane liveness probe tuning for opts
+func livenessProbeTuning(opts *ClusterOptions) kubeadm.LivenessProbeTuning {
+	return kubeadm.LivenessProbeTuning{
+		InitialDelaySeconds: int32(opts.ControlPlaneLivenessInitialDelay / time.Second),
+		TimeoutSeconds:      int32(opts.ControlPlaneLivenessTimeout / time.Second),
+	}
+}
+
+// gracefulShutdown returns the kubelet graceful node shutdown config for opts
+func gracefulShutdown(opts *ClusterOptions) kubeadm.GracefulShutdown {
+	return kubeadm.GracefulShutdown{
+		GracePeriod:             opts.ShutdownGracePeriod,
+		CriticalPodsGracePeriod: opts.ShutdownGracePeriodCriticalPods,
+	}
+}
+
+// apiServerTuning returns the API server tuning for opts
+func apiServerTuning(opts *ClusterOptions) kubeadm.APIServerTuning {
+	enable := opts.EnableAdmissionPlugins
+	// the pod security admission config is only used if the plugin is enabled
+	if opts.PodSecurityConfigPath != "" {
+		enable = append(append([]string{}, kubeadm.PodSecurityAdmissionPlugins...), enable...)
+	}
+	return kubeadm.APIServerTuning{
+		RequestTimeout:               opts.APIServerRequestTimeout,
+		DefaultWatchCacheSize:        opts.APIServerDefaultWatchCacheSize,
+		WatchCacheSizes:              opts.APIServerWatchCacheSizes,
+		EnableAdmissionPlugins:       enable,
+		DisableAdmissionPlugins:      opts.DisableAdmissionPlugins,
+		ForceDisableAdmissionPlugins: opts.ForceDisableAdmissionPlugins,
+	}
+}
+
+// hasAPILabels returns true if any node in cfg has labels that must be set
+// through the API, see config.NodeLabels
+func hasAPILabels(cfg *config.Cluster) bool {
+	for i := range cfg.Nodes {
+		if _, api := config.NodeLabels(&cfg.Nodes[i]); len(api) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// namedAction is a planned action along with the name it was planned by
+type namedAction struct {
+	name   string
+	action actions.Action
+}
+
+// defaultActionNames returns the ordered list of actions to run when
+// opts.Actions is not set
+func defaultActionNames(opts *ClusterOptions) []string {
+	names := []string{}
+	// an external load balancer is not ours to configure
+	if opts.ExternalLoadBalancerEndpoint == "" {
+		names = append(names,
+			actionLoadBalancer, // setup external loadbalancer
+		)
+	}
+	names = append(names,
+		actionConfig, // setup kubeadm config
+	)
+	// trust any additional CAs before anything may pull images
+	if len(opts.NodeCACerts) > 0 {
+		names = append(names, actionCACerts)
+	}
+	// swap containerd before anything uses it
+	if opts.ContainerdVersion != "" {
+		names = append(names, actionContainerd)
+	}
+	if opts.StopBeforeSettingUpKubernetes {
+		return names
+	}
+	names = append(names,
+		actionKubeadmInit,      // run kubeadm init
+		actionWaitForAPIServer, // wait for the API server before installing anything
+	)
+	if len(opts.CoreDNSUpstreams) > 0 {
+		names = append(names,
+			actionCoreDNSUpstreams, // configure CoreDNS ahead of the CNI
+		)
+	}
+	// this step might be skipped, but is next after init
+	if !opts.Config.Networking.DisableDefaultCNI {
+		names = append(names,
+			actionInstallCNI, // install CNI
+		)
+	}
+	if opts.Konnectivity {
+		names = append(names,
+			actionKonnectivity, // connect the nodes to the konnectivity server
+		)
+	}
+	// add remaining steps
+	names = append(names,
+		actionStorage,     // install StorageClass
+		actionKubeadmJoin, // run kubeadm join
+	)
+	if hasAPILabels(opts.Config) {
+		names = append(names,
+			actionLabelNodes, // set the labels the kubelets may not set
+		)
+	}
+	if opts.LocalRegistry {
+		names = append(names,
+			actionRegistry, // create the local registry
+		)
+	}
+	if opts.PrintJoinCommand {
+		names = append(names,
+			actionJoinCommand, // print the join command for external nodes
+		)
+	}
+	// a zero WaitForReady means don't wait at all
+	if opts.WaitForReady > 0 {
+		names = append(names,
+			actionWaitForReady, // wait for cluster readiness
+		)
+	}
+	if opts.NodeLocalDNS {
+		names = append(names,
+			actionNodeLocalDNS, // install the DNS cache once ready
+		)
+	}
+	if opts.ExpectKubernetesVersion != "" {
+		names = append(names,
+			actionCheckVersion, // verify the Kubernetes version once ready
+		)
+	}
+	if opts.VerifyAPIServerHA {
+		names = append(names,
+			actionVerifyHA, // verify every control plane is serving
+		)
+	}
+	if opts.UntaintControlPlane || opts.SchedulableControlPlane {
+		names = append(names,
+			actionUntaint, // allow workloads on the control plane
+		)
+	}
+	if len(opts.SeedObjects) > 0 {
+		names = append(names,
+			actionSeedObjects, // create the seed objects once ready
+		)
+	}
+	if len(opts.WaitForWorkloads) > 0 {
+		names = append(names,
+			actionWaitForWorkloads, // wait for workloads, E.G. from the seed objects
+		)
+	}
+	return names
+}
+
+// planActions returns the actions to run, in order
+// If opts.Actions is set it is validated and used instead of the defaults
+func planActions(opts *ClusterOptions) ([]namedAction, error) {
+	names := opts.Actions
+	if len(names) == 0 {
+		names = defaultActionNames(opts)
+	} else if err := validateActionNames(opts, names); err != nil {
+		return nil, err
+	}
+	// only run up to and including opts.StopAfterAction if set
+	if opts.StopAfterAction != "" {
+		stop := -1
+		for i, name := range names {
+			if name == opts.StopAfterAction {
+				stop = i
+			}
+		}
+		if stop == -1 {
+			return nil, errors.Errorf("cannot stop after action %q, it is not one of the actions to run: %v", opts.StopAfterAction, names)
+		}
+		names = names[:stop+1]
+	}
+	planned := make([]namedAction, 0, len(names))
+	for _, name := range names {
+		planned = append(planned, namedAction{
+			name:   name,
+			action: builtinActions[name].newAction(opts),
+		})
+	}
+	return planned, nil
+}
+
+// before returns true if a is in names before b
+func before(names []string, a, b string) bool {
+	for _, name := range names {
+		switch name {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+	return false
+}
+
+// validateActionNames ensures that names only contains known actions,
+// at most once each, and that their requirements are ordered before them
+func validateActionNames(opts *ClusterOptions, names []string) error {
+	errs := []error{}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		action, known := builtinActions[name]
+		if !known {
+			errs = append(errs, errors.Errorf("unknown action %q", name))
+			continue
+		}
+		if seen[name] {
+			errs = append(errs, errors.Errorf("action %q is specified more than once", name))
+		}
+		for _, required := range action.requires {
+			// the load balancer is managed outside of kind if external
+			if required == actionLoadBalancer && opts.ExternalLoadBalancerEndpoint != "" {
+				continue
+			}
+			if !seen[required] {
+				errs = append(errs, errors.Errorf("action %q must be preceded by action %q", name, required))
+			}
+		}
+		seen[name] = true
+	}
+	if seen[actionInstallCNI] && opts.Config.Networking.DisableDefaultCNI {
+		errs = append(errs, errors.Errorf("action %q cannot be used with disableDefaultCNI", actionInstallCNI))
+	}
+	if seen[actionLoadBalancer] && opts.ExternalLoadBalancerEndpoint != "" {
+		errs = append(errs, errors.Errorf("action %q cannot be used with an external load balancer", actionLoadBalancer))
+	}
+	if seen[actionCheckVersion] && opts.ExpectKubernetesVersion == "" {
+		errs = append(errs, errors.Errorf("action %q requires an expected Kubernetes version", actionCheckVersion))
+	}
+	if seen[actionContainerd] && opts.ContainerdVersion == "" {
+		errs = append(errs, errors.Errorf("action %q requires a containerd version", actionContainerd))
+	}
+	if !seen[actionContainerd] && opts.ContainerdVersion != "" {
+		errs = append(errs, errors.Errorf("a containerd version requires action %q", actionContainerd))
+	}
+	if seen[actionContainerd] && seen[actionKubeadmInit] && !before(names, actionContainerd, actionKubeadmInit) {
+		errs = append(errs, errors.Errorf("action %q must come before action %q", actionContainerd, actionKubeadmInit))
+	}
+	if seen[actionCACerts] && len(opts.NodeCACerts) == 0 {
+		errs = append(errs, errors.Errorf("action %q requires node CA certificates", actionCACerts))
+	}
+	if !seen[actionCACerts] && len(opts.NodeCACerts) > 0 {
+		errs = append(errs, errors.Errorf("node CA certificates require action %q", actionCACerts))
+	}
+	// the certificates must be trusted before kubeadm init pulls images
+	if seen[actionCACerts] && seen[actionKubeadmInit] && !before(names, actionCACerts, actionKubeadmInit) {
+		errs = append(errs, errors.Errorf("action %q must come before action %q", actionCACerts, actionKubeadmInit))
+	}
+	if seen[actionKonnectivity] && !opts.Konnectivity {
+		errs = append(errs, errors.Errorf("action %q requires the konnectivity option", actionKonnectivity))
+	}
+	if seen[actionRegistry] && !opts.LocalRegistry {
+		errs = append(errs, errors.Errorf("action %q requires the local registry option", actionRegistry))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	yaml "gopkg.in/yaml.v3"
+	kubeyaml "sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kind/pkg/apis/config/v1alpha4"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config/encoding"
+)
+
+// supported ClusterOptions.PlanOutput formats
+const (
+	planOutputJSON = "json"
+	planOutputYAML = "yaml"
+)
+
+// clusterPlan is what would be created, see ClusterOptions.PlanOutput
+type clusterPlan struct {
+	// Config is the cluster config after defaulting and resolving options
+	Config *v1alpha4.Cluster `json:"config" yaml:"config"`
+	// Actions are the names of the actions to run after creating the nodes
+	Actions []string `json:"actions" yaml:"actions"`
+	// APIServer is where the API server will be published on the host
+	APIServer plannedPort `json:"apiServer" yaml:"apiServer"`
+	// Nodes are the nodes to create, in order
+	Nodes []plannedNode `json:"nodes" yaml:"nodes"`
+}
+
+// plannedNode is a node in clusterPlan
+type plannedNode struct {
+	Name  string        `json:"name" yaml:"name"`
+	Role  string        `json:"role" yaml:"role"`
+	Image string        `json:"image" yaml:"image"`
+	Ports []plannedPort `json:"ports,omitempty" yaml:"ports,omitempty"`
+}
+
+// plannedPort is a port published on the host, a HostPort of zero is
+// picked when creating the cluster
+type plannedPort struct {
+	ListenAddress string `json:"listenAddress" yaml:"listenAddress"`
+	HostPort      int32  `json:"hostPort" yaml:"hostPort"`
+	ContainerPort int32  `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
+	Protocol      string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+}
+
+// validatePlanOutput returns an error if format is not a supported
+// ClusterOptions.PlanOutput format
+func validatePlanOutput(format string) error {
+	switch format {
+	case "", planOutputJSON, planOutputYAML:
+		return nil
+	}
+	return errors.Errorf("invalid plan output %q: must be %q or %q", format, planOutputJSON, planOutputYAML)
+}
+
+// writePlan writes the plan for creating the cluster from the fixed up and
+// validated opts and the planned actions to w in opts.PlanOutput format
+func writePlan(w io.Writer, opts *ClusterOptions, planned []namedAction) error {
+	plan := clusterPlan{
+		Config:  encoding.InternalToV1Alpha4(opts.Config),
+		Actions: make([]string, 0, len(planned)),
+		APIServer: plannedPort{
+			ListenAddress: opts.Config.Networking.APIServerAddress,
+			HostPort:      opts.Config.Networking.APIServerPort,
+		},
+		Nodes: make([]plannedNode, 0, len(opts.Config.Nodes)),
+	}
+	for _, a := range planned {
+		plan.Actions = append(plan.Actions, a.name)
+	}
+	nodeNamer, err := common.MakeTemplateNodeNamer(opts.Config.Name, opts.NodeNameTemplate)
+	if err != nil {
+		return err
+	}
+	for _, n := range opts.Config.Nodes {
+		name, err := nodeNamer(string(n.Role))
+		if err != nil {
+			return err
+		}
+		node := plannedNode{
+			Name:  name,
+			Role:  string(n.Role),
+			Image: n.Image,
+		}
+		for _, pm := range n.ExtraPortMappings {
+			node.Ports = append(node.Ports, plannedPort{
+				ListenAddress: pm.ListenAddress,
+				HostPort:      pm.HostPort,
+				ContainerPort: pm.ContainerPort,
+				Protocol:      string(pm.Protocol),
+			})
+		}
+		plan.Nodes = append(plan.Nodes, node)
+	}
+
+	// the config API types are only tagged for YAML, so JSON is converted
+	b, err := yaml.Marshal(plan)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plan")
+	}
+	if opts.PlanOutput == planOutputJSON {
+		j, err := kubeyaml.YAMLToJSON(b)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode plan")
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, j, "", "  "); err != nil {
+			return errors.Wrap(err, "failed to encode plan")
+		}
+		b = append(out.Bytes(), '\n')
+	}
+	_, err = w.Write(b)
+	return err
+}
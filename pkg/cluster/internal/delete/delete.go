@@ -17,6 +17,8 @@ limitations under the License.
 package delete
 
 import (
+	"time"
+
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
@@ -28,11 +30,53 @@ import (
 // explicitKubeconfigPath is --kubeconfig, following the rules from
 // https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands
 func Cluster(logger log.Logger, p providers.Provider, name, explicitKubeconfigPath string) error {
+	return ClusterWithOptions(logger, p, name, explicitKubeconfigPath, ClusterOptions{})
+}
+
+// ClusterOptions are options for ClusterWithOptions
+type ClusterOptions struct {
+	// GracefulDelete cordons and drains the nodes and waits for volumes to be
+	// detached before deleting them, so finalizers and CSI detach can run
+	GracefulDelete bool
+	// GracefulTimeout bounds GracefulDelete, after which the nodes are
+	// deleted anyway, if zero DefaultGracefulTimeout is used
+	GracefulTimeout time.Duration
+	// SnapshotEtcdBeforeDelete is a host path to save a snapshot of etcd to before
+	// deleting the cluster, if set the cluster is not deleted if this fails
+	SnapshotEtcdBeforeDelete string
+}
+
+// ClusterWithOptions deletes the cluster identified by ctx like Cluster per opts
+func ClusterWithOptions(logger log.Logger, p providers.Provider, name, explicitKubeconfigPath string, opts ClusterOptions) error {
+	if opts.GracefulTimeout < 0 {
+		return errors.Errorf("invalid graceful delete timeout %s: must not be negative", opts.GracefulTimeout)
+	}
 	n, err := p.ListNodes(name)
 	if err != nil {
 		return errors.Wrap(err, "error listing nodes")
 	}
 
+	if opts.SnapshotEtcdBeforeDelete != "" {
+		logger.V(0).Infof("Saving etcd snapshot for cluster %q ...", name)
+		size, err := snapshotEtcd(n, opts.SnapshotEtcdBeforeDelete)
+		if err != nil {
+			return errors.Wrapf(err, "failed to snapshot etcd for cluster %q", name)
+		}
+		logger.V(0).Infof("Saved etcd snapshot (%d bytes) to %s", size, opts.SnapshotEtcdBeforeDelete)
+	}
+
+	if opts.GracefulDelete && len(n) > 0 {
+		timeout := opts.GracefulTimeout
+		if timeout == 0 {
+			timeout = DefaultGracefulTimeout
+		}
+		logger.V(0).Infof("Draining nodes for cluster %q ...", name)
+		// fall back to deleting the nodes regardless
+		if err := drain(n, timeout); err != nil {
+			logger.Warnf("WARNING: failed to gracefully drain cluster %q, deleting it anyway: %v", name, err)
+		}
+	}
+
 	kerr := kubeconfig.Remove(name, explicitKubeconfigPath)
 	if kerr != nil {
 		logger.Errorf("failed to update kubeconfig: %v", kerr)
@@ -42,6 +86,14 @@ func Cluster(logger log.Logger, p providers.Provider, name, explicitKubeconfigPa
 	if err != nil {
 		return err
 	}
+	// remove the cluster's local registry, if kind created one
+	if err := p.DeleteLocalRegistry(name); err != nil {
+		return err
+	}
+	// remove the shared image caches only this cluster used
+	if err := p.DeleteUnusedImageCaches(); err != nil {
+		return err
+	}
 	if kerr != nil {
 		return err
 	}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// DefaultGracefulTimeout is how long the nodes are drained for before they
+// are deleted anyway if ClusterOptions.GracefulTimeout is not set
+const DefaultGracefulTimeout = 2 * time.Minute
+
+// drain cordons and drains all of the cluster's Kubernetes nodes and then
+// waits for any volumes to be detached, giving up after timeout
+func drain(allNodes []nodes.Node, timeout time.Duration) error {
+	controlPlanes, err := nodeutils.ControlPlaneNodes(allNodes)
+	if err != nil {
+		return err
+	}
+	if len(controlPlanes) < 1 {
+		return errors.New("could not locate any control plane nodes")
+	}
+	node := controlPlanes[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Kubernetes node names may differ from the containers, ask the cluster
+	names, err := exec.OutputLines(node.CommandContext(ctx,
+		"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+		"get", "nodes", "-o=jsonpath={range .items[*]}{.metadata.name}{\"\\n\"}{end}",
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+	args := []string{
+		"--kubeconfig=/etc/kubernetes/admin.conf", "drain",
+		"--ignore-daemonsets", "--force", "--delete-local-data",
+		"--timeout=" + timeout.String(),
+	}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			args = append(args, name)
+		}
+	}
+	if err := node.CommandContext(ctx, "kubectl", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to drain nodes")
+	}
+
+	// pods are gone, but CSI drivers detach their volumes asynchronously
+	for {
+		attachments, err := exec.OutputLines(node.CommandContext(ctx,
+			"kubectl", "--kubeconfig=/etc/kubernetes/admin.conf",
+			"get", "volumeattachments", "-o=name",
+		))
+		if err != nil {
+			return errors.Wrap(err, "failed to list volume attachments")
+		}
+		if len(attachments) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("timed out waiting for %d volume attachment(s) to be removed", len(attachments))
+		case <-time.After(time.Second):
+		}
+	}
+}
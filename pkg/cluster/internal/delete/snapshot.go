@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+	"sigs.k8s.io/kind/pkg/cluster/nodes"
+	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// etcdSnapshotPath is where the snapshot is saved in the etcd container,
+// this is in the etcd data dir so it is also on the node
+const etcdSnapshotPath = "/var/lib/etcd/kind-snapshot.db"
+
+// snapshotEtcd saves a snapshot of etcd on the bootstrap control plane node
+// to path on the host, returning the size of the snapshot in bytes
+func snapshotEtcd(allNodes []nodes.Node, path string) (int64, error) {
+	node, err := nodeutils.BootstrapControlPlaneNode(allNodes)
+	if err != nil {
+		return 0, err
+	}
+
+	// etcdctl ships in the etcd image rather than the node image
+	lines, err := exec.OutputLines(node.Command("crictl", "ps", "-q", "--name=^etcd$"))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to find the etcd container")
+	}
+	if len(lines) < 1 || strings.TrimSpace(lines[0]) == "" {
+		return 0, errors.Errorf("no etcd container running on node %s", node.String())
+	}
+	if err := node.Command(
+		"crictl", "exec", strings.TrimSpace(lines[0]),
+		"etcdctl",
+		"--endpoints=https://127.0.0.1:2379",
+		"--cacert=/etc/kubernetes/pki/etcd/ca.crt",
+		"--cert=/etc/kubernetes/pki/etcd/server.crt",
+		"--key=/etc/kubernetes/pki/etcd/server.key",
+		"snapshot", "save", etcdSnapshotPath,
+	).Run(); err != nil {
+		return 0, errors.Wrap(err, "failed to save etcd snapshot")
+	}
+	defer func() {
+		_ = node.Command("rm", "-f", etcdSnapshotPath).Run()
+	}()
+
+	// copy the snapshot out of the node
+	f, err := common.FileOnHost(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if err := node.Command("cat", etcdSnapshotPath).SetStdout(f).Run(); err != nil {
+		return 0, errors.Wrap(err, "failed to copy etcd snapshot")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
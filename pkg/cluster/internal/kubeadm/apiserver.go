@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// APIServerTuning sets API server flags for scale and admission testing,
+// zero values leave the API server defaults
+// NOTE: extreme values may destabilize the control plane, they are allowed
+// so that such behavior can be tested
+type APIServerTuning struct {
+	// RequestTimeout is --request-timeout
+	RequestTimeout time.Duration
+	// DefaultWatchCacheSize is --default-watch-cache-size if non-nil,
+	// zero disables the watch cache for resources without a WatchCacheSizes entry
+	DefaultWatchCacheSize *int32
+	// WatchCacheSizes is --watch-cache-sizes, keyed by resource[.group]
+	WatchCacheSizes map[string]int32
+	// EnableAdmissionPlugins is --enable-admission-plugins
+	EnableAdmissionPlugins []string
+	// DisableAdmissionPlugins is --disable-admission-plugins, this may only
+	// contain RequiredAdmissionPlugins if ForceDisableAdmissionPlugins is set
+	DisableAdmissionPlugins      []string
+	ForceDisableAdmissionPlugins bool
+}
+
+// RequiredAdmissionPlugins are the admission plugins kind clusters rely on,
+// they are not disabled unless forced, see APIServerTuning
+var RequiredAdmissionPlugins = []string{
+	"NodeRestriction", // the kubelets are registered with node credentials
+	"ServiceAccount",  // the default CNI and storage use service account tokens
+}
+
+// PodSecurityAdmissionPlugins are the admission plugins enabled along with
+// ConfigData.PodSecurityConfig
+var PodSecurityAdmissionPlugins = []string{"NodeRestriction", "PodSecurity"}
+
+// IsZero returns true if t does not change any of the defaults
+func (t APIServerTuning) IsZero() bool {
+	return t.RequestTimeout == 0 && t.DefaultWatchCacheSize == nil && len(t.WatchCacheSizes) == 0 &&
+		len(t.EnableAdmissionPlugins) == 0 && len(t.DisableAdmissionPlugins) == 0
+}
+
+// admissionPluginRE matches admission plugin names, E.G. NodeRestriction
+var admissionPluginRE = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// watchCacheResourceRE matches --watch-cache-sizes resources, E.G. pods or
+// deployments.apps
+var watchCacheResourceRE = regexp.MustCompile(`^[a-z0-9]+(\.[a-z0-9]([-a-z0-9.]*[a-z0-9])?)?$`)
+
+// Validate returns an error if t contains values the API server will reject
+func (t APIServerTuning) Validate() error {
+	errs := []error{}
+	if t.RequestTimeout < 0 {
+		errs = append(errs, errors.Errorf("invalid API server request timeout %s: must not be negative", t.RequestTimeout))
+	}
+	if t.DefaultWatchCacheSize != nil && *t.DefaultWatchCacheSize < 0 {
+		errs = append(errs, errors.Errorf("invalid API server default watch cache size %d: must not be negative", *t.DefaultWatchCacheSize))
+	}
+	for resource, size := range t.WatchCacheSizes {
+		if !watchCacheResourceRE.MatchString(resource) {
+			errs = append(errs, errors.Errorf("invalid API server watch cache resource %q: must be of the form resource[.group]", resource))
+		}
+		if size < 0 {
+			errs = append(errs, errors.Errorf("invalid API server watch cache size %d for %s: must not be negative", size, resource))
+		}
+	}
+	enabled := make(map[string]bool, len(t.EnableAdmissionPlugins))
+	for _, plugin := range t.EnableAdmissionPlugins {
+		if !admissionPluginRE.MatchString(plugin) {
+			errs = append(errs, errors.Errorf("invalid admission plugin %q: must match `%s`", plugin, admissionPluginRE.String()))
+		}
+		enabled[plugin] = true
+	}
+	required := make(map[string]bool, len(RequiredAdmissionPlugins))
+	for _, plugin := range RequiredAdmissionPlugins {
+		required[plugin] = true
+	}
+	for _, plugin := range t.DisableAdmissionPlugins {
+		switch {
+		case !admissionPluginRE.MatchString(plugin):
+			errs = append(errs, errors.Errorf("invalid admission plugin %q: must match `%s`", plugin, admissionPluginRE.String()))
+		case enabled[plugin]:
+			errs = append(errs, errors.Errorf("invalid admission plugin %q: cannot be both enabled and disabled", plugin))
+		case required[plugin] && !t.ForceDisableAdmissionPlugins:
+			errs = append(errs, errors.Errorf("invalid admission plugin %q: kind clusters rely on it, it can only be disabled if forced", plugin))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// ExtraArgs returns the API server flags for t, without the leading --
+func (t APIServerTuning) ExtraArgs() map[string]string {
+	if t.IsZero() {
+		return nil
+	}
+	args := map[string]string{}
+	if t.RequestTimeout > 0 {
+		args["request-timeout"] = t.RequestTimeout.String()
+	}
+	if t.DefaultWatchCacheSize != nil {
+		args["default-watch-cache-size"] = fmt.Sprintf("%d", *t.DefaultWatchCacheSize)
+	}
+	if len(t.WatchCacheSizes) > 0 {
+		sizes := make([]string, 0, len(t.WatchCacheSizes))
+		for resource, size := range t.WatchCacheSizes {
+			sizes = append(sizes, fmt.Sprintf("%s#%d", resource, size))
+		}
+		sort.Strings(sizes)
+		args["watch-cache-sizes"] = strings.Join(sizes, ",")
+	}
+	if len(t.EnableAdmissionPlugins) > 0 {
+		args["enable-admission-plugins"] = joinUnique(t.EnableAdmissionPlugins)
+	}
+	if len(t.DisableAdmissionPlugins) > 0 {
+		args["disable-admission-plugins"] = joinUnique(t.DisableAdmissionPlugins)
+	}
+	return args
+}
+
+// joinUnique joins values with commas, in order, skipping duplicates
+func joinUnique(values []string) string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return strings.Join(unique, ",")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestAPIServerTuning(t *testing.T) {
+	t.Parallel()
+	zero, negative := int32(0), int32(-1)
+	assert.ExpectError(t, false, APIServerTuning{}.Validate())
+	assert.ExpectError(t, false, APIServerTuning{
+		RequestTimeout:        time.Hour,
+		DefaultWatchCacheSize: &zero,
+		WatchCacheSizes:       map[string]int32{"pods": 1000, "deployments.apps": 0},
+	}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{RequestTimeout: -time.Second}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{DefaultWatchCacheSize: &negative}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{WatchCacheSizes: map[string]int32{"pods": -1}}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{WatchCacheSizes: map[string]int32{"pods#100": 100}}.Validate())
+
+	assert.DeepEqual(t, map[string]string(nil), APIServerTuning{}.ExtraArgs())
+	assert.DeepEqual(t, map[string]string{
+		"request-timeout":          "1m30s",
+		"default-watch-cache-size": "0",
+		"watch-cache-sizes":        "deployments.apps#50,pods#1000",
+	}, APIServerTuning{
+		RequestTimeout:        90 * time.Second,
+		DefaultWatchCacheSize: &zero,
+		WatchCacheSizes:       map[string]int32{"pods": 1000, "deployments.apps": 50},
+	}.ExtraArgs())
+}
+
+func TestAPIServerAdmissionPlugins(t *testing.T) {
+	t.Parallel()
+	assert.ExpectError(t, false, APIServerTuning{
+		EnableAdmissionPlugins:  []string{"ValidatingAdmissionWebhook", "PodSecurity"},
+		DisableAdmissionPlugins: []string{"DefaultStorageClass"},
+	}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{EnableAdmissionPlugins: []string{"node-restriction"}}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{
+		EnableAdmissionPlugins:  []string{"PodSecurity"},
+		DisableAdmissionPlugins: []string{"PodSecurity"},
+	}.Validate())
+	assert.ExpectError(t, true, APIServerTuning{DisableAdmissionPlugins: []string{"NodeRestriction"}}.Validate())
+	assert.ExpectError(t, false, APIServerTuning{
+		DisableAdmissionPlugins:      []string{"NodeRestriction"},
+		ForceDisableAdmissionPlugins: true,
+	}.Validate())
+
+	assert.DeepEqual(t, map[string]string{
+		"enable-admission-plugins":  "NodeRestriction,PodSecurity,ValidatingAdmissionWebhook",
+		"disable-admission-plugins": "DefaultStorageClass",
+	}, APIServerTuning{
+		EnableAdmissionPlugins:  []string{"NodeRestriction", "PodSecurity", "ValidatingAdmissionWebhook", "PodSecurity"},
+		DisableAdmissionPlugins: []string{"DefaultStorageClass"},
+	}.ExtraArgs())
+}
@@ -19,6 +19,7 @@ package kubeadm
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 	"text/template"
@@ -39,6 +40,8 @@ type ConfigData struct {
 	APIBindPort int
 	// The API server external listen IP (which we will port forward)
 	APIServerAddress string
+	// ExtraCertSANs are additional API server certificate SANs
+	ExtraCertSANs []string
 
 	// this should really be used for the --provider-id flag
 	// ideally cluster config should not depend on the node backend otherwise ...
@@ -48,11 +51,19 @@ type ConfigData struct {
 	ControlPlane bool
 	// The main IP address of the node
 	NodeAddress string
+	// The address the bootstrap control plane node's API server advertises,
+	// if unset NodeAddress is used
+	AdvertiseAddress string
 	// The name for the node (not the address)
 	NodeName string
+	// The hostname of the node, registered as the Kubernetes node name if set
+	NodeHostname string
 
 	// The Token for TLS bootstrap
 	Token string
+	// TokenTTL is the lifetime of Token as a duration string, if unset the
+	// kubeadm default is used
+	TokenTTL string
 
 	// KubeProxyMode defines the kube-proxy mode between iptables or ipvs
 	KubeProxyMode string
@@ -60,6 +71,9 @@ type ConfigData struct {
 	PodSubnet string
 	// The subnet used for services
 	ServiceSubnet string
+	// The DNS domain for services, if unset the kubeadm default
+	// (cluster.local) is used
+	DNSDomain string
 
 	// Kubernetes FeatureGates
 	FeatureGates map[string]bool
@@ -70,6 +84,71 @@ type ConfigData struct {
 	// IPv4 values take precedence over IPv6 by default, if true set IPv6 default values
 	IPv6 bool
 
+	// KubeletConfigAPIVersion is the apiVersion of the KubeletConfiguration,
+	// if unset it is detected from KubernetesVersion, see
+	// KubeletConfigAPIVersionFor
+	KubeletConfigAPIVersion string
+
+	// Kubelet image garbage collection thresholds, if zero
+	// imageGCHighThresholdPercent is 100 and imageGCLowThresholdPercent
+	// is the kubelet default
+	ImageGCHighThresholdPercent int32
+	ImageGCLowThresholdPercent  int32
+
+	// KubeReserved and SystemReserved are the kubelet's kubeReserved and
+	// systemReserved resources by name, if set
+	KubeReserved   map[string]string
+	SystemReserved map[string]string
+
+	// ShutdownGracePeriod and ShutdownGracePeriodCriticalPods are the
+	// kubelet's graceful node shutdown periods, if set, see GracefulShutdown
+	ShutdownGracePeriod             string
+	ShutdownGracePeriodCriticalPods string
+
+	// CloudProvider is the --cloud-provider for the kubelet and
+	// kube-controller-manager, if set it must be CloudProviderExternal
+	CloudProvider string
+
+	// NodeCIDRMaskSize is the kube-controller-manager's --node-cidr-mask-size,
+	// if zero the kube-controller-manager default is used
+	NodeCIDRMaskSize int32
+
+	// KubeletRootDir is the kubelet's --root-dir, if unset the kubelet
+	// default (/var/lib/kubelet) is used
+	KubeletRootDir string
+
+	// NodeLabels are registered by the kubelet along with the node
+	NodeLabels map[string]string
+
+	// SchedulableControlPlane registers control plane nodes without the
+	// NoSchedule taint
+	SchedulableControlPlane bool
+
+	// APIServerExtraArgs are additional API server flags, without the
+	// leading --, see APIServerTuning
+	APIServerExtraArgs map[string]string
+
+	// PodSecurityConfig configures the API server's PodSecurity admission
+	// plugin with the config at PodSecurityConfigPath, which must be
+	// written to the control plane nodes before kubeadm runs, the plugin
+	// must also be enabled with APIServerExtraArgs
+	PodSecurityConfig bool
+
+	// OIDCCA mounts OIDCConfigDir into the API server, the CA at
+	// OIDCCAPath must be written to the control plane nodes before kubeadm
+	// runs and passed with APIServerExtraArgs
+	OIDCCA bool
+
+	// Konnectivity routes the API server's egress to the cluster through the
+	// konnectivity server with the config at EgressSelectorConfigPath, which
+	// must be written to the control plane nodes before kubeadm runs
+	Konnectivity bool
+
+	// SchedulerConfig configures the kube-scheduler with the config at
+	// SchedulerConfigPath, which must be written to the control plane nodes
+	// before kubeadm runs
+	SchedulerConfig bool
+
 	// DerivedConfigData is populated by Derive()
 	// These auto-generated fields are available to Config templates,
 	// but not meant to be set by hand
@@ -87,6 +166,8 @@ type DerivedConfigData struct {
 	FeatureGatesString string
 	// RuntimeConfigString is of the form `Foo=true,Baz=false`
 	RuntimeConfigString string
+	// NodeLabelsString is of the form `foo=bar,baz=qux`
+	NodeLabelsString string
 }
 
 // Derive automatically derives DockerStableTag if not specified
@@ -95,6 +176,11 @@ func (c *ConfigData) Derive() {
 		c.DockerStableTag = strings.Replace(c.KubernetesVersion, "+", "_", -1)
 	}
 
+	// detect the KubeletConfiguration apiVersion if not specified
+	if c.KubeletConfigAPIVersion == "" {
+		c.KubeletConfigAPIVersion = KubeletConfigAPIVersionFor(c.KubernetesVersion)
+	}
+
 	// get sorted list of FeatureGate keys
 	featureGateKeys := make([]string, 0, len(c.FeatureGates))
 	for k := range c.FeatureGates {
@@ -111,6 +197,14 @@ func (c *ConfigData) Derive() {
 	}
 	c.FeatureGatesString = strings.Join(featureGates, ",")
 
+	// create a sorted key=value,... string of NodeLabels
+	nodeLabels := make([]string, 0, len(c.NodeLabels))
+	for k, v := range c.NodeLabels {
+		nodeLabels = append(nodeLabels, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(nodeLabels)
+	c.NodeLabelsString = strings.Join(nodeLabels, ",")
+
 	// create a sorted key=value,... string of RuntimeConfig
 	// first get sorted list of FeatureGate keys
 	runtimeConfigKeys := make([]string, 0, len(c.RuntimeConfig))
@@ -129,6 +223,134 @@ func (c *ConfigData) Derive() {
 	c.RuntimeConfigString = strings.Join(runtimeConfig, ",")
 }
 
+// kubeletConfigAPIVersions maps the known KubeletConfiguration apiVersions,
+// from newest to oldest, to the minimum Kubernetes version that supports them
+//
+// NOTE: v1beta1 is the only KubeletConfiguration apiVersion released so far,
+// so detection always picks it and an override can only be validated, this
+// is where newer apiVersions go once the kubelet serves them.
+var kubeletConfigAPIVersions = []struct {
+	apiVersion        string
+	minKubeletVersion string
+}{
+	{apiVersion: "kubelet.config.k8s.io/v1beta1", minKubeletVersion: "v1.10.0"},
+}
+
+// kube-proxy modes which are not supported by every Kubernetes version kind
+// supports, mapped to the minimum version supporting them by default,
+// and optionally the minimum version and feature gate to support them earlier
+var kubeProxyModeVersions = map[string]struct {
+	minVersion            string
+	minFeatureGateVersion string
+	featureGate           string
+}{
+	"nftables": {minVersion: "v1.31.0", minFeatureGateVersion: "v1.29.0", featureGate: "NFTablesProxyMode"},
+}
+
+// ValidateKubeProxyMode returns an error if the kube-proxy mode is not
+// supported by kubernetesVersion with featureGates
+func ValidateKubeProxyMode(mode, kubernetesVersion string, featureGates map[string]bool) error {
+	v, gated := kubeProxyModeVersions[mode]
+	if !gated {
+		return nil
+	}
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return err
+	}
+	if ver.AtLeast(version.MustParseGeneric(v.minVersion)) {
+		return nil
+	}
+	if ver.AtLeast(version.MustParseGeneric(v.minFeatureGateVersion)) {
+		if featureGates[v.featureGate] {
+			return nil
+		}
+		return errors.Errorf("kube-proxy mode %q requires the %s feature gate with Kubernetes %s", mode, v.featureGate, kubernetesVersion)
+	}
+	return errors.Errorf("kube-proxy mode %q requires Kubernetes %s or newer, got %s", mode, v.minFeatureGateVersion, kubernetesVersion)
+}
+
+// CloudProviderExternal is the only supported ConfigData.CloudProvider, it
+// configures the cluster for an external cloud-controller-manager.
+// The kubelet will register each node with the
+// node.cloudprovider.kubernetes.io/uninitialized taint, to be removed by the
+// cloud-controller-manager once it has initialized the node.
+const CloudProviderExternal = "external"
+
+// ValidateCloudProvider returns an error if cloudProvider is not supported
+func ValidateCloudProvider(cloudProvider string) error {
+	if cloudProvider != CloudProviderExternal {
+		return errors.Errorf("unsupported cloud provider %q, the only supported cloud provider is %q", cloudProvider, CloudProviderExternal)
+	}
+	return nil
+}
+
+// validTokenRE matches kubeadm bootstrap tokens
+var validTokenRE = regexp.MustCompile(`^[a-z0-9]{6}\.[a-z0-9]{16}$`)
+
+// ValidateToken returns an error if token is not a valid bootstrap token
+func ValidateToken(token string) error {
+	if !validTokenRE.MatchString(token) {
+		return errors.Errorf("invalid bootstrap token: must match `%s`", validTokenRE.String())
+	}
+	return nil
+}
+
+// maxDNSDomainLength is the maximum length of a DNS-1123 subdomain
+const maxDNSDomainLength = 253
+
+// validDNSDomainRE matches DNS-1123 subdomains
+var validDNSDomainRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?(\.[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?)*$`)
+
+// ValidateDNSDomain returns an error if dnsDomain is not a valid cluster
+// DNS domain, which must be a DNS-1123 subdomain
+func ValidateDNSDomain(dnsDomain string) error {
+	if len(dnsDomain) > maxDNSDomainLength || !validDNSDomainRE.MatchString(dnsDomain) {
+		return errors.Errorf("invalid DNS domain %q: must be a valid DNS-1123 subdomain of at most %d characters", dnsDomain, maxDNSDomainLength)
+	}
+	return nil
+}
+
+// KubeletConfigAPIVersionFor returns the newest KubeletConfiguration
+// apiVersion supported by kubernetesVersion, or the oldest known apiVersion
+// if kubernetesVersion cannot be parsed or is unsupported
+func KubeletConfigAPIVersionFor(kubernetesVersion string) string {
+	oldest := kubeletConfigAPIVersions[len(kubeletConfigAPIVersions)-1].apiVersion
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return oldest
+	}
+	for _, v := range kubeletConfigAPIVersions {
+		if ver.AtLeast(version.MustParseGeneric(v.minKubeletVersion)) {
+			return v.apiVersion
+		}
+	}
+	return oldest
+}
+
+// ValidateKubeletConfigAPIVersion returns an error if apiVersion is not a
+// known KubeletConfiguration apiVersion, or if kubernetesVersion is non-empty
+// and does not support apiVersion
+func ValidateKubeletConfigAPIVersion(apiVersion, kubernetesVersion string) error {
+	for _, v := range kubeletConfigAPIVersions {
+		if v.apiVersion != apiVersion {
+			continue
+		}
+		if kubernetesVersion == "" {
+			return nil
+		}
+		ver, err := version.ParseGeneric(kubernetesVersion)
+		if err != nil {
+			return err
+		}
+		if ver.LessThan(version.MustParseGeneric(v.minKubeletVersion)) {
+			return errors.Errorf("KubeletConfiguration %s requires Kubernetes %s or newer, got %s", apiVersion, v.minKubeletVersion, kubernetesVersion)
+		}
+		return nil
+	}
+	return errors.Errorf("unknown KubeletConfiguration apiVersion %q", apiVersion)
+}
+
 // See docs for these APIs at:
 // https://godoc.org/k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm#pkg-subdirectories
 // EG:
@@ -147,18 +369,35 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
-  certSANs: [localhost, "{{.APIServerAddress}}"]
+  certSANs: [localhost, "{{.APIServerAddress}}"{{ range .ExtraCertSANs }}, "{{ . }}"{{ end }}]
   extraArgs:
     "runtime-config": "{{ .RuntimeConfigString }}"
 {{ if .FeatureGates }}
     "feature-gates": "{{ .FeatureGatesString }}"
 {{ end}}
+{{ range $key, $value := .APIServerExtraArgs }}
+    "{{ $key }}": "{{ $value }}"
+{{ end }}
+{{ if .OIDCCA }}
+  extraVolumes:
+  - name: oidc-ca
+    hostPath: "` + OIDCConfigDir + `"
+    mountPath: "` + OIDCConfigDir + `"
+    readOnly: true
+    pathType: Directory
+{{ end }}
 controllerManager:
 {{ if .FeatureGates }}
   extraArgs:
     "feature-gates": "{{ .FeatureGatesString }}"
 {{ end}}
     enable-hostpath-provisioner: "true"
+{{ if .CloudProvider }}
+    cloud-provider: "{{ .CloudProvider }}"
+{{ end }}
+{{ if .NodeCIDRMaskSize }}
+    node-cidr-mask-size: "{{ .NodeCIDRMaskSize }}"
+{{ end }}
     # configure ipv6 default addresses for IPv6 clusters
     {{ if .IPv6 -}}
     bind-address: "::"
@@ -176,6 +415,9 @@ scheduler:
 networking:
   podSubnet: "{{ .PodSubnet }}"
   serviceSubnet: "{{ .ServiceSubnet }}"
+{{ if .DNSDomain }}
+  dnsDomain: "{{ .DNSDomain }}"
+{{ end }}
 ---
 apiVersion: kubeadm.k8s.io/v1beta1
 kind: InitConfiguration
@@ -184,17 +426,35 @@ metadata:
 # we use a well know token for TLS bootstrap
 bootstrapTokens:
 - token: "{{ .Token }}"
+{{ if .TokenTTL }}
+  ttl: "{{ .TokenTTL }}"
+{{ end }}
 # we use a well know port for making the API server discoverable inside docker network. 
 # from the host machine such port will be accessible via a random local port instead.
 localAPIEndpoint:
-  advertiseAddress: "{{ .NodeAddress }}"
+  advertiseAddress: "{{ or .AdvertiseAddress .NodeAddress }}"
   bindPort: {{.APIBindPort}}
 nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
+{{ if and .ControlPlane .SchedulableControlPlane }}
+  taints: []
+{{ end }}
+{{ if .NodeHostname }}
+  name: "{{ .NodeHostname }}"
+{{ end }}
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
     provider-id: "kind://{{.NodeProvider}}/{{.ClusterName}}/{{.NodeName}}"
+{{ if .CloudProvider }}
+    cloud-provider: "{{ .CloudProvider }}"
+{{ end }}
+{{ if .KubeletRootDir }}
+    root-dir: "{{ .KubeletRootDir }}"
+{{ end }}
+{{ if .NodeLabels }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{ end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeadm.k8s.io/v1beta1
@@ -209,17 +469,32 @@ controlPlane:
 {{- end }}
 nodeRegistration:
   criSocket: "/run/containerd/containerd.sock"
+{{ if and .ControlPlane .SchedulableControlPlane }}
+  taints: []
+{{ end }}
+{{ if .NodeHostname }}
+  name: "{{ .NodeHostname }}"
+{{ end }}
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
     provider-id: "kind://{{.NodeProvider}}/{{.ClusterName}}/{{.NodeName}}"
+{{ if .CloudProvider }}
+    cloud-provider: "{{ .CloudProvider }}"
+{{ end }}
+{{ if .KubeletRootDir }}
+    root-dir: "{{ .KubeletRootDir }}"
+{{ end }}
+{{ if .NodeLabels }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{ end }}
 discovery:
   bootstrapToken:
     apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
     token: "{{ .Token }}"
     unsafeSkipCAVerification: true
 ---
-apiVersion: kubelet.config.k8s.io/v1beta1
+apiVersion: {{ .KubeletConfigAPIVersion }}
 kind: KubeletConfiguration
 metadata:
   name: config
@@ -231,11 +506,35 @@ healthzBindAddress: "::"
 # disable disk resource management by default
 # kubelet will see the host disk that the inner container runtime
 # is ultimately backed by and attempt to recover disk space. we don't want that.
-imageGCHighThresholdPercent: 100
+imageGCHighThresholdPercent: {{ or .ImageGCHighThresholdPercent 100 }}
+{{ if .ImageGCLowThresholdPercent -}}
+imageGCLowThresholdPercent: {{ .ImageGCLowThresholdPercent }}
+{{- end }}
+{{ if .DNSDomain -}}
+clusterDomain: "{{ .DNSDomain }}"
+{{- end }}
 evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ if .KubeReserved -}}
+kubeReserved:
+{{- range $key, $value := .KubeReserved }}
+  "{{ $key }}": "{{ $value }}"
+{{- end }}
+{{ end -}}
+{{ if .SystemReserved -}}
+systemReserved:
+{{- range $key, $value := .SystemReserved }}
+  "{{ $key }}": "{{ $value }}"
+{{- end }}
+{{ end -}}
+{{ if .ShutdownGracePeriod -}}
+shutdownGracePeriod: "{{ .ShutdownGracePeriod }}"
+{{ if .ShutdownGracePeriodCriticalPods -}}
+shutdownGracePeriodCriticalPods: "{{ .ShutdownGracePeriodCriticalPods }}"
+{{ end -}}
+{{ end -}}
 {{if .FeatureGates}}featureGates:
 {{ range $key := .SortedFeatureGateKeys }}
   "{{ $key }}": {{$.FeatureGates $key }}
@@ -267,18 +566,62 @@ controlPlaneEndpoint: "{{ .ControlPlaneEndpoint }}"
 # so we need to ensure the cert is valid for localhost so we can talk
 # to the cluster after rewriting the kubeconfig to point to localhost
 apiServer:
-  certSANs: [localhost, "{{.APIServerAddress}}"]
+  certSANs: [localhost, "{{.APIServerAddress}}"{{ range .ExtraCertSANs }}, "{{ . }}"{{ end }}]
   extraArgs:
     "runtime-config": "{{ .RuntimeConfigString }}"
 {{ if .FeatureGates }}
     "feature-gates": "{{ .FeatureGatesString }}"
 {{ end}}
+{{ range $key, $value := .APIServerExtraArgs }}
+    "{{ $key }}": "{{ $value }}"
+{{ end }}
+{{ if .PodSecurityConfig }}
+    "admission-control-config-file": "` + PodSecurityConfigPath + `"
+{{ end }}
+{{ if .Konnectivity }}
+    "egress-selector-config-file": "` + EgressSelectorConfigPath + `"
+{{ end }}
+{{ if or .PodSecurityConfig .OIDCCA .Konnectivity }}
+  extraVolumes:
+{{ end }}
+{{ if .PodSecurityConfig }}
+  - name: admission-config
+    hostPath: "` + AdmissionConfigDir + `"
+    mountPath: "` + AdmissionConfigDir + `"
+    readOnly: true
+    pathType: Directory
+{{ end }}
+{{ if .OIDCCA }}
+  - name: oidc-ca
+    hostPath: "` + OIDCConfigDir + `"
+    mountPath: "` + OIDCConfigDir + `"
+    readOnly: true
+    pathType: Directory
+{{ end }}
+{{ if .Konnectivity }}
+  - name: egress-selector-config
+    hostPath: "` + KonnectivityConfigDir + `"
+    mountPath: "` + KonnectivityConfigDir + `"
+    readOnly: true
+    pathType: Directory
+  - name: konnectivity-uds
+    hostPath: "` + KonnectivityUDSDir + `"
+    mountPath: "` + KonnectivityUDSDir + `"
+    readOnly: false
+    pathType: DirectoryOrCreate
+{{ end }}
 controllerManager:
   extraArgs:
 {{ if .FeatureGates }}
     "feature-gates": "{{ .FeatureGatesString }}"
 {{ end }}
     enable-hostpath-provisioner: "true"
+{{ if .CloudProvider }}
+    cloud-provider: "{{ .CloudProvider }}"
+{{ end }}
+{{ if .NodeCIDRMaskSize }}
+    node-cidr-mask-size: "{{ .NodeCIDRMaskSize }}"
+{{ end }}
     # configure ipv6 default addresses for IPv6 clusters
     {{ if .IPv6 -}}
     bind-address: "::"
@@ -293,9 +636,21 @@ scheduler:
     address: "::"
     bind-address: "::1"
     {{- end }}
+{{ if .SchedulerConfig }}
+    "config": "` + SchedulerConfigPath + `"
+  extraVolumes:
+  - name: scheduler-config
+    hostPath: "` + SchedulerConfigDir + `"
+    mountPath: "` + SchedulerConfigDir + `"
+    readOnly: true
+    pathType: Directory
+{{ end }}
 networking:
   podSubnet: "{{ .PodSubnet }}"
   serviceSubnet: "{{ .ServiceSubnet }}"
+{{ if .DNSDomain }}
+  dnsDomain: "{{ .DNSDomain }}"
+{{ end }}
 ---
 apiVersion: kubeadm.k8s.io/v1beta2
 kind: InitConfiguration
@@ -304,17 +659,35 @@ metadata:
 # we use a well know token for TLS bootstrap
 bootstrapTokens:
 - token: "{{ .Token }}"
+{{ if .TokenTTL }}
+  ttl: "{{ .TokenTTL }}"
+{{ end }}
 # we use a well know port for making the API server discoverable inside docker network. 
 # from the host machine such port will be accessible via a random local port instead.
 localAPIEndpoint:
-  advertiseAddress: "{{ .NodeAddress }}"
+  advertiseAddress: "{{ or .AdvertiseAddress .NodeAddress }}"
   bindPort: {{.APIBindPort}}
 nodeRegistration:
   criSocket: "unix:///run/containerd/containerd.sock"
+{{ if and .ControlPlane .SchedulableControlPlane }}
+  taints: []
+{{ end }}
+{{ if .NodeHostname }}
+  name: "{{ .NodeHostname }}"
+{{ end }}
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
     provider-id: "kind://{{.NodeProvider}}/{{.ClusterName}}/{{.NodeName}}"
+{{ if .CloudProvider }}
+    cloud-provider: "{{ .CloudProvider }}"
+{{ end }}
+{{ if .KubeletRootDir }}
+    root-dir: "{{ .KubeletRootDir }}"
+{{ end }}
+{{ if .NodeLabels }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{ end }}
 ---
 # no-op entry that exists solely so it can be patched
 apiVersion: kubeadm.k8s.io/v1beta2
@@ -329,17 +702,32 @@ controlPlane:
 {{- end }}
 nodeRegistration:
   criSocket: "unix:///run/containerd/containerd.sock"
+{{ if and .ControlPlane .SchedulableControlPlane }}
+  taints: []
+{{ end }}
+{{ if .NodeHostname }}
+  name: "{{ .NodeHostname }}"
+{{ end }}
   kubeletExtraArgs:
     fail-swap-on: "false"
     node-ip: "{{ .NodeAddress }}"
     provider-id: "kind://{{.NodeProvider}}/{{.ClusterName}}/{{.NodeName}}"
+{{ if .CloudProvider }}
+    cloud-provider: "{{ .CloudProvider }}"
+{{ end }}
+{{ if .KubeletRootDir }}
+    root-dir: "{{ .KubeletRootDir }}"
+{{ end }}
+{{ if .NodeLabels }}
+    node-labels: "{{ .NodeLabelsString }}"
+{{ end }}
 discovery:
   bootstrapToken:
     apiServerEndpoint: "{{ .ControlPlaneEndpoint }}"
     token: "{{ .Token }}"
     unsafeSkipCAVerification: true
 ---
-apiVersion: kubelet.config.k8s.io/v1beta1
+apiVersion: {{ .KubeletConfigAPIVersion }}
 kind: KubeletConfiguration
 metadata:
   name: config
@@ -351,11 +739,35 @@ healthzBindAddress: "::"
 # disable disk resource management by default
 # kubelet will see the host disk that the inner container runtime
 # is ultimately backed by and attempt to recover disk space. we don't want that.
-imageGCHighThresholdPercent: 100
+imageGCHighThresholdPercent: {{ or .ImageGCHighThresholdPercent 100 }}
+{{ if .ImageGCLowThresholdPercent -}}
+imageGCLowThresholdPercent: {{ .ImageGCLowThresholdPercent }}
+{{- end }}
+{{ if .DNSDomain -}}
+clusterDomain: "{{ .DNSDomain }}"
+{{- end }}
 evictionHard:
   nodefs.available: "0%"
   nodefs.inodesFree: "0%"
   imagefs.available: "0%"
+{{ if .KubeReserved -}}
+kubeReserved:
+{{- range $key, $value := .KubeReserved }}
+  "{{ $key }}": "{{ $value }}"
+{{- end }}
+{{ end -}}
+{{ if .SystemReserved -}}
+systemReserved:
+{{- range $key, $value := .SystemReserved }}
+  "{{ $key }}": "{{ $value }}"
+{{- end }}
+{{ end -}}
+{{ if .ShutdownGracePeriod -}}
+shutdownGracePeriod: "{{ .ShutdownGracePeriod }}"
+{{ if .ShutdownGracePeriodCriticalPods -}}
+shutdownGracePeriodCriticalPods: "{{ .ShutdownGracePeriodCriticalPods }}"
+{{ end -}}
+{{ end -}}
 {{if .FeatureGates}}featureGates:
 {{ range $key := .SortedFeatureGateKeys }}
   "{{ $key }}": {{ index $.FeatureGates $key }}
@@ -0,0 +1,204 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateKubeProxyMode(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name         string
+		Mode         string
+		Version      string
+		FeatureGates map[string]bool
+		ExpectError  bool
+	}{
+		{
+			Name:    "iptables",
+			Mode:    "iptables",
+			Version: "v1.16.0",
+		},
+		{
+			Name:    "nftables by default",
+			Mode:    "nftables",
+			Version: "v1.31.0",
+		},
+		{
+			Name:         "nftables with feature gate",
+			Mode:         "nftables",
+			Version:      "v1.29.2",
+			FeatureGates: map[string]bool{"NFTablesProxyMode": true},
+		},
+		{
+			Name:        "nftables without feature gate",
+			Mode:        "nftables",
+			Version:     "v1.29.2",
+			ExpectError: true,
+		},
+		{
+			Name:         "nftables too old",
+			Mode:         "nftables",
+			Version:      "v1.28.0",
+			FeatureGates: map[string]bool{"NFTablesProxyMode": true},
+			ExpectError:  true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateKubeProxyMode(tc.Mode, tc.Version, tc.FeatureGates))
+		})
+	}
+}
+
+func TestValidateDNSDomain(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		DNSDomain   string
+		ExpectError bool
+	}{
+		{
+			Name:      "default",
+			DNSDomain: "cluster.local",
+		},
+		{
+			Name:      "single label",
+			DNSDomain: "east",
+		},
+		{
+			Name:        "empty",
+			DNSDomain:   "",
+			ExpectError: true,
+		},
+		{
+			Name:        "uppercase",
+			DNSDomain:   "Cluster.local",
+			ExpectError: true,
+		},
+		{
+			Name:        "trailing dot",
+			DNSDomain:   "cluster.local.",
+			ExpectError: true,
+		},
+		{
+			Name:        "label too long",
+			DNSDomain:   strings.Repeat("a", 64) + ".local",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateDNSDomain(tc.DNSDomain))
+		})
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Token       string
+		ExpectError bool
+	}{
+		{Name: "well known token", Token: Token},
+		{Name: "uppercase", Token: "ABCDEF.0123456789abcdef", ExpectError: true},
+		{Name: "short secret", Token: "abcdef.0123456789", ExpectError: true},
+		{Name: "missing separator", Token: "abcdef0123456789abcdef", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateToken(tc.Token))
+		})
+	}
+}
+
+func TestValidatePodSecurityAdmission(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Version     string
+		ExpectError bool
+	}{
+		{Version: "v1.23.0"},
+		{Version: "v1.30.2"},
+		{Version: "v1.22.4", ExpectError: true},
+		{Version: "bogus", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidatePodSecurityAdmission(tc.Version))
+		})
+	}
+}
+
+func TestKubeletConfigAPIVersionFor(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Version  string
+		Expected string
+	}{
+		{Version: "v1.10.0", Expected: "kubelet.config.k8s.io/v1beta1"},
+		{Version: "v1.19.1", Expected: "kubelet.config.k8s.io/v1beta1"},
+		{Version: "v1.30.2", Expected: "kubelet.config.k8s.io/v1beta1"},
+		// unsupported and unparsable versions fall back to the oldest
+		{Version: "v1.9.11", Expected: "kubelet.config.k8s.io/v1beta1"},
+		{Version: "bogus", Expected: "kubelet.config.k8s.io/v1beta1"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, tc.Expected, KubeletConfigAPIVersionFor(tc.Version))
+		})
+	}
+}
+
+func TestValidateKubeletConfigAPIVersion(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		APIVersion  string
+		Version     string
+		ExpectError bool
+	}{
+		{Name: "known without version", APIVersion: "kubelet.config.k8s.io/v1beta1"},
+		{Name: "known with supported version", APIVersion: "kubelet.config.k8s.io/v1beta1", Version: "v1.19.1"},
+		{Name: "known with unsupported version", APIVersion: "kubelet.config.k8s.io/v1beta1", Version: "v1.9.11", ExpectError: true},
+		{Name: "known with bogus version", APIVersion: "kubelet.config.k8s.io/v1beta1", Version: "bogus", ExpectError: true},
+		{Name: "unknown", APIVersion: "kubelet.config.k8s.io/v1", ExpectError: true},
+		{Name: "empty", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateKubeletConfigAPIVersion(tc.APIVersion, tc.Version))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// KonnectivityConfigDir is the directory on the control plane nodes
+// containing the API server egress selector configuration, it is mounted
+// into the API server
+const KonnectivityConfigDir = "/etc/kubernetes/konnectivity"
+
+// EgressSelectorConfigPath is the path on the control plane nodes of the API
+// server's --egress-selector-config-file when ConfigData.Konnectivity is set
+const EgressSelectorConfigPath = KonnectivityConfigDir + "/egress-selector-configuration.yaml"
+
+// KonnectivityUDSDir is the directory on the control plane nodes containing
+// the konnectivity server's socket, it is mounted into the API server and the
+// konnectivity server
+const KonnectivityUDSDir = "/etc/kubernetes/konnectivity-server"
+
+// KonnectivityServerManifestPath is the path on the control plane nodes of
+// the konnectivity server static pod
+const KonnectivityServerManifestPath = "/etc/kubernetes/manifests/konnectivity-server.yaml"
+
+// KonnectivityAgentPort is the port the konnectivity server listens for
+// agents on, on each control plane node
+const KonnectivityAgentPort = 8132
+
+// the konnectivity images, the server and agent must be the same version
+const (
+	KonnectivityServerImage = "registry.k8s.io/kas-network-proxy/proxy-server:v0.0.37"
+	KonnectivityAgentImage  = "registry.k8s.io/kas-network-proxy/proxy-agent:v0.0.37"
+)
+
+// minKonnectivityVersion is the first version with the v1beta1
+// EgressSelectorConfiguration
+const minKonnectivityVersion = "v1.20.0"
+
+// ValidateKonnectivity returns an error listing the prerequisites if
+// kubernetesVersion does not support routing the API server's egress through
+// konnectivity
+func ValidateKonnectivity(kubernetesVersion string) error {
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return err
+	}
+	if ver.LessThan(version.MustParseGeneric(minKonnectivityVersion)) {
+		return errors.Errorf(
+			"konnectivity requires Kubernetes %s or newer, got %s: the API server must support the apiserver.k8s.io/v1beta1 EgressSelectorConfiguration and issue bound service account tokens for the agents",
+			minKonnectivityVersion, kubernetesVersion,
+		)
+	}
+	return nil
+}
+
+// EgressSelectorConfig is the API server egress selector configuration
+// sending the traffic to the cluster through the konnectivity server's socket
+const EgressSelectorConfig = `apiVersion: apiserver.k8s.io/v1beta1
+kind: EgressSelectorConfiguration
+egressSelections:
+- name: cluster
+  connection:
+    proxyProtocol: GRPC
+    transport:
+      uds:
+        udsName: ` + KonnectivityUDSDir + `/konnectivity-server.socket
+`
+
+// KonnectivityServerManifest returns the konnectivity server static pod,
+// it authenticates the agents with the admin kubeconfig written by kubeadm
+// and serves them with the API server certificate
+func KonnectivityServerManifest() string {
+	return strings.NewReplacer(
+		"__IMAGE__", KonnectivityServerImage,
+		"__UDS_DIR__", KonnectivityUDSDir,
+		"__AGENT_PORT__", fmt.Sprint(KonnectivityAgentPort),
+	).Replace(konnectivityServerManifestTemplate)
+}
+
+// konnectivityServerManifestTemplate is adapted from the upstream docs, see:
+// https://kubernetes.io/docs/tasks/extend-kubernetes/setup-konnectivity/
+const konnectivityServerManifestTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: konnectivity-server
+  namespace: kube-system
+spec:
+  priorityClassName: system-cluster-critical
+  hostNetwork: true
+  containers:
+  - name: konnectivity-server-container
+    image: __IMAGE__
+    command: ["/proxy-server"]
+    args:
+    - --logtostderr=true
+    - --uds-name=__UDS_DIR__/konnectivity-server.socket
+    - --delete-existing-uds-file
+    - --cluster-cert=/etc/kubernetes/pki/apiserver.crt
+    - --cluster-key=/etc/kubernetes/pki/apiserver.key
+    - --mode=grpc
+    - --server-port=0
+    - --agent-port=__AGENT_PORT__
+    - --admin-port=8133
+    - --health-port=8134
+    - --agent-namespace=kube-system
+    - --agent-service-account=konnectivity-agent
+    - --kubeconfig=/etc/kubernetes/admin.conf
+    - --authentication-audience=system:konnectivity-server
+    livenessProbe:
+      httpGet:
+        scheme: HTTP
+        host: 127.0.0.1
+        port: 8134
+        path: /healthz
+      initialDelaySeconds: 30
+      timeoutSeconds: 60
+    volumeMounts:
+    - name: k8s-certs
+      mountPath: /etc/kubernetes/pki
+      readOnly: true
+    - name: kubeconfig
+      mountPath: /etc/kubernetes/admin.conf
+      readOnly: true
+    - name: konnectivity-uds
+      mountPath: __UDS_DIR__
+  volumes:
+  - name: k8s-certs
+    hostPath:
+      path: /etc/kubernetes/pki
+  - name: kubeconfig
+    hostPath:
+      path: /etc/kubernetes/admin.conf
+      type: File
+  - name: konnectivity-uds
+    hostPath:
+      path: __UDS_DIR__
+      type: DirectoryOrCreate
+`
@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateKonnectivity(t *testing.T) {
+	t.Parallel()
+	assert.ExpectError(t, false, ValidateKonnectivity("v1.20.0"))
+	assert.ExpectError(t, false, ValidateKonnectivity("v1.21.1"))
+	assert.ExpectError(t, true, ValidateKonnectivity("v1.19.1"))
+	assert.ExpectError(t, true, ValidateKonnectivity("not a version"))
+}
+
+func TestKonnectivityServerManifest(t *testing.T) {
+	t.Parallel()
+	manifest := KonnectivityServerManifest()
+	assert.BoolEqual(t, false, strings.Contains(manifest, "__"))
+	assert.BoolEqual(t, true, strings.Contains(manifest, "--agent-port=8132"))
+	assert.BoolEqual(t, true, strings.Contains(manifest, KonnectivityServerImage))
+}
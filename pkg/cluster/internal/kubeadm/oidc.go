@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// OIDCConfigDir is the directory on the control plane nodes containing the
+// OIDC issuer's CA, it is mounted into the API server
+const OIDCConfigDir = "/etc/kubernetes/oidc"
+
+// OIDCCAPath is the path on the control plane nodes of the API server's
+// --oidc-ca-file when OIDCConfig.CAPath is set
+const OIDCCAPath = OIDCConfigDir + "/ca.crt"
+
+// OIDCConfig sets the API server flags for authenticating with OIDC ID
+// tokens, zero values leave the API server defaults
+// NOTE: this only configures the API server, the identity provider must be
+// set up separately and reachable from the control plane nodes
+type OIDCConfig struct {
+	// IssuerURL is --oidc-issuer-url, it must be an https URL
+	IssuerURL string
+	// ClientID is --oidc-client-id
+	ClientID string
+	// UsernameClaim is --oidc-username-claim
+	UsernameClaim string
+	// UsernamePrefix is --oidc-username-prefix
+	UsernamePrefix string
+	// GroupsClaim is --oidc-groups-claim
+	GroupsClaim string
+	// GroupsPrefix is --oidc-groups-prefix
+	GroupsPrefix string
+	// RequiredClaims is --oidc-required-claim, keyed by claim
+	RequiredClaims map[string]string
+	// SigningAlgs is --oidc-signing-algs
+	SigningAlgs []string
+	// CAPath is the host path of the PEM encoded CA that signed the issuer's
+	// serving certificate, if set it is copied to OIDCCAPath on the control
+	// plane nodes and used as --oidc-ca-file
+	CAPath string
+}
+
+// IsZero returns true if c does not configure OIDC
+func (c OIDCConfig) IsZero() bool {
+	return c.IssuerURL == "" && c.ClientID == "" && c.UsernameClaim == "" && c.UsernamePrefix == "" &&
+		c.GroupsClaim == "" && c.GroupsPrefix == "" && len(c.RequiredClaims) == 0 &&
+		len(c.SigningAlgs) == 0 && c.CAPath == ""
+}
+
+// Validate returns an error if c contains values the API server will reject
+func (c OIDCConfig) Validate() error {
+	errs := []error{}
+	if c.IssuerURL == "" {
+		errs = append(errs, errors.New("invalid OIDC config: the issuer URL is required"))
+	} else if u, err := url.Parse(c.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		errs = append(errs, errors.Errorf("invalid OIDC issuer URL %q: must be an https URL without a query or fragment", c.IssuerURL))
+	}
+	if c.ClientID == "" {
+		errs = append(errs, errors.New("invalid OIDC config: the client ID is required"))
+	}
+	for claim, value := range c.RequiredClaims {
+		if claim == "" || strings.ContainsAny(claim, ",=") || strings.Contains(value, ",") {
+			errs = append(errs, errors.Errorf("invalid OIDC required claim %q=%q: the claim must be non-empty and not contain ',' or '=', the value must not contain ','", claim, value))
+		}
+	}
+	for _, alg := range c.SigningAlgs {
+		if alg == "" || strings.Contains(alg, ",") {
+			errs = append(errs, errors.Errorf("invalid OIDC signing algorithm %q", alg))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// ExtraArgs returns the API server flags for c, without the leading --
+func (c OIDCConfig) ExtraArgs() map[string]string {
+	if c.IsZero() {
+		return nil
+	}
+	args := map[string]string{
+		"oidc-issuer-url": c.IssuerURL,
+		"oidc-client-id":  c.ClientID,
+	}
+	if c.UsernameClaim != "" {
+		args["oidc-username-claim"] = c.UsernameClaim
+	}
+	if c.UsernamePrefix != "" {
+		args["oidc-username-prefix"] = c.UsernamePrefix
+	}
+	if c.GroupsClaim != "" {
+		args["oidc-groups-claim"] = c.GroupsClaim
+	}
+	if c.GroupsPrefix != "" {
+		args["oidc-groups-prefix"] = c.GroupsPrefix
+	}
+	if len(c.RequiredClaims) > 0 {
+		claims := make([]string, 0, len(c.RequiredClaims))
+		for claim, value := range c.RequiredClaims {
+			claims = append(claims, claim+"="+value)
+		}
+		sort.Strings(claims)
+		args["oidc-required-claim"] = strings.Join(claims, ",")
+	}
+	if len(c.SigningAlgs) > 0 {
+		args["oidc-signing-algs"] = joinUnique(c.SigningAlgs)
+	}
+	if c.CAPath != "" {
+		args["oidc-ca-file"] = OIDCCAPath
+	}
+	return args
+}
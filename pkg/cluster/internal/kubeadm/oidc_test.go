@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestOIDCConfig(t *testing.T) {
+	t.Parallel()
+	valid := OIDCConfig{IssuerURL: "https://dex.example.com:5556/dex", ClientID: "kubernetes"}
+	assert.ExpectError(t, false, valid.Validate())
+	assert.ExpectError(t, true, OIDCConfig{ClientID: "kubernetes"}.Validate())
+	assert.ExpectError(t, true, OIDCConfig{IssuerURL: "https://dex.example.com"}.Validate())
+	assert.ExpectError(t, true, OIDCConfig{IssuerURL: "http://dex.example.com", ClientID: "kubernetes"}.Validate())
+	assert.ExpectError(t, true, OIDCConfig{IssuerURL: "dex.example.com", ClientID: "kubernetes"}.Validate())
+	assert.ExpectError(t, true, OIDCConfig{IssuerURL: "https://dex.example.com?x=y", ClientID: "kubernetes"}.Validate())
+	withClaim := valid
+	withClaim.RequiredClaims = map[string]string{"a=b": "c"}
+	assert.ExpectError(t, true, withClaim.Validate())
+
+	assert.BoolEqual(t, true, OIDCConfig{}.IsZero())
+	assert.DeepEqual(t, map[string]string(nil), OIDCConfig{}.ExtraArgs())
+	assert.DeepEqual(t, map[string]string{
+		"oidc-issuer-url":     "https://dex.example.com",
+		"oidc-client-id":      "kubernetes",
+		"oidc-username-claim": "email",
+		"oidc-groups-claim":   "groups",
+		"oidc-groups-prefix":  "oidc:",
+		"oidc-required-claim": "aud=kubernetes,hd=example.com",
+		"oidc-signing-algs":   "RS256,ES256",
+		"oidc-ca-file":        OIDCCAPath,
+	}, OIDCConfig{
+		IssuerURL:      "https://dex.example.com",
+		ClientID:       "kubernetes",
+		UsernameClaim:  "email",
+		GroupsClaim:    "groups",
+		GroupsPrefix:   "oidc:",
+		RequiredClaims: map[string]string{"hd": "example.com", "aud": "kubernetes"},
+		SigningAlgs:    []string{"RS256", "ES256", "RS256"},
+		CAPath:         "/tmp/dex-ca.crt",
+	}.ExtraArgs())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// PatchesDir is the directory on the nodes containing kubeadm patches for
+// the control plane static pods, see PatchesFlag
+const PatchesDir = "/kind/patches"
+
+// PatchesFlag returns the kubeadm init / join flag for applying the patches
+// in PatchesDir supported by kubernetesVersion
+func PatchesFlag(kubernetesVersion string) (string, error) {
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return "", err
+	}
+	if ver.AtLeast(version.MustParseGeneric("v1.22.0")) {
+		return "--patches=" + PatchesDir, nil
+	}
+	if ver.AtLeast(version.MustParseGeneric("v1.19.0")) {
+		return "--experimental-patches=" + PatchesDir, nil
+	}
+	return "", errors.Errorf("kubeadm patches require Kubernetes v1.19.0 or newer, got %s", kubernetesVersion)
+}
+
+// LivenessProbeTuning relaxes the liveness probes of the API server and etcd
+// static pods, zero values leave the kubeadm defaults
+type LivenessProbeTuning struct {
+	InitialDelaySeconds int32
+	TimeoutSeconds      int32
+}
+
+// IsZero returns true if t does not change any of the defaults
+func (t LivenessProbeTuning) IsZero() bool {
+	return t.InitialDelaySeconds == 0 && t.TimeoutSeconds == 0
+}
+
+// maxLivenessProbeSeconds bounds LivenessProbeTuning values, larger values
+// would delay detecting a broken control plane for too long to be useful
+const maxLivenessProbeSeconds = 600
+
+// Validate returns an error if t contains negative or unreasonably large values
+func (t LivenessProbeTuning) Validate() error {
+	errs := []error{}
+	if t.InitialDelaySeconds < 0 || t.InitialDelaySeconds > maxLivenessProbeSeconds {
+		errs = append(errs, errors.Errorf("invalid liveness probe initial delay %ds: must be positive and at most %ds", t.InitialDelaySeconds, maxLivenessProbeSeconds))
+	}
+	if t.TimeoutSeconds < 0 || t.TimeoutSeconds > maxLivenessProbeSeconds {
+		errs = append(errs, errors.Errorf("invalid liveness probe timeout %ds: must be positive and at most %ds", t.TimeoutSeconds, maxLivenessProbeSeconds))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// Patches returns the kubeadm patch files to write into PatchesDir,
+// keyed by file name
+func (t LivenessProbeTuning) Patches() map[string]string {
+	ops := []string{}
+	if t.InitialDelaySeconds > 0 {
+		ops = append(ops, fmt.Sprintf(
+			`{"op": "replace", "path": "/spec/containers/0/livenessProbe/initialDelaySeconds", "value": %d}`, t.InitialDelaySeconds,
+		))
+	}
+	if t.TimeoutSeconds > 0 {
+		ops = append(ops, fmt.Sprintf(
+			`{"op": "replace", "path": "/spec/containers/0/livenessProbe/timeoutSeconds", "value": %d}`, t.TimeoutSeconds,
+		))
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	patch := "[" + strings.Join(ops, ", ") + "]\n"
+	// file names are of the form target[suffix][+patchtype].extension
+	return map[string]string{
+		"kube-apiserver+json.json": patch,
+		"etcd+json.json":           patch,
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestPatchesFlag(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Version     string
+		Expected    string
+		ExpectError bool
+	}{
+		{Version: "v1.18.8", ExpectError: true},
+		{Version: "v1.19.1", Expected: "--experimental-patches=" + PatchesDir},
+		{Version: "v1.22.0", Expected: "--patches=" + PatchesDir},
+		{Version: "bogus", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			flag, err := PatchesFlag(tc.Version)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, flag)
+		})
+	}
+}
+
+func TestLivenessProbeTuning(t *testing.T) {
+	t.Parallel()
+	assert.ExpectError(t, false, LivenessProbeTuning{InitialDelaySeconds: 60, TimeoutSeconds: 30}.Validate())
+	assert.ExpectError(t, true, LivenessProbeTuning{InitialDelaySeconds: -1}.Validate())
+	assert.ExpectError(t, true, LivenessProbeTuning{TimeoutSeconds: 601}.Validate())
+
+	assert.DeepEqual(t, map[string]string(nil), LivenessProbeTuning{}.Patches())
+	patch := `[{"op": "replace", "path": "/spec/containers/0/livenessProbe/timeoutSeconds", "value": 30}]` + "\n"
+	assert.DeepEqual(t, map[string]string{
+		"kube-apiserver+json.json": patch,
+		"etcd+json.json":           patch,
+	}, LivenessProbeTuning{TimeoutSeconds: 30}.Patches())
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// phase is a top level kubeadm init or join phase
+type phase struct {
+	// minVersion is the first Kubernetes version with this phase if set
+	minVersion string
+	// required is set if kind depends on this phase and its sub-phases
+	required bool
+	// subPhases are the sub-phases that may be skipped on their own
+	subPhases []string
+}
+
+// initPhases are the phases of kubeadm init
+var initPhases = map[string]phase{
+	"preflight":          {},
+	"certs":              {required: true},
+	"kubeconfig":         {required: true},
+	"kubelet-start":      {required: true},
+	"control-plane":      {required: true},
+	"etcd":               {required: true},
+	"upload-config":      {required: true},
+	"upload-certs":       {},
+	"mark-control-plane": {},
+	"bootstrap-token":    {required: true},
+	"kubelet-finalize":   {minVersion: "v1.17.0", subPhases: []string{"experimental-cert-rotation"}},
+	"addon":              {subPhases: []string{"coredns", "kube-proxy"}},
+	"show-join-command":  {minVersion: "v1.22.0"},
+}
+
+// joinPhases are the phases of kubeadm join
+var joinPhases = map[string]phase{
+	"preflight":             {},
+	"control-plane-prepare": {required: true},
+	"kubelet-start":         {required: true},
+	"control-plane-join":    {required: true},
+}
+
+// ValidateSkipPhases returns an error if phases are not all phases of the
+// kubeadm command ("init" or "join") that kind does not depend on.
+// If kubernetesVersion is set the phases must also exist in that version.
+func ValidateSkipPhases(command string, phases []string, kubernetesVersion string) error {
+	var known map[string]phase
+	switch command {
+	case "init":
+		known = initPhases
+	case "join":
+		known = joinPhases
+	default:
+		return errors.Errorf("unknown kubeadm command %q", command)
+	}
+	var ver *version.Version
+	if kubernetesVersion != "" {
+		v, err := version.ParseGeneric(kubernetesVersion)
+		if err != nil {
+			return err
+		}
+		ver = v
+	}
+	errs := []error{}
+	for _, name := range phases {
+		parts := strings.SplitN(name, "/", 2)
+		p, ok := known[parts[0]]
+		switch {
+		case !ok:
+			errs = append(errs, errors.Errorf("unknown kubeadm %s phase %q", command, name))
+		case p.required:
+			errs = append(errs, errors.Errorf("kubeadm %s phase %q cannot be skipped, kind depends on it", command, name))
+		case len(parts) == 2 && !containsString(p.subPhases, parts[1]):
+			errs = append(errs, errors.Errorf("unknown kubeadm %s phase %q", command, name))
+		case ver != nil && p.minVersion != "" && !ver.AtLeast(version.MustParseSemantic(p.minVersion)):
+			errs = append(errs, errors.Errorf("kubeadm %s phase %q requires Kubernetes %s or newer, got %s", command, name, p.minVersion, kubernetesVersion))
+		}
+	}
+	return errors.NewAggregate(errs)
+}
+
+// SkipPhasesFlag returns the kubeadm init / join flag for skipping the
+// preflight phase, which kind always skips, along with phases
+func SkipPhasesFlag(phases []string) string {
+	skip := []string{"preflight"}
+	for _, name := range phases {
+		if name != "preflight" {
+			skip = append(skip, name)
+		}
+	}
+	return "--skip-phases=" + strings.Join(skip, ",")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
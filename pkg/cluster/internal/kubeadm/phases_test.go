@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateSkipPhases(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Command     string
+		Phases      []string
+		Version     string
+		ExpectError bool
+	}{
+		{Name: "no phases", Command: "init"},
+		{Name: "init addons", Command: "init", Phases: []string{"addon/coredns", "addon/kube-proxy"}},
+		{Name: "all init addons", Command: "init", Phases: []string{"addon"}},
+		{Name: "init phase kind depends on", Command: "init", Phases: []string{"certs"}, ExpectError: true},
+		{Name: "init sub-phase kind depends on", Command: "init", Phases: []string{"control-plane/apiserver"}, ExpectError: true},
+		{Name: "unknown init phase", Command: "init", Phases: []string{"bogus"}, ExpectError: true},
+		{Name: "unknown init sub-phase", Command: "init", Phases: []string{"addon/bogus"}, ExpectError: true},
+		{Name: "join only phase for init", Command: "init", Phases: []string{"control-plane-join"}, ExpectError: true},
+		{Name: "join preflight", Command: "join", Phases: []string{"preflight"}},
+		{Name: "join phase kind depends on", Command: "join", Phases: []string{"kubelet-start"}, ExpectError: true},
+		{Name: "init only phase for join", Command: "join", Phases: []string{"addon/coredns"}, ExpectError: true},
+		{Name: "phase in version", Command: "init", Phases: []string{"show-join-command"}, Version: "v1.22.1"},
+		{Name: "phase not in version", Command: "init", Phases: []string{"show-join-command"}, Version: "v1.19.1", ExpectError: true},
+		{Name: "invalid version", Command: "init", Phases: []string{"addon"}, Version: "bogus", ExpectError: true},
+		{Name: "unknown command", Command: "reset", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateSkipPhases(tc.Command, tc.Phases, tc.Version))
+		})
+	}
+}
+
+func TestSkipPhasesFlag(t *testing.T) {
+	t.Parallel()
+	assert.StringEqual(t, "--skip-phases=preflight", SkipPhasesFlag(nil))
+	assert.StringEqual(t, "--skip-phases=preflight,addon/kube-proxy", SkipPhasesFlag([]string{"preflight", "addon/kube-proxy"}))
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// AdmissionConfigDir is the directory on the control plane nodes containing
+// the API server admission configuration, it is mounted into the API server
+const AdmissionConfigDir = "/etc/kubernetes/admission"
+
+// PodSecurityConfigPath is the path on the control plane nodes of the API
+// server's --admission-control-config-file when ConfigData.PodSecurityConfig
+// is set
+const PodSecurityConfigPath = AdmissionConfigDir + "/pod-security.yaml"
+
+// minPodSecurityVersion is the first version with the PodSecurity admission
+// plugin enabled without a feature gate
+const minPodSecurityVersion = "v1.23.0"
+
+// ValidatePodSecurityAdmission returns an error if kubernetesVersion does
+// not support configuring the PodSecurity admission plugin
+func ValidatePodSecurityAdmission(kubernetesVersion string) error {
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return err
+	}
+	if ver.LessThan(version.MustParseGeneric(minPodSecurityVersion)) {
+		return errors.Errorf("pod security admission requires Kubernetes %s or newer, got %s", minPodSecurityVersion, kubernetesVersion)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	yaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// SchedulerConfigDir is the directory on the control plane nodes containing
+// the kube-scheduler configuration, it is mounted into the scheduler
+const SchedulerConfigDir = "/etc/kubernetes/scheduler"
+
+// SchedulerConfigPath is the path on the control plane nodes of the
+// kube-scheduler's --config when ConfigData.SchedulerConfig is set
+const SchedulerConfigPath = SchedulerConfigDir + "/config.yaml"
+
+// schedulerKubeconfigPath is the kubeconfig kubeadm writes for the scheduler,
+// the flag kubeadm sets for it is ignored when --config is set
+const schedulerKubeconfigPath = "/etc/kubernetes/scheduler.conf"
+
+// defaultSchedulerName is the name of the scheduler's default profile
+const defaultSchedulerName = "default-scheduler"
+
+// SchedulerProfile is a profile in the KubeSchedulerConfiguration
+type SchedulerProfile struct {
+	// SchedulerName is the name pods use to select this profile
+	SchedulerName string
+	// Plugins is the profile's plugins as a yaml mapping, if any
+	Plugins string
+	// PluginConfig is the profile's pluginConfig as a yaml list, if any
+	PluginConfig string
+}
+
+// schedulerConfigAPIVersions maps the first Kubernetes version serving each
+// KubeSchedulerConfiguration apiVersion with scheduler profiles, newest first
+var schedulerConfigAPIVersions = []struct {
+	minVersion string
+	apiVersion string
+}{
+	{minVersion: "v1.25.0", apiVersion: "kubescheduler.config.k8s.io/v1"},
+	{minVersion: "v1.23.0", apiVersion: "kubescheduler.config.k8s.io/v1beta3"},
+	{minVersion: "v1.22.0", apiVersion: "kubescheduler.config.k8s.io/v1beta2"},
+	{minVersion: "v1.19.0", apiVersion: "kubescheduler.config.k8s.io/v1beta1"},
+	{minVersion: "v1.18.0", apiVersion: "kubescheduler.config.k8s.io/v1alpha2"},
+}
+
+// SchedulerConfigAPIVersion returns the KubeSchedulerConfiguration apiVersion
+// to use for kubernetesVersion, or an error if kubernetesVersion does not
+// support scheduler profiles
+func SchedulerConfigAPIVersion(kubernetesVersion string) (string, error) {
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range schedulerConfigAPIVersions {
+		if !ver.LessThan(version.MustParseGeneric(v.minVersion)) {
+			return v.apiVersion, nil
+		}
+	}
+	oldest := schedulerConfigAPIVersions[len(schedulerConfigAPIVersions)-1].minVersion
+	return "", errors.Errorf("scheduler profiles require Kubernetes %s or newer, got %s", oldest, kubernetesVersion)
+}
+
+// schedulerConfig is the subset of the KubeSchedulerConfiguration kind sets,
+// the fields are the same in every apiVersion with profiles
+type schedulerConfig struct {
+	APIVersion       string                 `yaml:"apiVersion"`
+	Kind             string                 `yaml:"kind"`
+	ClientConnection schedulerClientConfig  `yaml:"clientConnection"`
+	Profiles         []schedulerProfileData `yaml:"profiles"`
+}
+
+type schedulerClientConfig struct {
+	Kubeconfig string `yaml:"kubeconfig"`
+}
+
+type schedulerProfileData struct {
+	SchedulerName string                 `yaml:"schedulerName"`
+	Plugins       map[string]interface{} `yaml:"plugins,omitempty"`
+	PluginConfig  []interface{}          `yaml:"pluginConfig,omitempty"`
+}
+
+// SchedulerConfig returns the KubeSchedulerConfiguration with profiles for
+// kubernetesVersion, the default-scheduler profile is kept first unless it
+// is in profiles
+func SchedulerConfig(kubernetesVersion string, profiles []SchedulerProfile) (string, error) {
+	apiVersion, err := SchedulerConfigAPIVersion(kubernetesVersion)
+	if err != nil {
+		return "", err
+	}
+	cfg := schedulerConfig{
+		APIVersion: apiVersion,
+		Kind:       "KubeSchedulerConfiguration",
+		ClientConnection: schedulerClientConfig{
+			Kubeconfig: schedulerKubeconfigPath,
+		},
+	}
+	hasDefault := false
+	for _, p := range profiles {
+		if p.SchedulerName == defaultSchedulerName {
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		cfg.Profiles = append(cfg.Profiles, schedulerProfileData{SchedulerName: defaultSchedulerName})
+	}
+	for _, p := range profiles {
+		data := schedulerProfileData{SchedulerName: p.SchedulerName}
+		if err := yaml.Unmarshal([]byte(p.Plugins), &data.Plugins); err != nil {
+			return "", errors.Wrapf(err, "invalid plugins for scheduler profile %q", p.SchedulerName)
+		}
+		if err := yaml.Unmarshal([]byte(p.PluginConfig), &data.PluginConfig); err != nil {
+			return "", errors.Wrapf(err, "invalid pluginConfig for scheduler profile %q", p.SchedulerName)
+		}
+		cfg.Profiles = append(cfg.Profiles, data)
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode scheduler config")
+	}
+	return string(b), nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestSchedulerConfigAPIVersion(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Version     string
+		Expected    string
+		ExpectError bool
+	}{
+		{Version: "v1.17.11", ExpectError: true},
+		{Version: "v1.18.8", Expected: "kubescheduler.config.k8s.io/v1alpha2"},
+		{Version: "v1.19.1", Expected: "kubescheduler.config.k8s.io/v1beta1"},
+		{Version: "v1.21.0", Expected: "kubescheduler.config.k8s.io/v1beta1"},
+		{Version: "v1.22.2", Expected: "kubescheduler.config.k8s.io/v1beta2"},
+		{Version: "v1.24.0", Expected: "kubescheduler.config.k8s.io/v1beta3"},
+		{Version: "v1.25.0-alpha.1", Expected: "kubescheduler.config.k8s.io/v1"},
+		{Version: "v1.30.2", Expected: "kubescheduler.config.k8s.io/v1"},
+		{Version: "bogus", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Version, func(t *testing.T) {
+			t.Parallel()
+			apiVersion, err := SchedulerConfigAPIVersion(tc.Version)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, apiVersion)
+		})
+	}
+}
+
+func TestSchedulerConfig(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Version     string
+		Profiles    []SchedulerProfile
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Name:    "keeps default profile",
+			Version: "v1.19.1",
+			Profiles: []SchedulerProfile{{
+				SchedulerName: "no-scoring-scheduler",
+				Plugins:       "score:\n  disabled:\n  - name: '*'\n",
+			}},
+			Expected: `apiVersion: kubescheduler.config.k8s.io/v1beta1
+kind: KubeSchedulerConfiguration
+clientConnection:
+    kubeconfig: /etc/kubernetes/scheduler.conf
+profiles:
+    - schedulerName: default-scheduler
+    - schedulerName: no-scoring-scheduler
+      plugins:
+        score:
+            disabled:
+                - name: '*'
+`,
+		},
+		{
+			Name:    "overrides default profile",
+			Version: "v1.25.3",
+			Profiles: []SchedulerProfile{{
+				SchedulerName: "default-scheduler",
+				PluginConfig:  "- name: NodeResourcesFit\n  args:\n    scoringStrategy:\n      type: MostAllocated\n",
+			}},
+			Expected: `apiVersion: kubescheduler.config.k8s.io/v1
+kind: KubeSchedulerConfiguration
+clientConnection:
+    kubeconfig: /etc/kubernetes/scheduler.conf
+profiles:
+    - schedulerName: default-scheduler
+      pluginConfig:
+        - args:
+            scoringStrategy:
+                type: MostAllocated
+          name: NodeResourcesFit
+`,
+		},
+		{
+			Name:        "unsupported version",
+			Version:     "v1.17.0",
+			Profiles:    []SchedulerProfile{{SchedulerName: "my-scheduler"}},
+			ExpectError: true,
+		},
+		{
+			Name:        "bogus plugins",
+			Version:     "v1.19.1",
+			Profiles:    []SchedulerProfile{{SchedulerName: "my-scheduler", Plugins: "- not a mapping"}},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			config, err := SchedulerConfig(tc.Version, tc.Profiles)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.StringEqual(t, tc.Expected, config)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// GracefulNodeShutdownFeatureGate is the feature gate enabled along with
+// GracefulShutdown
+const GracefulNodeShutdownFeatureGate = "GracefulNodeShutdown"
+
+// minGracefulNodeShutdownVersion is the first version with the
+// GracefulNodeShutdown feature gate
+const minGracefulNodeShutdownVersion = "v1.20.0"
+
+// GracefulShutdown configures the kubelet's graceful node shutdown, zero
+// values leave it disabled
+// NOTE: the kubelet delays shutdown with a systemd-logind inhibitor lock, so
+// this only works if the node container runs systemd-logind
+type GracefulShutdown struct {
+	// GracePeriod is the kubelet's shutdownGracePeriod, the total time the
+	// node delays shutting down for pods to terminate
+	GracePeriod time.Duration
+	// CriticalPodsGracePeriod is the kubelet's
+	// shutdownGracePeriodCriticalPods, the part of GracePeriod reserved for
+	// terminating critical pods
+	CriticalPodsGracePeriod time.Duration
+}
+
+// IsZero returns true if g does not enable graceful node shutdown
+func (g GracefulShutdown) IsZero() bool {
+	return g.GracePeriod == 0 && g.CriticalPodsGracePeriod == 0
+}
+
+// Validate returns an error if g contains values the kubelet will reject
+func (g GracefulShutdown) Validate() error {
+	errs := []error{}
+	if g.GracePeriod < 0 {
+		errs = append(errs, errors.Errorf("invalid shutdown grace period %s: must not be negative", g.GracePeriod))
+	}
+	if g.CriticalPodsGracePeriod < 0 {
+		errs = append(errs, errors.Errorf("invalid critical pods shutdown grace period %s: must not be negative", g.CriticalPodsGracePeriod))
+	} else if g.CriticalPodsGracePeriod > g.GracePeriod {
+		errs = append(errs, errors.Errorf("invalid critical pods shutdown grace period %s: must be at most the shutdown grace period %s", g.CriticalPodsGracePeriod, g.GracePeriod))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// ValidateGracefulShutdown returns an error if kubernetesVersion does not
+// support graceful node shutdown
+func ValidateGracefulShutdown(kubernetesVersion string) error {
+	ver, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return err
+	}
+	if ver.LessThan(version.MustParseGeneric(minGracefulNodeShutdownVersion)) {
+		return errors.Errorf("graceful node shutdown requires Kubernetes %s or newer, got %s", minGracefulNodeShutdownVersion, kubernetesVersion)
+	}
+	return nil
+}
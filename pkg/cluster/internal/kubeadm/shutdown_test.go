@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	t.Parallel()
+	assert.BoolEqual(t, true, GracefulShutdown{}.IsZero())
+	assert.ExpectError(t, false, GracefulShutdown{}.Validate())
+	assert.ExpectError(t, false, GracefulShutdown{GracePeriod: 30 * time.Second, CriticalPodsGracePeriod: 10 * time.Second}.Validate())
+	assert.ExpectError(t, false, GracefulShutdown{GracePeriod: 30 * time.Second}.Validate())
+	assert.ExpectError(t, true, GracefulShutdown{GracePeriod: -time.Second}.Validate())
+	assert.ExpectError(t, true, GracefulShutdown{CriticalPodsGracePeriod: 10 * time.Second}.Validate())
+	assert.ExpectError(t, true, GracefulShutdown{GracePeriod: 10 * time.Second, CriticalPodsGracePeriod: 20 * time.Second}.Validate())
+
+	assert.ExpectError(t, false, ValidateGracefulShutdown("v1.21.1"))
+	assert.ExpectError(t, true, ValidateGracefulShutdown("v1.19.1"))
+}
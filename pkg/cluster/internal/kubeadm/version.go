@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// minKubeadmVersion is the oldest kubeadm kind can configure, older kubeadm
+// does not support the v1beta1 config or skipping the preflight phase
+const minKubeadmVersion = "v1.13.0"
+
+// ValidateKubeadmVersion returns an error if kubeadmVersion, the output of
+// `kubeadm version -o short`, is too old for kind or is not the same minor
+// version as kubernetesVersion, the Kubernetes version of the node image
+func ValidateKubeadmVersion(kubeadmVersion, kubernetesVersion string) error {
+	kubeadmVer, err := version.ParseGeneric(kubeadmVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse kubeadm version %q", kubeadmVersion)
+	}
+	if kubeadmVer.LessThan(version.MustParseGeneric(minKubeadmVersion)) {
+		return errors.Errorf("kubeadm %s is not supported, kind requires kubeadm %s or newer", kubeadmVersion, minKubeadmVersion)
+	}
+	kubeVer, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse Kubernetes version %q", kubernetesVersion)
+	}
+	if kubeadmVer.Major() != kubeVer.Major() || kubeadmVer.Minor() != kubeVer.Minor() {
+		return errors.Errorf("kubeadm %s does not match the image's Kubernetes version %s", kubeadmVersion, kubernetesVersion)
+	}
+	return nil
+}
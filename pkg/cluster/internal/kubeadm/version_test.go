@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeadm
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateKubeadmVersion(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name              string
+		KubeadmVersion    string
+		KubernetesVersion string
+		ExpectError       bool
+	}{
+		{Name: "same version", KubeadmVersion: "v1.19.1", KubernetesVersion: "v1.19.1"},
+		{Name: "same minor version", KubeadmVersion: "v1.19.0", KubernetesVersion: "v1.19.1-rc.0+abc"},
+		{Name: "different minor version", KubeadmVersion: "v1.18.8", KubernetesVersion: "v1.19.1", ExpectError: true},
+		{Name: "too old", KubeadmVersion: "v1.12.10", KubernetesVersion: "v1.12.10", ExpectError: true},
+		{Name: "not a version", KubeadmVersion: "kubeadm: command not found", KubernetesVersion: "v1.19.1", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateKubeadmVersion(tc.KubeadmVersion, tc.KubernetesVersion))
+		})
+	}
+}
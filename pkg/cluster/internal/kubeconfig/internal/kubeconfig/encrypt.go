@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// encryptedPrefix starts the contents of encrypted kubeconfigs, it is
+// followed by the base64 encoded nonce and AES-256-GCM sealed kubeconfig
+const encryptedPrefix = "kind-kubeconfig-aes256gcm-v1:"
+
+// ParseEncryptionKey parses a 256-bit kubeconfig encryption key from key,
+// which must be hex or base64 encoded
+func ParseEncryptionKey(key string) ([]byte, error) {
+	key = strings.TrimSpace(key)
+	if b, err := hex.DecodeString(key); err == nil && len(b) == 32 {
+		return b, nil
+	}
+	if b, err := base64.StdEncoding.DecodeString(key); err == nil && len(b) == 32 {
+		return b, nil
+	}
+	return nil, errors.New("invalid kubeconfig encryption key: must be 32 bytes, hex or base64 encoded")
+}
+
+// IsEncrypted returns true if contents is an encrypted kubeconfig
+func IsEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, []byte(encryptedPrefix))
+}
+
+// Encrypt seals the kubeconfig contents with key, see ParseEncryptionKey
+func Encrypt(contents, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate kubeconfig encryption nonce")
+	}
+	sealed := gcm.Seal(nonce, nonce, contents, nil)
+	return []byte(encryptedPrefix + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Decrypt opens an encrypted kubeconfig sealed by Encrypt with key
+func Decrypt(encrypted, key []byte) ([]byte, error) {
+	if !IsEncrypted(encrypted) {
+		return nil, errors.New("not an encrypted kubeconfig")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encrypted[len(encryptedPrefix):])))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode encrypted kubeconfig")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("encrypted kubeconfig is truncated")
+	}
+	contents, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt kubeconfig, is the key correct?")
+	}
+	return contents, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid kubeconfig encryption key")
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteEncrypted writes a kind kubeconfig (see KINDFromRawKubeadm) to
+// configPath encrypted with key, replacing any existing contents, see
+// WriteStandalone
+func WriteEncrypted(kindConfig *Config, configPath string, key []byte) error {
+	if configPath == "" {
+		return errors.New("an encrypted kubeconfig requires an explicit path")
+	}
+	// verify assumptions about kubeadm / kind kubeconfigs
+	if err := checkKubeadmExpectations(kindConfig); err != nil {
+		return err
+	}
+	encoded, err := Encode(kindConfig)
+	if err != nil {
+		return err
+	}
+	encrypted, err := Encrypt(encoded, key)
+	if err != nil {
+		return err
+	}
+
+	// lock config file the same as client-go
+	if err := lockFile(configPath); err != nil {
+		return errors.Wrap(err, "failed to lock config file")
+	}
+	defer func() {
+		_ = unlockFile(configPath)
+	}()
+
+	// NOTE: 0755 / 0600 are to match client-go
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return errors.Wrap(err, "failed to create directory for KUBECONFIG")
+	}
+	if err := ioutil.WriteFile(configPath, encrypted, 0600); err != nil {
+		return errors.Wrap(err, "failed to write KUBECONFIG")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"bytes"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseEncryptionKey(t *testing.T) {
+	t.Parallel()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	cases := []struct {
+		Name        string
+		Key         string
+		ExpectError bool
+	}{
+		{
+			Name: "hex",
+			Key:  "3031323334353637383961626364656630313233343536373839616263646566",
+		},
+		{
+			Name: "base64 with trailing newline",
+			Key:  "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n",
+		},
+		{
+			Name:        "too short",
+			Key:         "30313233343536373839616263646566",
+			ExpectError: true,
+		},
+		{
+			Name:        "not encoded",
+			Key:         "0123456789abcdef0123456789abcdef",
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			parsed, err := ParseEncryptionKey(tc.Key)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, key, parsed)
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+	key := []byte("0123456789abcdef0123456789abcdef")
+	contents := []byte("apiVersion: v1\nkind: Config\n")
+	encrypted, err := Encrypt(contents, key)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	assert.BoolEqual(t, true, IsEncrypted(encrypted))
+	if bytes.Contains(encrypted, contents) {
+		t.Fatalf("Encrypted kubeconfig contains the plaintext")
+	}
+	decrypted, err := Decrypt(encrypted, key)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.StringEqual(t, string(contents), string(decrypted))
+
+	_, err = Decrypt(encrypted, []byte("fedcba9876543210fedcba9876543210"))
+	assert.ExpectError(t, true, err)
+	_, err = Decrypt(contents, key)
+	assert.ExpectError(t, true, err)
+}
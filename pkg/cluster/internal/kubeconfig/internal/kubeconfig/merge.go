@@ -17,7 +17,10 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
 	"sigs.k8s.io/kind/pkg/errors"
 )
@@ -25,7 +28,9 @@ import (
 // WriteMerged writes a kind kubeconfig (see KINDFromRawKubeadm) into configPath
 // merging with the existing contents if any and setting the current context to
 // the kind config's current context.
-func WriteMerged(kindConfig *Config, explicitConfigPath string) error {
+// If onClash is set it is called with the context name and how the existing
+// context differs if merging replaces a context pointing at another cluster.
+func WriteMerged(kindConfig *Config, explicitConfigPath string, onClash func(context, reason string)) error {
 	// figure out what filepath we should use
 	configPath := pathForMerge(explicitConfigPath, os.Getenv)
 
@@ -44,6 +49,14 @@ func WriteMerged(kindConfig *Config, explicitConfigPath string) error {
 	}
 
 	// merge with kind kubeconfig
+	if err := checkKubeadmExpectations(kindConfig); err != nil {
+		return err
+	}
+	if onClash != nil {
+		if reason := contextClash(existing, kindConfig); reason != "" {
+			onClash(kindConfig.Contexts[0].Name, reason)
+		}
+	}
 	if err := merge(existing, kindConfig); err != nil {
 		return err
 	}
@@ -109,3 +122,48 @@ func merge(existing, kind *Config) error {
 
 	return nil
 }
+
+// contextClash returns how the cluster the existing context with the kind
+// context's name points at differs from the kind cluster, if it exists and
+// has another server or certificate authority, E.G. a stale context left
+// behind by a cluster that no longer exists
+func contextClash(existing, kind *Config) string {
+	clusterName := ""
+	for _, c := range existing.Contexts {
+		if c.Name == kind.Contexts[0].Name {
+			clusterName = c.Context.Cluster
+		}
+	}
+	if clusterName == "" {
+		return ""
+	}
+	current := kind.Clusters[0].Cluster
+	for _, c := range existing.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		var reasons []string
+		if c.Cluster.Server != current.Server {
+			reasons = append(reasons, fmt.Sprintf("server %s instead of %s", c.Cluster.Server, current.Server))
+		}
+		// exporting without a CA to skip TLS verification is not a clash
+		if hasCA(&current) && !sameCA(&c.Cluster, &current) {
+			reasons = append(reasons, "a different certificate authority")
+		}
+		return strings.Join(reasons, " and ")
+	}
+	return ""
+}
+
+func hasCA(cluster *Cluster) bool {
+	return cluster.OtherFields[certificateAuthorityDataKey] != nil || cluster.OtherFields[certificateAuthorityKey] != nil
+}
+
+func sameCA(a, b *Cluster) bool {
+	for _, key := range []string{certificateAuthorityDataKey, certificateAuthorityKey} {
+		if !reflect.DeepEqual(a.OtherFields[key], b.OtherFields[key]) {
+			return false
+		}
+	}
+	return true
+}
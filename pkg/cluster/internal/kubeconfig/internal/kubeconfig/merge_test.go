@@ -304,7 +304,7 @@ users:
 		},
 	}
 	// ensure that we can write this merged config
-	if err := WriteMerged(kindConfig, existingConfigPath); err != nil {
+	if err := WriteMerged(kindConfig, existingConfigPath, nil); err != nil {
 		t.Fatalf("Failed to write merged kubeconfig: %v", err)
 	}
 
@@ -360,7 +360,7 @@ func testWriteMergedBogusConfig(t *testing.T) {
 	}
 	defer os.RemoveAll(dir)
 
-	err = WriteMerged(&Config{}, filepath.Join(dir, "bogus"))
+	err = WriteMerged(&Config{}, filepath.Join(dir, "bogus"), nil)
 	assert.ExpectError(t, true, err)
 }
 
@@ -411,7 +411,7 @@ func testWriteMergedNoExistingFile(t *testing.T) {
 	}
 
 	nonExistentPath := filepath.Join(dir, "bogus", "extra-bogus")
-	err = WriteMerged(kindConfig, nonExistentPath)
+	err = WriteMerged(kindConfig, nonExistentPath, nil)
 	assert.ExpectError(t, false, err)
 
 	// ensure the output matches expected
@@ -445,3 +445,59 @@ users:
 `
 	assert.StringEqual(t, expected, string(contents))
 }
+
+func TestContextClash(t *testing.T) {
+	t.Parallel()
+	kindConfig := func(server, ca string) *Config {
+		cluster := Cluster{Server: server}
+		if ca != "" {
+			cluster.OtherFields = map[string]interface{}{certificateAuthorityDataKey: ca}
+		}
+		return &Config{
+			Clusters: []NamedCluster{{Name: "kind-kind", Cluster: cluster}},
+			Contexts: []NamedContext{{Name: "kind-kind", Context: Context{Cluster: "kind-kind", User: "kind-kind"}}},
+		}
+	}
+	cases := []struct {
+		Name        string
+		Existing    *Config
+		Kind        *Config
+		ExpectClash bool
+	}{
+		{
+			Name:     "no existing context",
+			Existing: &Config{},
+			Kind:     kindConfig("https://127.0.0.1:6443", "ca"),
+		},
+		{
+			Name:     "same cluster",
+			Existing: kindConfig("https://127.0.0.1:6443", "ca"),
+			Kind:     kindConfig("https://127.0.0.1:6443", "ca"),
+		},
+		{
+			Name:        "different server",
+			Existing:    kindConfig("https://127.0.0.1:6443", "ca"),
+			Kind:        kindConfig("https://127.0.0.1:34567", "ca"),
+			ExpectClash: true,
+		},
+		{
+			Name:        "different certificate authority",
+			Existing:    kindConfig("https://127.0.0.1:6443", "old"),
+			Kind:        kindConfig("https://127.0.0.1:6443", "new"),
+			ExpectClash: true,
+		},
+		{
+			Name:     "skipping TLS verification",
+			Existing: kindConfig("https://127.0.0.1:6443", "ca"),
+			Kind:     kindConfig("https://127.0.0.1:6443", ""),
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			reason := contextClash(tc.Existing, tc.Kind)
+			assert.BoolEqual(t, tc.ExpectClash, reason != "")
+		})
+	}
+}
@@ -17,13 +17,15 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"io/ioutil"
 	"os"
 
 	"sigs.k8s.io/kind/pkg/errors"
 )
 
 // RemoveKIND removes the kind cluster kindClusterName from the KUBECONFIG
-// files at configPaths
+// files at configPaths, encrypted kubeconfigs (see WriteEncrypted) cannot be
+// read without their key and are left as is
 func RemoveKIND(kindClusterName string, explicitPath string) error {
 	// remove kind from each if present
 	for _, configPath := range paths(explicitPath, os.Getenv) {
@@ -36,6 +38,10 @@ func RemoveKIND(kindClusterName string, explicitPath string) error {
 				_ = unlockFile(configPath)
 			}(configPath)
 
+			if raw, err := ioutil.ReadFile(configPath); err == nil && IsEncrypted(raw) {
+				return nil
+			}
+
 			// read in existing
 			existing, err := read(configPath)
 			if err != nil {
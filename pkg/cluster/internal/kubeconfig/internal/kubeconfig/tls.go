@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"encoding/base64"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+const (
+	certificateAuthorityDataKey = "certificate-authority-data"
+	certificateAuthorityKey     = "certificate-authority"
+	insecureSkipTLSVerifyKey    = "insecure-skip-tls-verify"
+	clientCertificateDataKey    = "client-certificate-data"
+	clientKeyDataKey            = "client-key-data"
+)
+
+// ClientCredentials returns the decoded certificate authority embedded in
+// cluster and the client certificate and key embedded in user
+func ClientCredentials(cluster *Cluster, user map[string]interface{}) (ca, cert, key []byte, err error) {
+	if ca, err = decodeField(cluster.OtherFields, certificateAuthorityDataKey); err != nil {
+		return nil, nil, nil, err
+	}
+	if cert, err = decodeField(user, clientCertificateDataKey); err != nil {
+		return nil, nil, nil, err
+	}
+	if key, err = decodeField(user, clientKeyDataKey); err != nil {
+		return nil, nil, nil, err
+	}
+	return ca, cert, key, nil
+}
+
+// decodeField returns the base64 decoded value of the embedded data field key
+func decodeField(fields map[string]interface{}, key string) ([]byte, error) {
+	data, ok := fields[key].(string)
+	if !ok || data == "" {
+		return nil, errors.Errorf("kubeconfig has no embedded %s", key)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode kubeconfig %s", key)
+	}
+	return decoded, nil
+}
+
+// ReferenceCA replaces the certificate authority data embedded in cluster
+// with a reference to caPath, returning the decoded certificate authority
+// which the caller must write to caPath
+func ReferenceCA(cluster *Cluster, caPath string) ([]byte, error) {
+	data, ok := cluster.OtherFields[certificateAuthorityDataKey].(string)
+	if !ok || data == "" {
+		return nil, errors.New("kubeconfig cluster has no embedded certificate authority data")
+	}
+	ca, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode kubeconfig certificate authority data")
+	}
+	delete(cluster.OtherFields, certificateAuthorityDataKey)
+	cluster.OtherFields[certificateAuthorityKey] = caPath
+	return ca, nil
+}
+
+// SkipTLSVerify configures cluster to not verify the server's certificate,
+// removing the certificate authority as kubectl rejects setting both
+func SkipTLSVerify(cluster *Cluster) {
+	if cluster.OtherFields == nil {
+		cluster.OtherFields = map[string]interface{}{}
+	}
+	delete(cluster.OtherFields, certificateAuthorityDataKey)
+	delete(cluster.OtherFields, certificateAuthorityKey)
+	cluster.OtherFields[insecureSkipTLSVerifyKey] = true
+}
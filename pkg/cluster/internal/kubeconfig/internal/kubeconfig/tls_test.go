@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestReferenceCA(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name           string
+		Cluster        Cluster
+		ExpectedCA     []byte
+		ExpectedFields map[string]interface{}
+		ExpectError    bool
+	}{
+		{
+			Name: "embedded CA",
+			Cluster: Cluster{
+				Server: "https://127.0.0.1:6443",
+				OtherFields: map[string]interface{}{
+					"certificate-authority-data": "ZmFrZSBjYQ==",
+				},
+			},
+			ExpectedCA: []byte("fake ca"),
+			ExpectedFields: map[string]interface{}{
+				"certificate-authority": "/etc/kind/ca.crt",
+			},
+		},
+		{
+			Name: "no embedded CA",
+			Cluster: Cluster{
+				Server: "https://127.0.0.1:6443",
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "invalid embedded CA",
+			Cluster: Cluster{
+				OtherFields: map[string]interface{}{
+					"certificate-authority-data": "not base64!",
+				},
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			ca, err := ReferenceCA(&tc.Cluster, "/etc/kind/ca.crt")
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.ExpectedCA, ca)
+				assert.DeepEqual(t, tc.ExpectedFields, tc.Cluster.OtherFields)
+			}
+		})
+	}
+}
+
+func TestSkipTLSVerify(t *testing.T) {
+	t.Parallel()
+	cluster := Cluster{
+		Server: "https://127.0.0.1:6443",
+		OtherFields: map[string]interface{}{
+			"certificate-authority-data": "ZmFrZSBjYQ==",
+		},
+	}
+	SkipTLSVerify(&cluster)
+	assert.DeepEqual(t, map[string]interface{}{"insecure-skip-tls-verify": true}, cluster.OtherFields)
+}
+
+func TestClientCredentials(t *testing.T) {
+	t.Parallel()
+	cluster := Cluster{
+		OtherFields: map[string]interface{}{
+			"certificate-authority-data": "ZmFrZSBjYQ==",
+		},
+	}
+	user := map[string]interface{}{
+		"client-certificate-data": "ZmFrZSBjZXJ0",
+		"client-key-data":         "ZmFrZSBrZXk=",
+	}
+	ca, cert, key, err := ClientCredentials(&cluster, user)
+	assert.ExpectError(t, false, err)
+	assert.DeepEqual(t, []byte("fake ca"), ca)
+	assert.DeepEqual(t, []byte("fake cert"), cert)
+	assert.DeepEqual(t, []byte("fake key"), key)
+
+	_, _, _, err = ClientCredentials(&cluster, map[string]interface{}{
+		"client-certificate-data": "ZmFrZSBjZXJ0",
+	})
+	assert.ExpectError(t, true, err)
+}
@@ -24,6 +24,29 @@ import (
 	"sigs.k8s.io/kind/pkg/errors"
 )
 
+// WriteStandalone writes a kind kubeconfig (see KINDFromRawKubeadm) to
+// configPath, replacing any existing contents rather than merging with them,
+// such that the file contains only the kind cluster, context, and user.
+func WriteStandalone(kindConfig *Config, configPath string) error {
+	if configPath == "" {
+		return errors.New("a standalone kubeconfig requires an explicit path")
+	}
+	// verify assumptions about kubeadm / kind kubeconfigs
+	if err := checkKubeadmExpectations(kindConfig); err != nil {
+		return err
+	}
+
+	// lock config file the same as client-go
+	if err := lockFile(configPath); err != nil {
+		return errors.Wrap(err, "failed to lock config file")
+	}
+	defer func() {
+		_ = unlockFile(configPath)
+	}()
+
+	return write(kindConfig, configPath)
+}
+
 // write writes cfg to configPath
 // it will ensure the directories in the path if necessary
 func write(cfg *Config, configPath string) error {
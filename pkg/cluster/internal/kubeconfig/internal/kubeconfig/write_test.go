@@ -111,3 +111,37 @@ users:
 `
 	assert.StringEqual(t, expected, string(contents))
 }
+
+func TestWriteStandalone(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-testwritestandalone")
+	if err != nil {
+		t.Fatalf("Failed to create tempdir: %d", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// an explicit path is required
+	assert.ExpectError(t, true, WriteStandalone(&Config{}, ""))
+
+	// existing contents should be replaced, not merged
+	configPath := filepath.Join(dir, "kubeconfig")
+	if err := ioutil.WriteFile(configPath, []byte("current-context: other\n"), 0600); err != nil {
+		t.Fatalf("Failed to write existing kubeconfig: %v", err)
+	}
+	kindConfig := &Config{
+		Clusters: []NamedCluster{{Name: "kind-kind"}},
+		Contexts: []NamedContext{{Name: "kind-kind"}},
+		Users:    []NamedUser{{Name: "kind-kind"}},
+
+		CurrentContext: "kind-kind",
+	}
+	assert.ExpectError(t, false, WriteStandalone(kindConfig, configPath))
+	written, err := read(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read written kubeconfig: %v", err)
+	}
+	assert.StringEqual(t, "kind-kind", written.CurrentContext)
+	if len(written.Clusters) != 1 || len(written.Contexts) != 1 || len(written.Users) != 1 {
+		t.Errorf("expected exactly one cluster, context, and user but got: %+v", written)
+	}
+}
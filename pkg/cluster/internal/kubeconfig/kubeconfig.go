@@ -20,6 +20,9 @@ package kubeconfig
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/errors"
@@ -33,11 +36,143 @@ import (
 // Export exports the kubeconfig given the cluster context and a path to write it to
 // This will always be an external kubeconfig
 func Export(p providers.Provider, name, explicitPath string) error {
+	return ExportWithOptions(p, name, explicitPath, ExportOptions{})
+}
+
+// ExportStandalone exports the kubeconfig to explicitPath, replacing the file
+// with one containing only this cluster rather than merging into it
+// This will always be an external kubeconfig
+func ExportStandalone(p providers.Provider, name, explicitPath string) error {
+	return ExportWithOptions(p, name, explicitPath, ExportOptions{Standalone: true})
+}
+
+// ExportOptions are options for ExportWithOptions
+type ExportOptions struct {
+	// Standalone replaces the file at the explicit path with one containing
+	// only this cluster rather than merging into it
+	Standalone bool
+	// CAPath is a host path the cluster CA is written to if set, which the
+	// kubeconfig then references instead of embedding the CA
+	CAPath string
+	// InsecureSkipTLSVerify disables verifying the API server certificate,
+	// the kubeconfig then has no CA at all
+	InsecureSkipTLSVerify bool
+	// EncryptionKey encrypts the kubeconfig with this key if set, see
+	// ParseEncryptionKey, the encrypted kubeconfig replaces the file at the
+	// explicit path like Standalone
+	EncryptionKey []byte
+	// OnContextClash is called with the context name and how it differs if
+	// merging replaces an existing context pointing at another cluster
+	OnContextClash func(context, reason string)
+}
+
+// ExportWithOptions exports the kubeconfig given the cluster context and a
+// path to write it to per opts
+// This will always be an external kubeconfig
+func ExportWithOptions(p providers.Provider, name, explicitPath string, opts ExportOptions) error {
 	cfg, err := get(p, name, true)
 	if err != nil {
 		return err
 	}
-	return kubeconfig.WriteMerged(cfg, explicitPath)
+	if opts.CAPath != "" {
+		if err := writeCA(&cfg.Clusters[0].Cluster, opts.CAPath); err != nil {
+			return err
+		}
+	}
+	if opts.InsecureSkipTLSVerify {
+		kubeconfig.SkipTLSVerify(&cfg.Clusters[0].Cluster)
+	}
+	if opts.EncryptionKey != nil {
+		return kubeconfig.WriteEncrypted(cfg, explicitPath, opts.EncryptionKey)
+	}
+	if opts.Standalone {
+		return kubeconfig.WriteStandalone(cfg, explicitPath)
+	}
+	return kubeconfig.WriteMerged(cfg, explicitPath, opts.OnContextClash)
+}
+
+// writeCA writes the CA embedded in cluster to caPath and references it
+// from cluster instead
+func writeCA(cluster *kubeconfig.Cluster, caPath string) error {
+	// the kubeconfig may be used from any working directory
+	caPath, err := filepath.Abs(caPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve the cluster CA path")
+	}
+	ca, err := kubeconfig.ReferenceCA(cluster, caPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(caPath), os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create the cluster CA directory")
+	}
+	if err := ioutil.WriteFile(caPath, ca, 0644); err != nil {
+		return errors.Wrap(err, "failed to write the cluster CA")
+	}
+	return nil
+}
+
+// CredentialPaths are the host paths ExportCredentials writes to, each is
+// skipped if unset
+type CredentialPaths struct {
+	// CA is the path of the cluster CA certificate
+	CA string
+	// ClientCert and ClientKey are the paths of the admin client certificate
+	// and key, the key is only readable by the current user
+	ClientCert string
+	ClientKey  string
+}
+
+// ExportCredentials writes the cluster CA and admin client certificate and
+// key from the cluster's admin kubeconfig to paths
+func ExportCredentials(p providers.Provider, name string, paths CredentialPaths) error {
+	cfg, err := get(p, name, true)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Users) < 1 {
+		return errors.New("kubeconfig has no users")
+	}
+	ca, cert, key, err := kubeconfig.ClientCredentials(&cfg.Clusters[0].Cluster, cfg.Users[0].User)
+	if err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		path     string
+		contents []byte
+		perm     os.FileMode
+	}{
+		{paths.CA, ca, 0644},
+		{paths.ClientCert, cert, 0644},
+		{paths.ClientKey, key, 0600},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if err := ioutil.WriteFile(f.path, f.contents, f.perm); err != nil {
+			return errors.Wrap(err, "failed to write cluster credentials")
+		}
+		// WriteFile does not change the permissions of an existing file
+		if err := os.Chmod(f.path, f.perm); err != nil {
+			return errors.Wrap(err, "failed to write cluster credentials")
+		}
+	}
+	return nil
+}
+
+// ParseEncryptionKey parses a kubeconfig encryption key, which must be 32
+// bytes hex or base64 encoded, for ExportOptions.EncryptionKey
+func ParseEncryptionKey(key string) ([]byte, error) {
+	return kubeconfig.ParseEncryptionKey(key)
+}
+
+// Decrypt returns the kubeconfig encrypted with key by ExportWithOptions
+func Decrypt(encrypted []byte, key string) ([]byte, error) {
+	k, err := kubeconfig.ParseEncryptionKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return kubeconfig.Decrypt(encrypted, k)
 }
 
 // Remove removes clusterName from the kubeconfig paths detected based on
@@ -90,15 +225,31 @@ func get(p providers.Provider, name string, external bool) (*kubeconfig.Config,
 	}
 
 	// if we're doing external we need to override the server endpoint
-	server := ""
-	if external {
-		endpoint, err := p.GetAPIServerEndpoint(name)
+	// unless there are multiple control planes without a kind managed load
+	// balancer, in which case the load balancer is external to kind and the
+	// kubeadm config already points at it
+	lb, err := nodeutils.ExternalLoadBalancerNode(n)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := kubeconfig.KINDFromRawKubeadm(buff.String(), name, "")
+	if err != nil {
+		return nil, err
+	}
+	if external && (lb != nil || len(nodes) == 1) {
+		// an explicitly configured control plane endpoint is not ours to
+		// replace, only the provider's internal endpoint is overridden
+		internal, err := p.GetAPIServerInternalEndpoint(name)
 		if err != nil {
 			return nil, err
 		}
-		server = "https://" + endpoint
+		if cfg.Clusters[0].Cluster.Server == "https://"+internal {
+			endpoint, err := p.GetAPIServerEndpoint(name)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Clusters[0].Cluster.Server = "https://" + endpoint
+		}
 	}
-
-	// actually encode
-	return kubeconfig.KINDFromRawKubeadm(buff.String(), name, server)
+	return cfg, nil
 }
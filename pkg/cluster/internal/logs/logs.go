@@ -18,6 +18,7 @@ package logs
 
 import (
 	"archive/tar"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -104,3 +105,59 @@ func untar(logger log.Logger, r io.Reader, dir string) (err error) {
 		}
 	}
 }
+
+// ArchiveDir writes a gzip compressed tarball of the contents of dir to the
+// file at archivePath, with all entries nested under prefix in the archive
+func ArchiveDir(dir, prefix, archivePath string) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// only regular files and directories are collected
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rf, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer rf.Close()
+		_, err = io.Copy(tw, rf)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to archive %q", dir)
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
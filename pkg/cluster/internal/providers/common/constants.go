@@ -16,6 +16,22 @@ limitations under the License.
 
 package common
 
+import "time"
+
 // APIServerInternalPort defines the port where the control plane is listening
 // _inside_ the node network
 const APIServerInternalPort = 6443
+
+// DefaultImagePullTimeout bounds pulling each node image, including retries,
+// when ProvisionOptions.ImagePullTimeout is not set
+const DefaultImagePullTimeout = 20 * time.Minute
+
+// LocalRegistryInternalPort defines the port where the cluster's local image
+// registry is listening _inside_ the node network
+const LocalRegistryInternalPort = 5000
+
+// LocalRegistryName returns the name of the cluster's local image registry
+// container, which is also its hostname inside the node network
+func LocalRegistryName(cluster string) string {
+	return cluster + "-registry"
+}
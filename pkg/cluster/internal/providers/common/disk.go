@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+)
+
+// FreeDiskSpace returns the free space in bytes on the filesystem containing
+// path on the host, using the POSIX `df` output format
+func FreeDiskSpace(path string) (uint64, error) {
+	lines, err := exec.OutputLines(exec.Command("df", "-Pk", path))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get free disk space for %q", path)
+	}
+	return parseDfAvailable(lines)
+}
+
+// parseDfAvailable parses the available bytes from `df -Pk` output lines of
+// the form:
+// Filesystem     1024-blocks      Used Available Capacity Mounted on
+// /dev/sda1        102687672  51955272  45473136      54% /
+func parseDfAvailable(lines []string) (uint64, error) {
+	if len(lines) != 2 {
+		return 0, errors.Errorf("expected 2 lines of df output, got %d", len(lines))
+	}
+	fields := strings.Fields(lines[1])
+	if len(fields) < 6 {
+		return 0, errors.Errorf("unexpected df output: %q", lines[1])
+	}
+	available, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unexpected df output: %q", lines[1])
+	}
+	return available * 1024, nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseDfAvailable(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Name        string
+		Lines       []string
+		Expected    uint64
+		ExpectError bool
+	}{
+		{
+			Name: "linux",
+			Lines: []string{
+				"Filesystem     1024-blocks      Used Available Capacity Mounted on",
+				"/dev/sda1        102687672  51955272  45473136      54% /",
+			},
+			Expected: 45473136 * 1024,
+		},
+		{
+			Name: "mount point with spaces",
+			Lines: []string{
+				"Filesystem 1024-blocks Used Available Capacity Mounted on",
+				"/dev/disk1s1 488245288 1000 2048 1% /Volumes/My Disk",
+			},
+			Expected: 2048 * 1024,
+		},
+		{
+			Name:        "no output",
+			ExpectError: true,
+		},
+		{
+			Name: "bogus available",
+			Lines: []string{
+				"Filesystem     1024-blocks      Used Available Capacity Mounted on",
+				"/dev/sda1        102687672  51955272  lots      54% /",
+			},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseDfAvailable(tc.Lines)
+			assert.ExpectError(t, tc.ExpectError, err)
+			assert.DeepEqual(t, tc.Expected, result)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"path"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// DefaultKubeletRootDir is the kubelet's default --root-dir
+const DefaultKubeletRootDir = "/var/lib/kubelet"
+
+// ValidateKubeletRootDir returns an error if dir is not usable as the
+// kubelet's root directory in the nodes, the empty string is the default
+func ValidateKubeletRootDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if !path.IsAbs(dir) || path.Clean(dir) != dir {
+		return errors.Errorf("invalid kubelet root dir %q: must be a clean absolute path", dir)
+	}
+	if dir == "/" || dir == "/tmp" || dir == "/run" || dir == "/var" {
+		return errors.Errorf("invalid kubelet root dir %q: must not be %s itself", dir, dir)
+	}
+	return nil
+}
+
+// KubeletRootDirArgs returns the node container run args for the kubelet
+// root directory dir, these are supported by both docker and podman.
+// The kubelet needs its root dir to be on a volume rather than the container
+// filesystem for mount propagation, /var is always a volume.
+func KubeletRootDirArgs(dir string) []string {
+	if dir == "" || strings.HasPrefix(dir, "/var/") {
+		return nil
+	}
+	return []string{"--volume", dir}
+}
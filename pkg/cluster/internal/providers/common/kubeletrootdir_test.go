@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateKubeletRootDir(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Dir         string
+		ExpectError bool
+	}{
+		{Dir: ""},
+		{Dir: DefaultKubeletRootDir},
+		{Dir: "/data/kubelet"},
+		{Dir: "data/kubelet", ExpectError: true},
+		{Dir: "/data/kubelet/", ExpectError: true},
+		{Dir: "/data/../kubelet", ExpectError: true},
+		{Dir: "/", ExpectError: true},
+		{Dir: "/var", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Dir, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateKubeletRootDir(tc.Dir))
+		})
+	}
+}
+
+func TestKubeletRootDirArgs(t *testing.T) {
+	t.Parallel()
+	assert.DeepEqual(t, []string(nil), KubeletRootDirArgs(""))
+	assert.DeepEqual(t, []string(nil), KubeletRootDirArgs("/var/lib/kubelet-test"))
+	assert.DeepEqual(t, []string{"--volume", "/data/kubelet"}, KubeletRootDirArgs("/data/kubelet"))
+}
@@ -17,7 +17,12 @@ limitations under the License.
 package common
 
 import (
+	"bytes"
 	"fmt"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // MakeNodeNamer returns a func(role string)(nodeName string)
@@ -35,3 +40,67 @@ func MakeNodeNamer(clusterName string) func(string) string {
 		return fmt.Sprintf("%s-%s%s", clusterName, role, suffix)
 	}
 }
+
+// NodeNameData is the data a node name template is rendered with
+type NodeNameData struct {
+	// Cluster is the cluster name
+	Cluster string
+	// Role is the node role, E.G. "control-plane"
+	Role string
+	// Index is the 1-based index of the node amongst the nodes with the same role
+	Index int
+}
+
+// MakeTemplateNodeNamer returns a func(role string)(nodeName string, err error)
+// used to name nodes by rendering nodeNameTemplate with NodeNameData in the
+// same order as MakeNodeNamer, if nodeNameTemplate is empty the names are
+// those from MakeNodeNamer
+func MakeTemplateNodeNamer(clusterName, nodeNameTemplate string) (func(string) (string, error), error) {
+	if nodeNameTemplate == "" {
+		nodeNamer := MakeNodeNamer(clusterName)
+		return func(role string) (string, error) {
+			return nodeNamer(role), nil
+		}, nil
+	}
+	t, err := template.New("node-name").Option("missingkey=error").Parse(nodeNameTemplate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid node name template %q", nodeNameTemplate)
+	}
+	counter := make(map[string]int)
+	return func(role string) (string, error) {
+		counter[role]++
+		var buff bytes.Buffer
+		if err := t.Execute(&buff, NodeNameData{
+			Cluster: clusterName,
+			Role:    role,
+			Index:   counter[role],
+		}); err != nil {
+			return "", errors.Wrapf(err, "failed to render node name template %q", nodeNameTemplate)
+		}
+		return buff.String(), nil
+	}, nil
+}
+
+// ConfigNode returns the node in cfg that the node named nodeName was created
+// from, given the template the nodes were named from, see
+// MakeTemplateNodeNamer
+func ConfigNode(cfg *config.Cluster, nodeNameTemplate, nodeName string) (*config.Node, error) {
+	// TODO: gross hack!
+	// identify node in config by matching name (since these are named in order)
+	// we should really just streamline the bootstrap code and maintain
+	// this mapping ... something for the next major refactor
+	nodeNamer, err := MakeTemplateNodeNamer(cfg.Name, nodeNameTemplate)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cfg.Nodes {
+		name, err := nodeNamer(string(cfg.Nodes[i].Role))
+		if err != nil {
+			return nil, err
+		}
+		if name == nodeName {
+			return &cfg.Nodes[i], nil
+		}
+	}
+	return nil, errors.Errorf("failed to match node %q to config", nodeName)
+}
@@ -62,3 +62,76 @@ func TestMakeNodeNamer(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeTemplateNodeNamer(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name        string
+		clusterName string
+		template    string
+		nodes       []string // list of role nodes that belong to the cluster
+		want        []string
+		expectError bool
+	}{
+		{
+			name:        "Empty template uses the default names",
+			clusterName: "kind",
+			nodes:       []string{"control-plane", "worker", "worker"},
+			want:        []string{"kind-control-plane", "kind-worker", "kind-worker2"},
+		},
+		{
+			name:        "Template with all placeholders",
+			clusterName: "golden",
+			template:    "{{.Cluster}}-{{.Role}}-{{.Index}}",
+			nodes:       []string{"control-plane", "worker", "control-plane", "worker"},
+			want:        []string{"golden-control-plane-1", "golden-worker-1", "golden-control-plane-2", "golden-worker-2"},
+		},
+		{
+			name:        "Template without the role",
+			clusterName: "golden",
+			template:    "node-{{.Index}}",
+			nodes:       []string{"control-plane", "worker"},
+			want:        []string{"node-1", "node-1"},
+		},
+		{
+			name:        "Invalid template",
+			clusterName: "kind",
+			template:    "{{.Cluster",
+			expectError: true,
+		},
+		{
+			name:        "Unknown placeholder",
+			clusterName: "kind",
+			template:    "{{.Zone}}",
+			nodes:       []string{"control-plane"},
+			expectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			names, err := renderNames(tc.clusterName, tc.template, tc.nodes)
+			assert.ExpectError(t, tc.expectError, err)
+			if err == nil {
+				assert.DeepEqual(t, tc.want, names)
+			}
+		})
+	}
+}
+
+func renderNames(clusterName, nodeNameTemplate string, roles []string) ([]string, error) {
+	nodeNamer, err := MakeTemplateNodeNamer(clusterName, nodeNameTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, role := range roles {
+		name, err := nodeNamer(role)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+)
+
+// RegistryCacheInternalPort is the port where a registry cache is listening
+// _inside_ the node network
+const RegistryCacheInternalPort = 5000
+
+// RegistryCacheUpstream is the registry a registry cache pulls through from,
+// the registry only supports proxying a single upstream
+const RegistryCacheUpstream = "https://registry-1.docker.io"
+
+// RegistryCacheName returns the name of the pull through registry cache
+// container storing its content in the host directory cacheDir, which is
+// also its hostname inside the node network. cacheDir should be absolute,
+// every cluster sharing cacheDir shares the same registry cache.
+func RegistryCacheName(cacheDir string) string {
+	sum := sha1.Sum([]byte(cacheDir))
+	return "kind-registry-cache-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// RegistryCacheContainerdConfigPatch returns the containerd config patch for
+// the nodes to pull docker.io images through the registry cache for
+// cacheDir, containerd falls back to docker.io if the cache is unreachable
+func RegistryCacheContainerdConfigPatch(cacheDir string) string {
+	return fmt.Sprintf(`[plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+  endpoint = ["http://%s:%d", %q]
+`, RegistryCacheName(cacheDir), RegistryCacheInternalPort, RegistryCacheUpstream)
+}
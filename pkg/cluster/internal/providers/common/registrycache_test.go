@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestRegistryCacheName(t *testing.T) {
+	t.Parallel()
+	name := RegistryCacheName("/var/cache/kind")
+	assert.StringEqual(t, name, RegistryCacheName("/var/cache/kind"))
+	assert.BoolEqual(t, false, name == RegistryCacheName("/var/cache/other"))
+	assert.BoolEqual(t, true, strings.HasPrefix(name, "kind-registry-cache-"))
+	// container names are also used as hostnames
+	assert.BoolEqual(t, true, len(name) <= 63)
+}
+
+func TestRegistryCacheContainerdConfigPatch(t *testing.T) {
+	t.Parallel()
+	patch := RegistryCacheContainerdConfigPatch("/var/cache/kind")
+	assert.BoolEqual(t, true, strings.Contains(patch, `registry.mirrors."docker.io"`))
+	assert.BoolEqual(t, true, strings.Contains(patch, "http://"+RegistryCacheName("/var/cache/kind")+":5000"))
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// portReservationTTL is how long a port reservation is honored for, after
+// which it is assumed the process holding it exited without releasing it
+const portReservationTTL = 10 * time.Minute
+
+// maxReservePortAttempts bounds how many free ports ReserveFreePort tries
+// before giving up because they are all reserved
+const maxReservePortAttempts = 20
+
+// ReserveFreePort is like GetFreePort, but also reserves the port host-wide
+// so that kind processes creating clusters concurrently do not pick the same
+// port before it is bound by a node.
+// The returned release func must be called once the port is bound.
+func ReserveFreePort(listenAddr string) (int32, func(), error) {
+	return reserveFreePort(filepath.Join(os.TempDir(), "kind-port-reservations"), listenAddr)
+}
+
+func reserveFreePort(dir, listenAddr string) (int32, func(), error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return 0, nil, errors.Wrap(err, "failed to create port reservation dir")
+	}
+	for i := 0; i < maxReservePortAttempts; i++ {
+		port, err := GetFreePort(listenAddr)
+		if err != nil {
+			return 0, nil, err
+		}
+		path := filepath.Join(dir, strconv.Itoa(int(port)))
+		reserved, err := createReservation(path)
+		if err != nil {
+			return 0, nil, err
+		}
+		if reserved {
+			return port, func() { _ = os.Remove(path) }, nil
+		}
+	}
+	return 0, nil, errors.Errorf("failed to reserve a free port after %d attempts", maxReservePortAttempts)
+}
+
+// createReservation creates the reservation file at path, returning false
+// if it is already held by another process
+func createReservation(path string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+	if err == nil {
+		return true, f.Close()
+	}
+	if !os.IsExist(err) {
+		return false, errors.Wrap(err, "failed to reserve port")
+	}
+	// take over stale reservations
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) < portReservationTTL {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return false, nil
+	}
+	f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+	if err != nil {
+		return false, nil
+	}
+	return true, f.Close()
+}
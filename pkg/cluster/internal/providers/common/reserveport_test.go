@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReserveFreePort(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-reserve-port")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	port, release, err := reserveFreePort(dir, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("unexpected error reserving port: %v", err)
+	}
+	path := filepath.Join(dir, strconv.Itoa(int(port)))
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected port %d to be reserved: %v", port, err)
+	}
+	// a reserved port cannot be reserved again
+	reserved, err := createReservation(path)
+	if err != nil || reserved {
+		t.Errorf("expected reserving port %d again to fail, got %v, %v", port, reserved, err)
+	}
+	release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected port %d reservation to be released: %v", port, err)
+	}
+}
+
+func TestCreateReservationStale(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kind-reserve-port")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "12345")
+	if err := ioutil.WriteFile(path, nil, 0666); err != nil {
+		t.Fatalf("failed to write reservation: %v", err)
+	}
+	stale := time.Now().Add(-2 * portReservationTTL)
+	if err := os.Chtimes(path, stale, stale); err != nil {
+		t.Fatalf("failed to age reservation: %v", err)
+	}
+	reserved, err := createReservation(path)
+	if err != nil || !reserved {
+		t.Errorf("expected to take over stale reservation, got %v, %v", reserved, err)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ValidateRestartPolicy returns an error if policy is not a container restart
+// policy supported by both docker and podman, one of:
+// no, always, unless-stopped, on-failure, or on-failure:<max-retries>
+func ValidateRestartPolicy(policy string) error {
+	switch policy {
+	case "no", "always", "unless-stopped", "on-failure":
+		return nil
+	}
+	if retries := strings.TrimPrefix(policy, "on-failure:"); retries != policy {
+		if n, err := strconv.Atoi(retries); err == nil && n >= 0 {
+			return nil
+		}
+	}
+	return errors.Errorf("invalid restart policy %q, must be one of: no, always, unless-stopped, on-failure[:max-retries]", policy)
+}
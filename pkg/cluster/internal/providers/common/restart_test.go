@@ -0,0 +1,48 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateRestartPolicy(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Policy      string
+		ExpectError bool
+	}{
+		{Policy: "no"},
+		{Policy: "always"},
+		{Policy: "unless-stopped"},
+		{Policy: "on-failure"},
+		{Policy: "on-failure:3"},
+		{Policy: "on-failure:", ExpectError: true},
+		{Policy: "on-failure:-1", ExpectError: true},
+		{Policy: "sometimes", ExpectError: true},
+		{Policy: "", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Policy, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateRestartPolicy(tc.Policy))
+		})
+	}
+}
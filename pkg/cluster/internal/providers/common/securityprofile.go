@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// SecurityProfileHardened runs the node containers with a read-only root
+// filesystem, only the paths kind and kubeadm need to write are writable.
+//
+// The node containers must still be privileged to run containerd and the
+// kubelet, so this limits what can be modified in the node image rather than
+// isolating the node from the host.
+// Anything else writing outside of these paths will fail, E.G. CNIs that
+// install their plugins to /opt/cni/bin.
+const SecurityProfileHardened = "hardened"
+
+// hardenedWritablePaths are given anonymous volumes under
+// SecurityProfileHardened, these are populated from the node image.
+// /var, /tmp and /run are always writable.
+var hardenedWritablePaths = []string{
+	"/etc",                             // kubeadm, containerd and CA certificates
+	"/kind",                            // kubeadm config and patches
+	"/root",                            // kubectl cache
+	"/usr/local/share/ca-certificates", // extra CA certificates
+}
+
+// ValidateSecurityProfile returns an error if profile is not a known node
+// container security profile, the empty string is the default profile
+func ValidateSecurityProfile(profile string) error {
+	switch profile {
+	case "", SecurityProfileHardened:
+		return nil
+	}
+	return errors.Errorf("invalid security profile %q, the only supported profile is %q", profile, SecurityProfileHardened)
+}
+
+// SecurityProfileArgs returns the node container run args for profile,
+// these are supported by both docker and podman
+func SecurityProfileArgs(profile string) []string {
+	if profile != SecurityProfileHardened {
+		return nil
+	}
+	args := []string{"--read-only"}
+	for _, path := range hardenedWritablePaths {
+		args = append(args, "--volume", path)
+	}
+	return args
+}
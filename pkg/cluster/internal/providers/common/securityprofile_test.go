@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateSecurityProfile(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Profile     string
+		ExpectError bool
+	}{
+		{Profile: ""},
+		{Profile: SecurityProfileHardened},
+		{Profile: "unprivileged", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Profile, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateSecurityProfile(tc.Profile))
+		})
+	}
+}
+
+func TestSecurityProfileArgs(t *testing.T) {
+	t.Parallel()
+	if args := SecurityProfileArgs(""); len(args) != 0 {
+		t.Errorf("expected no args for the default profile, got %v", args)
+	}
+	args := SecurityProfileArgs(SecurityProfileHardened)
+	if len(args) == 0 || args[0] != "--read-only" {
+		t.Errorf("expected the hardened profile to make the root filesystem read-only, got %v", args)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"regexp"
+	"time"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// DefaultTimezone is the timezone of the nodes when it is not set, the node
+// images are built in UTC
+const DefaultTimezone = "UTC"
+
+// timezoneRE matches tz database names, E.G. UTC or America/New_York
+var timezoneRE = regexp.MustCompile(`^[A-Za-z][-+A-Za-z0-9_]*(/[-+A-Za-z0-9_]+)*$`)
+
+// ValidateTimezone returns an error if tz is not a tz database name, the
+// empty string is DefaultTimezone.
+// Names are looked up in the host's tz database when it has one, otherwise
+// only their form is checked
+func ValidateTimezone(tz string) error {
+	if tz == "" || tz == DefaultTimezone {
+		return nil
+	}
+	if !timezoneRE.MatchString(tz) {
+		return errors.Errorf("invalid timezone %q: must be a tz database name, E.G. America/New_York", tz)
+	}
+	if _, err := time.LoadLocation(tz); err != nil && hostHasTZDatabase() {
+		return errors.Errorf("invalid timezone %q: not in the tz database", tz)
+	}
+	return nil
+}
+
+// hostHasTZDatabase returns true if the host's tz database can be loaded
+func hostHasTZDatabase() bool {
+	_, err := time.LoadLocation("Europe/London")
+	return err == nil
+}
+
+// TimezoneArgs returns the node container run args for the timezone tz,
+// these are supported by both docker and podman.
+// TZ is honored by the processes in the nodes using the node image's tz
+// database, it does not change the timezone Kubernetes uses, E.G. CronJob
+// schedules are still in the kube-controller-manager's timezone
+func TimezoneArgs(tz string) []string {
+	if tz == "" {
+		return nil
+	}
+	return []string{"--env", "TZ=" + tz}
+}
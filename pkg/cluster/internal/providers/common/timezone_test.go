@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestValidateTimezone(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Timezone    string
+		ExpectError bool
+	}{
+		{Timezone: ""},
+		{Timezone: DefaultTimezone},
+		{Timezone: "America/New_York"},
+		{Timezone: "Etc/GMT+5"},
+		{Timezone: "../etc/passwd", ExpectError: true},
+		{Timezone: "America/New York", ExpectError: true},
+		{Timezone: "/UTC", ExpectError: true},
+		// only unknown if the host has a tz database to look it up in
+		{Timezone: "Mars/Olympus_Mons", ExpectError: hostHasTZDatabase()},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Timezone, func(t *testing.T) {
+			t.Parallel()
+			assert.ExpectError(t, tc.ExpectError, ValidateTimezone(tc.Timezone))
+		})
+	}
+}
+
+func TestTimezoneArgs(t *testing.T) {
+	t.Parallel()
+	assert.DeepEqual(t, []string(nil), TimezoneArgs(""))
+	assert.DeepEqual(t, []string{"--env", "TZ=Asia/Tokyo"}, TimezoneArgs("Asia/Tokyo"))
+}
@@ -22,3 +22,24 @@ const clusterLabelKey = "io.x-k8s.kind.cluster"
 // nodeRoleLabelKey is applied to each "node" docker container for categorization
 // of nodes by role
 const nodeRoleLabelKey = "io.x-k8s.kind.role"
+
+// registryLabelKey is applied to each local registry docker container created
+// by kind for identification, the value is the cluster name
+const registryLabelKey = "io.x-k8s.kind.registry"
+
+// hostAddressLabelKey is applied to each "node" docker container when the
+// address the host's published ports are reachable at is overridden
+const hostAddressLabelKey = "io.x-k8s.kind.host-address"
+
+// imageCacheLabelKey is applied to each "node" docker container using a
+// shared image cache, the value is the cache container's name
+const imageCacheLabelKey = "io.x-k8s.kind.image-cache"
+
+// imageCacheDirLabelKey is applied to each shared image cache docker
+// container created by kind for identification, the value is its host
+// directory
+const imageCacheDirLabelKey = "io.x-k8s.kind.image-cache-dir"
+
+// networkLabelKey is applied to each "node" docker container to record the
+// docker network it was created on, see commonArgs
+const networkLabelKey = "io.x-k8s.kind.network"
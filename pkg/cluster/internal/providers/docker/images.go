@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -32,13 +33,18 @@ import (
 
 // ensureNodeImages ensures that the node images used by the create
 // configuration are present
-func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster) error {
+// each image is pulled for at most timeout, see common.DefaultImagePullTimeout
+// and for platform if set, see pullIfNotPresent
+func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster, timeout time.Duration, platform string) error {
+	if timeout == 0 {
+		timeout = common.DefaultImagePullTimeout
+	}
 	// pull each required image
 	for _, image := range common.RequiredNodeImages(cfg).List() {
 		// prints user friendly message
 		friendlyImageName, image := sanitizeImage(image)
 		status.Start(fmt.Sprintf("Ensuring node image (%s) 🖼", friendlyImageName))
-		if _, err := pullIfNotPresent(logger, image, 4); err != nil {
+		if _, err := pullIfNotPresent(logger, image, platform, 4, timeout); err != nil {
 			status.End(false)
 			return err
 		}
@@ -47,32 +53,98 @@ func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster
 }
 
 // pullIfNotPresent will pull an image if it is not present locally
-// retrying up to retries times
+// retrying up to retries times for at most timeout
+// if platform is set the image must be present for it, or is pulled for it
+// and must be available for it
 // it returns true if it attempted to pull, and any errors from pulling
-func pullIfNotPresent(logger log.Logger, image string, retries int) (pulled bool, err error) {
+func pullIfNotPresent(logger log.Logger, image, platform string, retries int, timeout time.Duration) (pulled bool, err error) {
 	// TODO(bentheelder): switch most (all) of the logging here to debug level
 	// once we have configurable log levels
 	// if this did not return an error, then the image exists locally
-	cmd := exec.Command("docker", "inspect", "--type=image", image)
-	if err := cmd.Run(); err == nil {
-		logger.V(1).Infof("Image: %s present locally", image)
-		return false, nil
+	if present, err := imagePlatform(image); err == nil {
+		if platform == "" || samePlatform(present, platform) {
+			logger.V(1).Infof("Image: %s present locally", image)
+			return false, nil
+		}
+		logger.V(1).Infof("Image: %s present locally for %s, not %s", image, present, platform)
 	}
 	// otherwise try to pull it
-	return true, pull(logger, image, retries)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = pull(ctx, logger, image, platform, retries)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return true, errors.Errorf("timed out pulling image %q after %s, the image pull timeout may need to be increased for slow networks", image, timeout)
+	}
+	if err != nil || platform == "" {
+		return true, err
+	}
+	// a single platform image may be pulled regardless of the platform
+	present, err := imagePlatform(image)
+	if err != nil {
+		return true, err
+	}
+	if !samePlatform(present, platform) {
+		return true, errors.Errorf("image %q is not available for platform %q, only for %q", image, platform, present)
+	}
+	return true, nil
 }
 
-// pull pulls an image, retrying up to retries times
-func pull(logger log.Logger, image string, retries int) error {
+// imagePlatform returns the os/arch of the local image
+func imagePlatform(image string) (string, error) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "inspect", "--type=image", "--format={{.Os}}/{{.Architecture}}", image,
+	))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to inspect image %q", image)
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("failed to inspect image %q: unexpected output %q", image, lines)
+	}
+	return lines[0], nil
+}
+
+// samePlatform returns true if the os/arch[/variant] platforms a and b
+// have the same os and arch, variants are not recorded consistently
+func samePlatform(a, b string) bool {
+	a1 := strings.SplitN(a, "/", 3)
+	b1 := strings.SplitN(b, "/", 3)
+	return len(a1) >= 2 && len(b1) >= 2 && a1[0] == b1[0] && a1[1] == b1[1]
+}
+
+// warnIfEmulated warns that nodes for platform run much slower if it is not
+// the platform of the docker daemon, which must then emulate it
+func warnIfEmulated(logger log.Logger, platform string) {
+	lines, err := exec.OutputLines(exec.Command(
+		"docker", "version", "--format={{.Server.Os}}/{{.Server.Arch}}",
+	))
+	if err != nil || len(lines) != 1 || samePlatform(lines[0], platform) {
+		return
+	}
+	logger.Warnf("WARNING: the node platform %s is being emulated on %s", platform, lines[0])
+	logger.Warn("WARNING: emulated nodes are much slower, creating the cluster may take several times longer and time out")
+}
+
+// pull pulls an image, for platform if set, retrying up to retries times
+// until ctx is done
+func pull(ctx context.Context, logger log.Logger, image, platform string, retries int) error {
 	logger.V(1).Infof("Pulling image: %s ...", image)
-	err := exec.Command("docker", "pull", image).Run()
+	args := []string{"pull"}
+	if platform != "" {
+		args = append(args, "--platform="+platform)
+	}
+	args = append(args, image)
+	err := exec.CommandContext(ctx, "docker", args...).Run()
 	// retry pulling up to retries times if necessary
 	if err != nil {
 		for i := 0; i < retries; i++ {
-			time.Sleep(time.Second * time.Duration(i+1))
+			select {
+			case <-ctx.Done():
+				return errors.Wrapf(err, "failed to pull image %q", image)
+			case <-time.After(time.Second * time.Duration(i+1)):
+			}
 			logger.V(1).Infof("Trying again to pull image: %q ... %v", image, err)
 			// TODO(bentheelder): add some backoff / sleep?
-			err = exec.Command("docker", "pull", image).Run()
+			err = exec.CommandContext(ctx, "docker", args...).Run()
 			if err == nil {
 				break
 			}
@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+)
+
+func Test_samePlatform(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "linux/amd64", b: "linux/amd64", same: true},
+		{a: "linux/arm64", b: "linux/arm64/v8", same: true},
+		{a: "linux/arm64", b: "linux/amd64", same: false},
+		{a: "linux/arm/v7", b: "linux/arm64", same: false},
+		{a: "windows/amd64", b: "linux/amd64", same: false},
+		{a: "linux", b: "linux", same: false},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.a+" "+tc.b, func(t *testing.T) {
+			t.Parallel()
+			if same := samePlatform(tc.a, tc.b); same != tc.same {
+				t.Errorf("expected %v but got %v", tc.same, same)
+			}
+		})
+	}
+}
@@ -20,11 +20,15 @@ import (
 	"crypto/sha1"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
 	"sigs.k8s.io/kind/pkg/exec"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // This may be overridden by KIND_EXPERIMENTAL_DOCKER_NETWORK env,
@@ -40,24 +44,31 @@ import (
 // networks.
 const fixedNetworkName = "kind"
 
+// mtuOption is the docker bridge network driver option setting the MTU
+const mtuOption = "com.docker.network.driver.mtu"
+
 // ensureNetwork checks if docker network by name exists, if not it creates it
-func ensureNetwork(name string) error {
+// with mtu if non-zero, an existing network must already have mtu
+func ensureNetwork(name string, mtu int) error {
 	// TODO: the network might already exist and not have ipv6 ... :|
 	// discussion: https://github.com/kubernetes-sigs/kind/pull/1508#discussion_r414594198
 	exists, err := checkIfNetworkExists(name)
 	if err != nil {
 		return err
 	}
-	// network already exists, we're good
+	// network already exists, we're good if the MTU matches
 	if exists {
-		return nil
+		if mtu == 0 {
+			return nil
+		}
+		return checkNetworkMTU(name, mtu)
 	}
 
 	// Generate unique subnet per network based on the name
 	// obtained from the ULA fc00::/8 range
 	// Make N attempts with "probing" in case we happen to collide
 	subnet := generateULASubnetFromName(name, 0)
-	err = createNetwork(name, subnet)
+	err = createNetwork(name, subnet, mtu)
 	if err == nil {
 		// Success!
 		return nil
@@ -69,7 +80,7 @@ func ensureNetwork(name string) error {
 	// If it is, make more attempts below
 	if isIPv6UnavailableError(err) {
 		// only one attempt, IPAM is automatic in ipv4 only
-		return createNetwork(name, "")
+		return createNetwork(name, "", mtu)
 	} else if !isPoolOverlapError(err) {
 		// unknown error ...
 		return err
@@ -79,7 +90,7 @@ func ensureNetwork(name string) error {
 	const maxAttempts = 5
 	for attempt := int32(1); attempt < maxAttempts; attempt++ {
 		subnet := generateULASubnetFromName(name, attempt)
-		err = createNetwork(name, subnet)
+		err = createNetwork(name, subnet, mtu)
 		if err == nil {
 			// success!
 			return nil
@@ -91,15 +102,169 @@ func ensureNetwork(name string) error {
 	return errors.New("exhausted attempts trying to find a non-overlapping subnet")
 }
 
-func createNetwork(name, ipv6Subnet string) error {
-	if ipv6Subnet == "" {
-		return exec.Command("docker", "network", "create", "-d=bridge",
-			"-o", "com.docker.network.bridge.enable_ip_masquerade=true",
-			name).Run()
-	}
-	return exec.Command("docker", "network", "create", "-d=bridge",
+func createNetwork(name, ipv6Subnet string, mtu int) error {
+	args := []string{"network", "create", "-d=bridge",
 		"-o", "com.docker.network.bridge.enable_ip_masquerade=true",
-		"--ipv6", "--subnet", ipv6Subnet, name).Run()
+	}
+	if mtu > 0 {
+		args = append(args, "-o", fmt.Sprintf("%s=%d", mtuOption, mtu))
+	}
+	if ipv6Subnet != "" {
+		args = append(args, "--ipv6", "--subnet", ipv6Subnet)
+	}
+	return exec.Command("docker", append(args, name)...).Run()
+}
+
+// checkNetworkMTU returns an error if the existing network name does not
+// have mtu, the network is shared by every cluster so it is not recreated
+func checkNetworkMTU(name string, mtu int) error {
+	out, err := exec.Output(exec.Command(
+		"docker", "network", "inspect",
+		fmt.Sprintf(`--format={{ index .Options %q }}`, mtuOption),
+		name,
+	))
+	if err != nil {
+		return err
+	}
+	current := strings.TrimSpace(string(out))
+	if n, err := strconv.Atoi(current); err == nil && n == mtu {
+		return nil
+	}
+	if current == "" || current == "<no value>" {
+		current = "the daemon default"
+	}
+	return fmt.Errorf(
+		"docker network %q already exists with MTU %s instead of %d, delete the clusters using it and the network to recreate it",
+		name, current, mtu,
+	)
+}
+
+// checkExistingNetwork returns an error if the user managed docker network
+// id does not exist or is not compatible with cfg, kind only attaches the
+// nodes to it and never creates or deletes it
+func checkExistingNetwork(id string, cfg *config.Cluster, mtu int) error {
+	subnets, err := getSubnets(id)
+	if err != nil {
+		return fmt.Errorf("existing docker network %q not found: %v", id, err)
+	}
+	if err := checkNetworkSubnets(id, subnets, cfg); err != nil {
+		return err
+	}
+	if mtu == 0 {
+		return nil
+	}
+	return checkNetworkMTU(id, mtu)
+}
+
+// checkNetworkSubnets returns an error if the docker network id's subnets
+// have no subnet for cfg's IP family, or overlap its pod or service subnets
+func checkNetworkSubnets(id string, subnets []string, cfg *config.Cluster) error {
+	ipv6 := cfg.Networking.IPFamily == config.IPv6Family
+	var clusterSubnets []*net.IPNet
+	for _, s := range []string{cfg.Networking.PodSubnet, cfg.Networking.ServiceSubnet} {
+		if _, subnet, err := net.ParseCIDR(s); err == nil {
+			clusterSubnets = append(clusterSubnets, subnet)
+		}
+	}
+	hasFamily := false
+	for _, s := range subnets {
+		_, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			continue
+		}
+		if (subnet.IP.To4() == nil) == ipv6 {
+			hasFamily = true
+		}
+		for _, c := range clusterSubnets {
+			if subnet.Contains(c.IP) || c.Contains(subnet.IP) {
+				return fmt.Errorf("existing docker network %q subnet %s overlaps the cluster subnet %s", id, subnet, c)
+			}
+		}
+	}
+	if !hasFamily {
+		return fmt.Errorf("existing docker network %q has no %s subnet", id, cfg.Networking.IPFamily)
+	}
+	return nil
+}
+
+// ensureSecondaryNetworks creates the networks that do not exist yet, and
+// returns an error if any of their subnets overlap the primary network's
+func ensureSecondaryNetworks(primary string, networks []providers.NetworkSpec, mtu int) error {
+	if len(networks) == 0 {
+		return nil
+	}
+	primarySubnets, err := getSubnets(primary)
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		exists, err := checkIfNetworkExists(n.Name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if err := createSecondaryNetwork(n, mtu); err != nil {
+				return fmt.Errorf("failed to create secondary docker network %q: %v", n.Name, err)
+			}
+		}
+		subnets, err := getSubnets(n.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkSecondaryNetworkSubnets(n, subnets, primary, primarySubnets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createSecondaryNetwork(n providers.NetworkSpec, mtu int) error {
+	args := []string{"network", "create", "-d=bridge"}
+	if mtu > 0 {
+		args = append(args, "-o", fmt.Sprintf("%s=%d", mtuOption, mtu))
+	}
+	if n.Subnet != "" {
+		if _, subnet, err := net.ParseCIDR(n.Subnet); err == nil && subnet.IP.To4() == nil {
+			args = append(args, "--ipv6")
+		}
+		args = append(args, "--subnet", n.Subnet)
+	}
+	return exec.Command("docker", append(args, n.Name)...).Run()
+}
+
+// checkSecondaryNetworkSubnets returns an error if the secondary network n's
+// subnets overlap the primary network's subnets, or do not include
+// n.Subnet if set because the network already existed with another subnet
+func checkSecondaryNetworkSubnets(n providers.NetworkSpec, subnets []string, primary string, primarySubnets []string) error {
+	var primaryNets []*net.IPNet
+	for _, s := range primarySubnets {
+		if _, subnet, err := net.ParseCIDR(s); err == nil {
+			primaryNets = append(primaryNets, subnet)
+		}
+	}
+	var want string
+	if _, subnet, err := net.ParseCIDR(n.Subnet); err == nil {
+		want = subnet.String()
+	}
+	hasSubnet := want == ""
+	for _, s := range subnets {
+		_, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			continue
+		}
+		if subnet.String() == want {
+			hasSubnet = true
+		}
+		for _, p := range primaryNets {
+			if subnet.Contains(p.IP) || p.Contains(subnet.IP) {
+				return fmt.Errorf("secondary docker network %q subnet %s overlaps the docker network %q subnet %s", n.Name, subnet, primary, p)
+			}
+		}
+	}
+	if !hasSubnet {
+		return fmt.Errorf("secondary docker network %q already exists without the subnet %s", n.Name, n.Subnet)
+	}
+	return nil
 }
 
 func checkIfNetworkExists(name string) (bool, error) {
@@ -19,6 +19,9 @@ package docker
 import (
 	"fmt"
 	"testing"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 func Test_generateULASubnetFromName(t *testing.T) {
@@ -66,3 +69,112 @@ func Test_generateULASubnetFromName(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkNetworkSubnets(t *testing.T) {
+	t.Parallel()
+	ipv4 := &config.Cluster{}
+	config.SetDefaultsCluster(ipv4)
+	ipv6 := &config.Cluster{Networking: config.Networking{IPFamily: config.IPv6Family}}
+	config.SetDefaultsCluster(ipv6)
+	cases := []struct {
+		name        string
+		subnets     []string
+		cfg         *config.Cluster
+		expectError bool
+	}{
+		{
+			name:    "ipv4 subnet",
+			subnets: []string{"172.30.0.0/16"},
+			cfg:     ipv4,
+		},
+		{
+			name:    "dual stack subnets",
+			subnets: []string{"172.30.0.0/16", "fc00:f853:ccd:e793::/64"},
+			cfg:     ipv6,
+		},
+		{
+			name:        "no ipv6 subnet",
+			subnets:     []string{"172.30.0.0/16"},
+			cfg:         ipv6,
+			expectError: true,
+		},
+		{
+			name:        "no subnets",
+			subnets:     []string{""},
+			cfg:         ipv4,
+			expectError: true,
+		},
+		{
+			name:        "overlaps the pod subnet",
+			subnets:     []string{"10.244.0.0/24"},
+			cfg:         ipv4,
+			expectError: true,
+		},
+		{
+			name:        "contains the service subnet",
+			subnets:     []string{"10.0.0.0/8"},
+			cfg:         ipv4,
+			expectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := checkNetworkSubnets("test", tc.subnets, tc.cfg)
+			if (err != nil) != tc.expectError {
+				t.Errorf("expected error %v but got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func Test_checkSecondaryNetworkSubnets(t *testing.T) {
+	t.Parallel()
+	primary := []string{"172.18.0.0/16", "fc00:f853:ccd:e793::/64"}
+	cases := []struct {
+		name        string
+		network     providers.NetworkSpec
+		subnets     []string
+		expectError bool
+	}{
+		{
+			name:    "runtime picked subnet",
+			network: providers.NetworkSpec{Name: "net1"},
+			subnets: []string{"172.19.0.0/16"},
+		},
+		{
+			name:    "requested subnet",
+			network: providers.NetworkSpec{Name: "net1", Subnet: "10.10.0.1/24"},
+			subnets: []string{"10.10.0.0/24"},
+		},
+		{
+			name:        "existing network with another subnet",
+			network:     providers.NetworkSpec{Name: "net1", Subnet: "10.10.0.0/24"},
+			subnets:     []string{"10.20.0.0/24"},
+			expectError: true,
+		},
+		{
+			name:        "overlaps the primary network",
+			network:     providers.NetworkSpec{Name: "net1"},
+			subnets:     []string{"172.18.5.0/24"},
+			expectError: true,
+		},
+		{
+			name:        "overlaps the primary ipv6 subnet",
+			network:     providers.NetworkSpec{Name: "net1"},
+			subnets:     []string{"fc00:f853:ccd::/48"},
+			expectError: true,
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := checkSecondaryNetworkSubnets(tc.network, tc.subnets, "kind", primary)
+			if (err != nil) != tc.expectError {
+				t.Errorf("expected error %v but got: %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -59,22 +59,51 @@ func (p *provider) String() string {
 }
 
 // Provision is part of the providers.Provider interface
-func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error) {
+func (p *provider) Provision(status *cli.Status, cfg *config.Cluster, opts providers.ProvisionOptions) (err error) {
 	// TODO: validate cfg
 	// ensure node images are pulled before actually provisioning
-	if err := ensureNodeImages(p.logger, status, cfg); err != nil {
+	if opts.NodePlatform != "" {
+		warnIfEmulated(p.logger, opts.NodePlatform)
+	}
+	if err := ensureNodeImages(p.logger, status, cfg, opts.ImagePullTimeout, opts.NodePlatform); err != nil {
 		return err
 	}
 
-	// ensure the pre-requesite network exists
+	// ensure the pre-requesite network exists, unless attaching to a
+	// network managed by the user
 	networkName := fixedNetworkName
-	if n := os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK"); n != "" {
-		p.logger.Warn("WARNING: Overriding docker network due to KIND_EXPERIMENTAL_DOCKER_NETWORK")
-		p.logger.Warn("WARNING: Here be dragons! This is not supported currently.")
-		networkName = n
+	if opts.ExistingNetworkID != "" {
+		if os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK") != "" {
+			p.logger.Warn("WARNING: Ignoring KIND_EXPERIMENTAL_DOCKER_NETWORK, attaching to the existing network instead")
+		}
+		networkName = opts.ExistingNetworkID
+		if err := checkExistingNetwork(networkName, cfg, opts.NetworkMTU); err != nil {
+			return err
+		}
+	} else {
+		if n := os.Getenv("KIND_EXPERIMENTAL_DOCKER_NETWORK"); n != "" {
+			p.logger.Warn("WARNING: Overriding docker network due to KIND_EXPERIMENTAL_DOCKER_NETWORK")
+			p.logger.Warn("WARNING: Here be dragons! This is not supported currently.")
+			networkName = n
+		}
+		if err := ensureNetwork(networkName, opts.NetworkMTU); err != nil {
+			return errors.Wrap(err, "failed to ensure docker network")
+		}
 	}
-	if err := ensureNetwork(networkName); err != nil {
-		return errors.Wrap(err, "failed to ensure docker network")
+	if err := ensureSecondaryNetworks(networkName, opts.SecondaryNetworks, opts.NetworkMTU); err != nil {
+		return err
+	}
+
+	// ports published on the loopback address of a remote daemon are only
+	// reachable from the remote host itself
+	if remote := remoteDockerHost(); remote != "" {
+		if ip := net.ParseIP(cfg.Networking.APIServerAddress); ip != nil && ip.IsLoopback() {
+			p.logger.Warnf(
+				"WARNING: DOCKER_HOST is the remote host %s, but the API server is only published on its loopback address %s",
+				remote, cfg.Networking.APIServerAddress,
+			)
+			p.logger.Warn("WARNING: set networking.apiServerAddress to an address reachable from this host, E.G. 0.0.0.0")
+		}
 	}
 
 	// actually provision the cluster
@@ -83,13 +112,24 @@ func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	defer func() { status.End(err == nil) }()
 
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(cfg, networkName)
+	createContainerFuncs, err := planCreation(cfg, networkName, opts)
 	if err != nil {
 		return err
 	}
 
 	// actually create nodes
-	return errors.UntilErrorConcurrent(createContainerFuncs)
+	if err := errors.UntilErrorConcurrent(createContainerFuncs); err != nil {
+		return err
+	}
+
+	// only ensure the image cache once the nodes using it exist,
+	// DeleteUnusedImageCaches deletes caches without any nodes
+	if opts.SharedImageCache != "" {
+		if err := ensureRegistryCache(opts.SharedImageCache, networkName); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ListClusters is part of the providers.Provider interface
@@ -164,6 +204,22 @@ func (p *provider) GetAPIServerEndpoint(cluster string) (string, error) {
 		return "", errors.Wrap(err, "failed to get api server endpoint")
 	}
 
+	// if the address the published ports are reachable at was overridden
+	// when creating the cluster, use it as the host
+	cmd := exec.Command(
+		"docker", "inspect",
+		"--format", fmt.Sprintf("{{ index .Config.Labels %q }}", hostAddressLabelKey),
+		n.String(),
+	)
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get host address")
+	}
+	hostAddress := ""
+	if len(lines) == 1 {
+		hostAddress = lines[0]
+	}
+
 	// if the 'desktop.docker.io/ports/<PORT>/tcp' label is present,
 	// defer to its value for the api server endpoint
 	//
@@ -171,19 +227,26 @@ func (p *provider) GetAPIServerEndpoint(cluster string) (string, error) {
 	// "Labels": {
 	// 	"desktop.docker.io/ports/6443/tcp": "10.0.1.7:6443",
 	// }
-	cmd := exec.Command(
+	cmd = exec.Command(
 		"docker", "inspect",
 		"--format", fmt.Sprintf(
 			"{{ index .Config.Labels \"desktop.docker.io/ports/%d/tcp\" }}", common.APIServerInternalPort,
 		),
 		n.String(),
 	)
-	lines, err := exec.OutputLines(cmd)
+	lines, err = exec.OutputLines(cmd)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get api server port")
 	}
 	if len(lines) == 1 && lines[0] != "" {
-		return lines[0], nil
+		if hostAddress == "" {
+			return lines[0], nil
+		}
+		_, port, err := net.SplitHostPort(lines[0])
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get api server port")
+		}
+		return net.JoinHostPort(hostAddress, port), nil
 	}
 
 	// else, retrieve the specific port mapping via NetworkSettings.Ports
@@ -206,8 +269,9 @@ func (p *provider) GetAPIServerEndpoint(cluster string) (string, error) {
 		return "", errors.Errorf("network details should only be two parts, got %d", len(parts))
 	}
 
-	// join host and port
-	return net.JoinHostPort(parts[0], parts[1]), nil
+	// join host and port, using an address reachable from here if the
+	// daemon is remote
+	return net.JoinHostPort(reachableHost(parts[0], hostAddress), parts[1]), nil
 }
 
 // GetAPIServerInternalEndpoint is part of the providers.Provider interface
@@ -232,6 +296,20 @@ func (p *provider) node(name string) nodes.Node {
 	}
 }
 
+// DataRootFreeSpace is part of the providers.Provider interface
+func (p *provider) DataRootFreeSpace() (uint64, error) {
+	root, err := dataRoot()
+	if err != nil {
+		return 0, err
+	}
+	return common.FreeDiskSpace(root)
+}
+
+// Capacity is part of the providers.Provider interface
+func (p *provider) Capacity() (int, uint64, error) {
+	return capacity()
+}
+
 // CollectLogs will populate dir with cluster logs and other debug files
 func (p *provider) CollectLogs(dir string, nodes []nodes.Node) error {
 	execToPathFn := func(cmd exec.Cmd, path string) func() error {
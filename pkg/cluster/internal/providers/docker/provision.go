@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/errors"
@@ -28,35 +30,53 @@ import (
 	"sigs.k8s.io/kind/pkg/fs"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs []func() error, err error) {
+func planCreation(cfg *config.Cluster, networkName string, opts providers.ProvisionOptions) (createContainerFuncs []func() error, err error) {
 	// we need to know all the names for NO_PROXY
 	// compute the names first before any actual node details
-	nodeNamer := common.MakeNodeNamer(cfg.Name)
+	nodeNamer, err := common.MakeTemplateNodeNamer(cfg.Name, opts.NodeNameTemplate)
+	if err != nil {
+		return nil, err
+	}
 	names := make([]string, len(cfg.Nodes))
 	for i, node := range cfg.Nodes {
-		name := nodeNamer(string(node.Role)) // name the node
+		name, err := nodeNamer(string(node.Role)) // name the node
+		if err != nil {
+			return nil, err
+		}
 		names[i] = name
 	}
-	haveLoadbalancer := clusterHasImplicitLoadBalancer(cfg)
+	multipleControlPlanes := clusterHasImplicitLoadBalancer(cfg)
+	haveLoadbalancer := multipleControlPlanes && !opts.ExternalLoadBalancer
 	if haveLoadbalancer {
-		names = append(names, nodeNamer(constants.ExternalLoadBalancerNodeRoleValue))
+		name, err := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
 	}
 
 	// these apply to all container creation
-	genericArgs, err := commonArgs(cfg.Name, cfg, networkName, names)
+	genericArgs, err := commonArgs(cfg.Name, cfg, networkName, names, opts.RestartPolicy)
 	if err != nil {
 		return nil, err
 	}
+	if opts.HostAddress != "" {
+		genericArgs = append(genericArgs, "--label", fmt.Sprintf("%s=%s", hostAddressLabelKey, opts.HostAddress))
+	}
+	if opts.SharedImageCache != "" {
+		genericArgs = append(genericArgs, "--label", fmt.Sprintf("%s=%s", imageCacheLabelKey, common.RegistryCacheName(opts.SharedImageCache)))
+	}
 
 	// only the external LB should reflect the port if we have multiple control planes
 	apiServerPort := cfg.Networking.APIServerPort
 	apiServerAddress := cfg.Networking.APIServerAddress
-	if haveLoadbalancer {
+	if multipleControlPlanes {
 		// TODO: picking ports locally is less than ideal with remote docker
 		// but this is supposed to be an implementation detail and NOT picking
 		// them breaks host reboot ...
@@ -66,6 +86,8 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 		if clusterIsIPv6(cfg) {
 			apiServerAddress = "::1" // only the LB needs to be non-local
 		}
+	}
+	if haveLoadbalancer {
 		// plan loadbalancer node
 		name := names[len(names)-1]
 		createContainerFuncs = append(createContainerFuncs, func() error {
@@ -78,6 +100,13 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 	}
 
 	// plan normal nodes
+	nodeArgs := append(append([]string{}, genericArgs...), common.SecurityProfileArgs(opts.SecurityProfile)...)
+	nodeArgs = append(nodeArgs, common.KubeletRootDirArgs(opts.KubeletRootDir)...)
+	nodeArgs = append(nodeArgs, common.TimezoneArgs(opts.Timezone)...)
+	if opts.NodePlatform != "" {
+		nodeArgs = append(nodeArgs, "--platform="+opts.NodePlatform)
+	}
+	controlPlanes := 0
 	for i, node := range cfg.Nodes {
 		node := node.DeepCopy() // copy so we can modify
 		name := names[i]
@@ -97,7 +126,11 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 		// plan actual creation based on role
 		switch node.Role {
 		case config.ControlPlaneRole:
+			// space out control plane starts if requested
+			stagger := time.Duration(controlPlanes) * opts.ControlPlaneStagger
+			controlPlanes++
 			createContainerFuncs = append(createContainerFuncs, func() error {
+				time.Sleep(stagger)
 				node.ExtraPortMappings = append(node.ExtraPortMappings,
 					config.PortMapping{
 						ListenAddress: apiServerAddress,
@@ -105,19 +138,25 @@ func planCreation(cfg *config.Cluster, networkName string) (createContainerFuncs
 						ContainerPort: common.APIServerInternalPort,
 					},
 				)
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, nodeArgs)
 				if err != nil {
 					return err
 				}
-				return createContainer(args)
+				if err := createContainer(args); err != nil {
+					return err
+				}
+				return connectSecondaryNetworks(name, opts.SecondaryNetworks)
 			})
-		case config.WorkerRole:
+		case config.WorkerRole, config.KubeletOnlyRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, nodeArgs)
 				if err != nil {
 					return err
 				}
-				return createContainer(args)
+				if err := createContainer(args); err != nil {
+					return err
+				}
+				return connectSecondaryNetworks(name, opts.SecondaryNetworks)
 			})
 		default:
 			return nil, errors.Errorf("unknown node role: %q", node.Role)
@@ -133,6 +172,18 @@ func createContainer(args []string) error {
 	return nil
 }
 
+// connectSecondaryNetworks attaches the node container name to the networks
+// one at a time, so that they get the interfaces in the same order on every
+// node, see providers.SecondaryNetworkInterface
+func connectSecondaryNetworks(name string, networks []providers.NetworkSpec) error {
+	for _, n := range networks {
+		if err := exec.Command("docker", "network", "connect", n.Name, name).Run(); err != nil {
+			return errors.Wrapf(err, "failed to attach node %q to network %q", name, n.Name)
+		}
+	}
+	return nil
+}
+
 func clusterIsIPv6(cfg *config.Cluster) bool {
 	return cfg.Networking.IPFamily == "ipv6"
 }
@@ -148,8 +199,16 @@ func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
 	return controlPlanes > 1
 }
 
+// defaultRestartPolicy is the default node container restart policy,
+// see commonArgs
+const defaultRestartPolicy = "on-failure:1"
+
 // commonArgs computes static arguments that apply to all containers
-func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNames []string) ([]string, error) {
+// restartPolicy overrides the default restart policy if set
+func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNames []string, restartPolicy string) ([]string, error) {
+	if restartPolicy == "" {
+		restartPolicy = defaultRestartPolicy
+	}
 	// standard arguments all nodes containers need, computed once
 	args := []string{
 		"--detach", // run the container detached
@@ -158,6 +217,8 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cluster),
 		// user a user defined docker network so we get embedded DNS
 		"--net", networkName,
+		// record the network for anything else attaching to it later
+		"--label", fmt.Sprintf("%s=%s", networkLabelKey, networkName),
 		// Docker supports the following restart modes:
 		// - no
 		// - on-failure[:max-retries]
@@ -182,7 +243,10 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 		// retries is 0, so only restart on reboots.
 		// however this _actually_ means the same thing as always
 		// so the closest thing is on-failure:1, which will retry *once*
-		"--restart=on-failure:1",
+		//
+		// This is the default, users may choose another policy
+		// E.G. unless-stopped to better survive host reboots
+		"--restart=" + restartPolicy,
 	}
 
 	// enable IPv6 if necessary
@@ -214,9 +278,13 @@ func commonArgs(cluster string, cfg *config.Cluster, networkName string, nodeNam
 }
 
 func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, name string, args []string) ([]string, error) {
+	hostname := name
+	if node.Hostname != "" {
+		hostname = node.Hostname
+	}
 	args = append([]string{
 		"run",
-		"--hostname", name, // make hostname match container name
+		"--hostname", hostname, // make hostname match container name unless overridden
 		"--name", name, // ... and set the container name
 		// label the node with the role ID
 		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, node.Role),
@@ -243,16 +311,67 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 		args...,
 	)
 
-	// convert mounts and port mappings to container run args
+	// convert mounts, port mappings and env to container run args
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
 	mappingArgs, err := generatePortMappings(clusterIPFamily, node.ExtraPortMappings...)
 	if err != nil {
 		return nil, err
 	}
 	args = append(args, mappingArgs...)
+	args = append(args, generateEnvArgs(node.Env)...)
+	args = append(args, generateHostAliasArgs(node.HostAliases)...)
+	args = append(args, generateGPUArgs(node.GPUs)...)
+
+	// override the entrypoint if requested, docker only takes the executable
+	// so the remaining arguments are passed as the command
+	if len(node.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", node.Entrypoint[0])
+	}
 
 	// finally, specify the image to run
-	return append(args, node.Image), nil
+	args = append(args, node.Image)
+	if len(node.Entrypoint) > 1 {
+		args = append(args, node.Entrypoint[1:]...)
+	}
+	return args, nil
+}
+
+// generateEnvArgs converts the node env to container run args,
+// sorted by name for stable output
+func generateEnvArgs(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]string, 0, len(env)*2)
+	for _, name := range names {
+		args = append(args, "-e", name+"="+env[name])
+	}
+	return args
+}
+
+// generateHostAliasArgs converts the node host aliases to container run args
+func generateHostAliasArgs(aliases []config.HostAlias) []string {
+	args := []string{}
+	for _, alias := range aliases {
+		for _, hostname := range alias.Hostnames {
+			args = append(args, "--add-host", hostname+":"+alias.IP)
+		}
+	}
+	return args
+}
+
+// generateGPUArgs converts the node GPUs to container run args
+func generateGPUArgs(gpus string) []string {
+	if gpus == "" {
+		return nil
+	}
+	// docker parses --gpus as CSV, so a list of devices must be quoted
+	if strings.Contains(gpus, ",") {
+		gpus = `"` + gpus + `"`
+	}
+	return []string{"--gpus", gpus}
 }
 
 func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]string, error) {
@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/kind/pkg/errors"
+	"sigs.k8s.io/kind/pkg/exec"
+
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
+)
+
+// registryImage is the image used for local registries and image caches
+const registryImage = "registry:2.7.1"
+
+// ProvisionLocalRegistry is part of the providers.Provider interface
+func (p *provider) ProvisionLocalRegistry(cluster string, hostPort int32) error {
+	name := common.LocalRegistryName(cluster)
+	networks, err := exec.OutputLines(exec.Command("docker",
+		"ps",
+		"-a", // show stopped nodes
+		"--filter", fmt.Sprintf("label=%s=%s", clusterLabelKey, cluster),
+		"--format", fmt.Sprintf(`{{.Label "%s"}}`, networkLabelKey),
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to get the nodes' network")
+	}
+	if err := exec.Command("docker",
+		"run",
+		"--detach",
+		"--restart=always",
+		"--name", name,
+		"--hostname", name,
+		// label the registry so we know kind created it
+		"--label", fmt.Sprintf("%s=%s", registryLabelKey, cluster),
+		// attach to the same network as the nodes
+		"--net", nodesNetwork(networks),
+		"--publish", fmt.Sprintf("127.0.0.1:%d:%d", hostPort, common.LocalRegistryInternalPort),
+		registryImage,
+	).Run(); err != nil {
+		return errors.Wrap(err, "failed to create local registry")
+	}
+	return nil
+}
+
+// nodesNetwork returns the network the nodes were created on given the value
+// of networkLabelKey on each of them, nodes created before it was recorded
+// are on fixedNetworkName
+func nodesNetwork(networks []string) string {
+	for _, network := range networks {
+		if network != "" {
+			return network
+		}
+	}
+	return fixedNetworkName
+}
+
+// DeleteLocalRegistry is part of the providers.Provider interface
+func (p *provider) DeleteLocalRegistry(cluster string) error {
+	lines, err := exec.OutputLines(exec.Command("docker",
+		"ps",
+		"-a", // show stopped registries
+		// filter for registries created by kind for this cluster
+		"--filter", fmt.Sprintf("label=%s=%s", registryLabelKey, cluster),
+		"--format", `{{.Names}}`,
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list local registries")
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	args := append([]string{"rm", "-f", "-v"}, lines...)
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete local registry")
+	}
+	return nil
+}
+
+// ensureRegistryCache ensures the pull through registry cache storing its
+// content in the host directory cacheDir is running and attached to network,
+// creating it if needed, see common.RegistryCacheName
+//
+// NOTE: the cache is shared by every cluster using cacheDir, so it is only
+// deleted with the last of them by DeleteUnusedImageCaches. A single
+// registry serves all the nodes, so the content in cacheDir is only ever
+// written by that registry.
+func ensureRegistryCache(cacheDir, network string) error {
+	name := common.RegistryCacheName(cacheDir)
+	exists, err := containerExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := exec.Command("docker",
+			"run",
+			"--detach",
+			"--restart=always",
+			"--name", name,
+			"--hostname", name,
+			// label the cache so we know kind created it
+			"--label", fmt.Sprintf("%s=%s", imageCacheDirLabelKey, cacheDir),
+			"--net", network,
+			"--volume", fmt.Sprintf("%s:/var/lib/registry", cacheDir),
+			"--env", "REGISTRY_PROXY_REMOTEURL="+common.RegistryCacheUpstream,
+			registryImage,
+		).Run()
+		if err == nil {
+			return nil
+		}
+		// another cluster may have created it concurrently
+		if exists, _ := containerExists(name); !exists {
+			return errors.Wrap(err, "failed to create registry cache")
+		}
+	}
+	// the cache may have been created for clusters on another network
+	networks, err := exec.Output(exec.Command("docker",
+		"inspect", "--format", `{{range $name, $_ := .NetworkSettings.Networks}}{{$name}} {{end}}`, name,
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to inspect registry cache")
+	}
+	for _, n := range strings.Fields(string(networks)) {
+		if n == network {
+			return nil
+		}
+	}
+	if err := exec.Command("docker", "network", "connect", network, name).Run(); err != nil {
+		return errors.Wrapf(err, "failed to attach registry cache to network %q", network)
+	}
+	return nil
+}
+
+// DeleteUnusedImageCaches is part of the providers.Provider interface
+func (p *provider) DeleteUnusedImageCaches() error {
+	caches, err := exec.OutputLines(exec.Command("docker",
+		"ps",
+		"-a", // show stopped caches
+		// filter for image caches created by kind
+		"--filter", "label="+imageCacheDirLabelKey,
+		"--format", `{{.Names}}`,
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list image caches")
+	}
+	if len(caches) == 0 {
+		return nil
+	}
+	used, err := exec.OutputLines(exec.Command("docker",
+		"ps",
+		"-a", // stopped nodes still use their cache
+		"--filter", "label="+imageCacheLabelKey,
+		"--format", fmt.Sprintf(`{{.Label "%s"}}`, imageCacheLabelKey),
+	))
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes using image caches")
+	}
+	unused := unusedImageCaches(caches, used)
+	if len(unused) == 0 {
+		return nil
+	}
+	args := append([]string{"rm", "-f", "-v"}, unused...)
+	if err := exec.Command("docker", args...).Run(); err != nil {
+		return errors.Wrap(err, "failed to delete image caches")
+	}
+	return nil
+}
+
+// unusedImageCaches returns the caches not in used, the value of
+// imageCacheLabelKey on each node
+func unusedImageCaches(caches, used []string) []string {
+	inUse := sets.NewString(used...)
+	unused := []string{}
+	for _, cache := range caches {
+		if !inUse.Has(cache) {
+			unused = append(unused, cache)
+		}
+	}
+	return unused
+}
+
+// containerExists returns true if a container named name exists
+func containerExists(name string) (bool, error) {
+	lines, err := exec.OutputLines(exec.Command("docker",
+		"ps", "-a",
+		"--filter", "name=^/?"+name+"$",
+		"--format", "{{.Names}}",
+	))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list containers")
+	}
+	return len(lines) > 0, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func Test_nodesNetwork(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name     string
+		networks []string
+		expected string
+	}{
+		{
+			name:     "no nodes",
+			expected: fixedNetworkName,
+		},
+		{
+			name:     "nodes without the network label",
+			networks: []string{"", ""},
+			expected: fixedNetworkName,
+		},
+		{
+			name:     "default network",
+			networks: []string{fixedNetworkName, fixedNetworkName},
+			expected: fixedNetworkName,
+		},
+		{
+			name:     "network from KIND_EXPERIMENTAL_DOCKER_NETWORK",
+			networks: []string{"custom", "custom"},
+			expected: "custom",
+		},
+		{
+			name:     "existing network ID",
+			networks: []string{"", "0123456789ab"},
+			expected: "0123456789ab",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.StringEqual(t, tc.expected, nodesNetwork(tc.networks))
+		})
+	}
+}
+
+func Test_unusedImageCaches(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name     string
+		caches   []string
+		used     []string
+		expected []string
+	}{
+		{
+			name:     "no nodes",
+			caches:   []string{"kind-registry-cache-a"},
+			expected: []string{"kind-registry-cache-a"},
+		},
+		{
+			name:     "all used",
+			caches:   []string{"kind-registry-cache-a", "kind-registry-cache-b"},
+			used:     []string{"kind-registry-cache-b", "kind-registry-cache-a", "kind-registry-cache-a"},
+			expected: []string{},
+		},
+		{
+			name:     "one unused",
+			caches:   []string{"kind-registry-cache-a", "kind-registry-cache-b"},
+			used:     []string{"kind-registry-cache-b"},
+			expected: []string{"kind-registry-cache-a"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.DeepEqual(t, tc.expected, unusedImageCaches(tc.caches, tc.used))
+		})
+	}
+}
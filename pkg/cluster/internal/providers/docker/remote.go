@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"net"
+	"net/url"
+	"os"
+)
+
+// remoteDockerHost returns the host of $DOCKER_HOST if it is a remote
+// daemon reached over TCP or SSH, and otherwise the empty string
+func remoteDockerHost() string {
+	return remoteHostFor(os.Getenv("DOCKER_HOST"))
+}
+
+// remoteHostFor returns the host of the DOCKER_HOST value dockerHost,
+// if it is remote
+func remoteHostFor(dockerHost string) string {
+	u, err := url.Parse(dockerHost)
+	if err != nil {
+		return ""
+	}
+	switch u.Scheme {
+	case "tcp", "ssh", "http", "https":
+	default:
+		return ""
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && ip.IsLoopback()) || host == "localhost" {
+		return ""
+	}
+	return host
+}
+
+// reachableHost returns the host to reach a port published by the docker
+// daemon on hostIP at, given the overridden host address if any
+func reachableHost(hostIP, override string) string {
+	if override != "" {
+		return override
+	}
+	// ports published on the loopback or all addresses of a remote daemon
+	// are not reachable at those addresses from here
+	if remote := remoteDockerHost(); remote != "" {
+		if ip := net.ParseIP(hostIP); ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+			return remote
+		}
+	}
+	return hostIP
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"testing"
+)
+
+func Test_remoteHostFor(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		dockerHost string
+		host       string
+	}{
+		{dockerHost: "", host: ""},
+		{dockerHost: "unix:///var/run/docker.sock", host: ""},
+		{dockerHost: "npipe:////./pipe/docker_engine", host: ""},
+		{dockerHost: "tcp://127.0.0.1:2375", host: ""},
+		{dockerHost: "tcp://localhost:2375", host: ""},
+		{dockerHost: "tcp://192.168.1.10:2376", host: "192.168.1.10"},
+		{dockerHost: "tcp://[fd00::10]:2376", host: "fd00::10"},
+		{dockerHost: "ssh://user@docker.example.com", host: "docker.example.com"},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.dockerHost, func(t *testing.T) {
+			t.Parallel()
+			if host := remoteHostFor(tc.dockerHost); host != tc.host {
+				t.Errorf("expected %q but got %q", tc.host, host)
+			}
+		})
+	}
+}
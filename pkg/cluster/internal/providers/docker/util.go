@@ -17,8 +17,10 @@ limitations under the License.
 package docker
 
 import (
+	"fmt"
 	"strings"
 
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -60,3 +62,34 @@ func mountDevMapper() bool {
 	}
 	return storage == "btrfs" || storage == "zfs"
 }
+
+// dataRoot returns the docker data root directory, E.G. /var/lib/docker
+func dataRoot() (string, error) {
+	cmd := exec.Command("docker", "info", "-f", "{{.DockerRootDir}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get docker data root")
+	}
+	if len(lines) != 1 || strings.TrimSpace(lines[0]) == "" {
+		return "", errors.Errorf("unexpected docker data root output: %v", lines)
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// capacity returns the CPUs and memory in bytes of the docker host
+func capacity() (int, uint64, error) {
+	cmd := exec.Command("docker", "info", "-f", "{{.NCPU}} {{.MemTotal}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get docker host capacity")
+	}
+	var cpus int
+	var memory uint64
+	if len(lines) != 1 {
+		return 0, 0, errors.Errorf("unexpected docker host capacity output: %v", lines)
+	}
+	if _, err := fmt.Sscanf(lines[0], "%d %d", &cpus, &memory); err != nil {
+		return 0, 0, errors.Errorf("unexpected docker host capacity output: %v", lines)
+	}
+	return cpus, memory, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package podman
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -32,13 +33,17 @@ import (
 
 // ensureNodeImages ensures that the node images used by the create
 // configuration are present
-func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster) error {
+// each image is pulled for at most timeout, see common.DefaultImagePullTimeout
+func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = common.DefaultImagePullTimeout
+	}
 	// pull each required image
 	for _, image := range common.RequiredNodeImages(cfg).List() {
 		// prints user friendly message
 		friendlyImageName, image := sanitizeImage(image)
 		status.Start(fmt.Sprintf("Ensuring node image (%s) 🖼", friendlyImageName))
-		if _, err := pullIfNotPresent(logger, image, 4); err != nil {
+		if _, err := pullIfNotPresent(logger, image, 4, timeout); err != nil {
 			status.End(false)
 			return err
 		}
@@ -47,9 +52,9 @@ func ensureNodeImages(logger log.Logger, status *cli.Status, cfg *config.Cluster
 }
 
 // pullIfNotPresent will pull an image if it is not present locally
-// retrying up to retries times
+// retrying up to retries times for at most timeout
 // it returns true if it attempted to pull, and any errors from pulling
-func pullIfNotPresent(logger log.Logger, image string, retries int) (pulled bool, err error) {
+func pullIfNotPresent(logger log.Logger, image string, retries int, timeout time.Duration) (pulled bool, err error) {
 	// TODO(bentheelder): switch most (all) of the logging here to debug level
 	// once we have configurable log levels
 	// if this did not return an error, then the image exists locally
@@ -59,20 +64,30 @@ func pullIfNotPresent(logger log.Logger, image string, retries int) (pulled bool
 		return false, nil
 	}
 	// otherwise try to pull it
-	return true, pull(logger, image, retries)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = pull(ctx, logger, image, retries)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return true, errors.Errorf("timed out pulling image %q after %s, the image pull timeout may need to be increased for slow networks", image, timeout)
+	}
+	return true, err
 }
 
-// pull pulls an image, retrying up to retries times
-func pull(logger log.Logger, image string, retries int) error {
+// pull pulls an image, retrying up to retries times until ctx is done
+func pull(ctx context.Context, logger log.Logger, image string, retries int) error {
 	logger.V(1).Infof("Pulling image: %s ...", image)
-	err := exec.Command("podman", "pull", image).Run()
+	err := exec.CommandContext(ctx, "podman", "pull", image).Run()
 	// retry pulling up to retries times if necessary
 	if err != nil {
 		for i := 0; i < retries; i++ {
-			time.Sleep(time.Second * time.Duration(i+1))
+			select {
+			case <-ctx.Done():
+				return errors.Wrapf(err, "failed to pull image %q", image)
+			case <-time.After(time.Second * time.Duration(i+1)):
+			}
 			logger.V(1).Infof("Trying again to pull image: %q ... %v", image, err)
 			// TODO(bentheelder): add some backoff / sleep?
-			err = exec.Command("podman", "pull", image).Run()
+			err = exec.CommandContext(ctx, "podman", "pull", image).Run()
 			if err == nil {
 				break
 			}
@@ -62,10 +62,36 @@ func (p *provider) String() string {
 }
 
 // Provision is part of the providers.Provider interface
-func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error) {
+func (p *provider) Provision(status *cli.Status, cfg *config.Cluster, opts providers.ProvisionOptions) (err error) {
 	if err := ensureMinVersion(); err != nil {
 		return err
 	}
+	if opts.HostAddress != "" {
+		return errors.New("overriding the host address is not supported by the podman provider")
+	}
+	if opts.NetworkMTU != 0 {
+		return errors.New("setting the network MTU is not supported by the podman provider")
+	}
+	if opts.NodePlatform != "" {
+		return errors.New("setting the node platform is not supported by the podman provider")
+	}
+	if opts.ExistingNetworkID != "" {
+		return errors.New("attaching to an existing network is not supported by the podman provider")
+	}
+	if opts.SharedImageCache != "" {
+		return errors.New("a shared image cache is not supported by the podman provider")
+	}
+	if opts.LocalRegistry {
+		return errors.New("local registries are not supported by the podman provider")
+	}
+	if len(opts.SecondaryNetworks) > 0 {
+		return errors.New("secondary networks are not supported by the podman provider")
+	}
+	for _, node := range cfg.Nodes {
+		if node.GPUs != "" {
+			return errors.New("GPU passthrough is not supported by the podman provider")
+		}
+	}
 
 	// kind doesn't work with podman rootless, surface an error
 	if os.Geteuid() != 0 {
@@ -75,7 +101,7 @@ func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 
 	// TODO: validate cfg
 	// ensure node images are pulled before actually provisioning
-	if err := ensureNodeImages(p.logger, status, cfg); err != nil {
+	if err := ensureNodeImages(p.logger, status, cfg, opts.ImagePullTimeout); err != nil {
 		return err
 	}
 
@@ -85,7 +111,7 @@ func (p *provider) Provision(status *cli.Status, cfg *config.Cluster) (err error
 	defer func() { status.End(err == nil) }()
 
 	// plan creating the containers
-	createContainerFuncs, err := planCreation(cfg)
+	createContainerFuncs, err := planCreation(cfg, opts)
 	if err != nil {
 		return err
 	}
@@ -313,3 +339,35 @@ func (p *provider) CollectLogs(dir string, nodes []nodes.Node) error {
 	errs = append(errs, errors.AggregateConcurrent(fns))
 	return errors.NewAggregate(errs)
 }
+
+// ProvisionLocalRegistry is part of the providers.Provider interface
+func (p *provider) ProvisionLocalRegistry(cluster string, hostPort int32) error {
+	// TODO: podman nodes are not on a network resolving container names
+	return errors.New("local registries are not supported by the podman provider")
+}
+
+// DeleteLocalRegistry is part of the providers.Provider interface
+func (p *provider) DeleteLocalRegistry(cluster string) error {
+	// nothing to do, podman never creates local registries
+	return nil
+}
+
+// DeleteUnusedImageCaches is part of the providers.Provider interface
+func (p *provider) DeleteUnusedImageCaches() error {
+	// nothing to do, podman never creates image caches
+	return nil
+}
+
+// DataRootFreeSpace is part of the providers.Provider interface
+func (p *provider) DataRootFreeSpace() (uint64, error) {
+	root, err := graphRoot()
+	if err != nil {
+		return 0, err
+	}
+	return common.FreeDiskSpace(root)
+}
+
+// Capacity is part of the providers.Provider interface
+func (p *provider) Capacity() (int, uint64, error) {
+	return capacity()
+}
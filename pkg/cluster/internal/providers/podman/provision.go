@@ -20,22 +20,28 @@ import (
 	"fmt"
 	"net"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/exec"
 
 	"sigs.k8s.io/kind/pkg/cluster/internal/loadbalancer"
+	"sigs.k8s.io/kind/pkg/cluster/internal/providers"
 	"sigs.k8s.io/kind/pkg/cluster/internal/providers/common"
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 )
 
 // planCreation creates a slice of funcs that will create the containers
-func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err error) {
+func planCreation(cfg *config.Cluster, opts providers.ProvisionOptions) (createContainerFuncs []func() error, err error) {
 	// these apply to all container creation
-	nodeNamer := common.MakeNodeNamer(cfg.Name)
-	genericArgs, err := commonArgs(cfg)
+	nodeNamer, err := common.MakeTemplateNodeNamer(cfg.Name, opts.NodeNameTemplate)
+	if err != nil {
+		return nil, err
+	}
+	genericArgs, err := commonArgs(cfg, opts.RestartPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +60,13 @@ func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err
 		if clusterIsIPv6(cfg) {
 			apiServerAddress = "::1" // only the LB needs to be non-local
 		}
+	}
+	if clusterHasImplicitLoadBalancer(cfg) && !opts.ExternalLoadBalancer {
 		// plan loadbalancer node
-		name := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		name, err := nodeNamer(constants.ExternalLoadBalancerNodeRoleValue)
+		if err != nil {
+			return nil, err
+		}
 		createContainerFuncs = append(createContainerFuncs, func() error {
 			args, err := runArgsForLoadBalancer(cfg, name, genericArgs)
 			if err != nil {
@@ -66,9 +77,16 @@ func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err
 	}
 
 	// plan normal nodes
+	nodeArgs := append(append([]string{}, genericArgs...), common.SecurityProfileArgs(opts.SecurityProfile)...)
+	nodeArgs = append(nodeArgs, common.KubeletRootDirArgs(opts.KubeletRootDir)...)
+	nodeArgs = append(nodeArgs, common.TimezoneArgs(opts.Timezone)...)
+	controlPlanes := 0
 	for _, node := range cfg.Nodes {
-		node := node.DeepCopy()              // copy so we can modify
-		name := nodeNamer(string(node.Role)) // name the node
+		node := node.DeepCopy()                   // copy so we can modify
+		name, err := nodeNamer(string(node.Role)) // name the node
+		if err != nil {
+			return nil, err
+		}
 
 		// fixup relative paths, podman can only handle absolute paths
 		for i := range node.ExtraMounts {
@@ -83,7 +101,11 @@ func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err
 		// plan actual creation based on role
 		switch node.Role {
 		case config.ControlPlaneRole:
+			// space out control plane starts if requested
+			stagger := time.Duration(controlPlanes) * opts.ControlPlaneStagger
+			controlPlanes++
 			createContainerFuncs = append(createContainerFuncs, func() error {
+				time.Sleep(stagger)
 				node.ExtraPortMappings = append(node.ExtraPortMappings,
 					config.PortMapping{
 						ListenAddress: apiServerAddress,
@@ -91,15 +113,15 @@ func planCreation(cfg *config.Cluster) (createContainerFuncs []func() error, err
 						ContainerPort: common.APIServerInternalPort,
 					},
 				)
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, nodeArgs)
 				if err != nil {
 					return err
 				}
 				return createContainer(args)
 			})
-		case config.WorkerRole:
+		case config.WorkerRole, config.KubeletOnlyRole:
 			createContainerFuncs = append(createContainerFuncs, func() error {
-				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, genericArgs)
+				args, err := runArgsForNode(node, cfg.Networking.IPFamily, name, nodeArgs)
 				if err != nil {
 					return err
 				}
@@ -135,7 +157,8 @@ func clusterHasImplicitLoadBalancer(cfg *config.Cluster) bool {
 }
 
 // commonArgs computes static arguments that apply to all containers
-func commonArgs(cfg *config.Cluster) ([]string, error) {
+// restartPolicy sets the node container restart policy if set
+func commonArgs(cfg *config.Cluster, restartPolicy string) ([]string, error) {
 	// standard arguments all nodes containers need, computed once
 	args := []string{
 		"--detach", // run the container detached
@@ -144,6 +167,10 @@ func commonArgs(cfg *config.Cluster) ([]string, error) {
 		"--label", fmt.Sprintf("%s=%s", clusterLabelKey, cfg.Name),
 	}
 
+	if restartPolicy != "" {
+		args = append(args, "--restart="+restartPolicy)
+	}
+
 	// enable IPv6 if necessary
 	if clusterIsIPv6(cfg) {
 		args = append(args, "--sysctl=net.ipv6.conf.all.disable_ipv6=0", "--sysctl=net.ipv6.conf.all.forwarding=1")
@@ -162,6 +189,10 @@ func commonArgs(cfg *config.Cluster) ([]string, error) {
 }
 
 func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, name string, args []string) ([]string, error) {
+	hostname := name
+	if node.Hostname != "" {
+		hostname = node.Hostname
+	}
 	// Pre-create anonymous volumes to enable specifying mount options
 	// during container run time
 	varVolume, err := createAnonymousVolume(name)
@@ -171,7 +202,7 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 
 	args = append([]string{
 		"run",
-		"--hostname", name, // make hostname match container name
+		"--hostname", hostname, // make hostname match container name unless overridden
 		"--name", name, // ... and set the container name
 		// label the node with the role ID
 		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, node.Role),
@@ -200,17 +231,55 @@ func runArgsForNode(node *config.Node, clusterIPFamily config.ClusterIPFamily, n
 		args...,
 	)
 
-	// convert mounts and port mappings to container run args
+	// convert mounts, port mappings and env to container run args
 	args = append(args, generateMountBindings(node.ExtraMounts...)...)
 	mappingArgs, err := generatePortMappings(clusterIPFamily, node.ExtraPortMappings...)
 	if err != nil {
 		return nil, err
 	}
 	args = append(args, mappingArgs...)
+	args = append(args, generateEnvArgs(node.Env)...)
+	args = append(args, generateHostAliasArgs(node.HostAliases)...)
+
+	// override the entrypoint if requested, podman only takes the executable
+	// so the remaining arguments are passed as the command
+	if len(node.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", node.Entrypoint[0])
+	}
 
 	// finally, specify the image to run
 	_, image := sanitizeImage(node.Image)
-	return append(args, image), nil
+	args = append(args, image)
+	if len(node.Entrypoint) > 1 {
+		args = append(args, node.Entrypoint[1:]...)
+	}
+	return args, nil
+}
+
+// generateEnvArgs converts the node env to container run args,
+// sorted by name for stable output
+func generateEnvArgs(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]string, 0, len(env)*2)
+	for _, name := range names {
+		args = append(args, "-e", name+"="+env[name])
+	}
+	return args
+}
+
+// generateHostAliasArgs converts the node host aliases to container run args
+func generateHostAliasArgs(aliases []config.HostAlias) []string {
+	args := []string{}
+	for _, alias := range aliases {
+		for _, hostname := range alias.Hostnames {
+			args = append(args, "--add-host", hostname+":"+alias.IP)
+		}
+	}
+	return args
 }
 
 func runArgsForLoadBalancer(cfg *config.Cluster, name string, args []string) ([]string, error) {
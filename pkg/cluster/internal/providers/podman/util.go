@@ -115,3 +115,34 @@ func deleteVolumes(names []string) error {
 	cmd := exec.Command("podman", args...)
 	return cmd.Run()
 }
+
+// graphRoot returns the podman storage graph root, E.G. /var/lib/containers/storage
+func graphRoot() (string, error) {
+	cmd := exec.Command("podman", "info", "--format", "{{.Store.GraphRoot}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get podman graph root")
+	}
+	if len(lines) != 1 || strings.TrimSpace(lines[0]) == "" {
+		return "", errors.Errorf("unexpected podman graph root output: %v", lines)
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+// capacity returns the CPUs and memory in bytes of the podman host
+func capacity() (int, uint64, error) {
+	cmd := exec.Command("podman", "info", "--format", "{{.Host.CPUs}} {{.Host.MemTotal}}")
+	lines, err := exec.OutputLines(cmd)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get podman host capacity")
+	}
+	var cpus int
+	var memory uint64
+	if len(lines) != 1 {
+		return 0, 0, errors.Errorf("unexpected podman host capacity output: %v", lines)
+	}
+	if _, err := fmt.Sscanf(lines[0], "%d %d", &cpus, &memory); err != nil {
+		return 0, 0, errors.Errorf("unexpected podman host capacity output: %v", lines)
+	}
+	return cpus, memory, nil
+}
@@ -17,18 +17,88 @@ limitations under the License.
 package providers
 
 import (
+	"fmt"
+	"time"
+
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 
 	"sigs.k8s.io/kind/pkg/internal/apis/config"
 	"sigs.k8s.io/kind/pkg/internal/cli"
 )
 
+// ProvisionOptions are options for Provision other than the cluster config
+type ProvisionOptions struct {
+	// RestartPolicy is the node container restart policy,
+	// if unset the provider's default is used
+	RestartPolicy string
+	// ExternalLoadBalancer is set if the control plane is behind a load
+	// balancer managed outside of kind, in which case none is created
+	ExternalLoadBalancer bool
+	// ControlPlaneStagger is the delay between starting each control plane
+	// node container, if zero they are all started at once
+	ControlPlaneStagger time.Duration
+	// SecurityProfile is the node container security profile, if unset the
+	// nodes are only privileged, see common.SecurityProfileHardened
+	SecurityProfile string
+	// HostAddress is the address the ports published on the host are
+	// reachable at if set, E.G. for a remote container runtime
+	HostAddress string
+	// NodeNameTemplate is the template the node container names are
+	// rendered from if set, see common.MakeTemplateNodeNamer
+	NodeNameTemplate string
+	// ImagePullTimeout bounds pulling each node image, including retries,
+	// if zero common.DefaultImagePullTimeout is used
+	ImagePullTimeout time.Duration
+	// KubeletRootDir is the kubelet's root directory in the nodes if set,
+	// see common.KubeletRootDirArgs
+	KubeletRootDir string
+	// ExistingNetworkID is the ID of a network managed outside of kind to
+	// attach the nodes to instead of the provider's own network, if set
+	ExistingNetworkID string
+	// SecondaryNetworks are attached to the nodes in order after the primary
+	// node network, see SecondaryNetworkInterface
+	SecondaryNetworks []NetworkSpec
+	// LocalRegistry is set if ProvisionLocalRegistry will be called for the
+	// cluster, so providers not supporting it can fail before creating nodes
+	LocalRegistry bool
+	// SharedImageCache is the absolute host directory of a pull through
+	// registry cache shared by the nodes if set, see common.RegistryCacheName
+	SharedImageCache string
+	// Timezone is the TZ of the node containers if set, see
+	// common.TimezoneArgs
+	Timezone string
+	// NetworkMTU is the MTU of the node network when creating it if set,
+	// otherwise the provider's default is used
+	NetworkMTU int
+	// NodePlatform is the os/arch[/variant] platform the node images are
+	// pulled and run for if set, otherwise the runtime's own platform is used
+	NodePlatform string
+}
+
+// NetworkSpec is a network attached to the nodes in addition to the primary
+// node network, see ProvisionOptions.SecondaryNetworks
+type NetworkSpec struct {
+	// Name is the name of the network, it is created if it does not exist
+	// and is not deleted with the cluster
+	Name string
+	// Subnet is the CIDR of the network when creating it if set, otherwise
+	// the runtime picks one
+	Subnet string
+}
+
+// SecondaryNetworkInterface returns the name of the network interface in the
+// nodes of the i-th of ProvisionOptions.SecondaryNetworks, the primary node
+// network is always eth0
+func SecondaryNetworkInterface(i int) string {
+	return fmt.Sprintf("eth%d", i+1)
+}
+
 // Provider represents a provider of cluster / node infrastructure
 // This is an alpha-grade internal API
 type Provider interface {
 	// Provision should create and start the nodes, just short of
 	// actually starting up Kubernetes, based on the given cluster config
-	Provision(status *cli.Status, cfg *config.Cluster) error
+	Provision(status *cli.Status, cfg *config.Cluster, opts ProvisionOptions) error
 	// ListClusters discovers the clusters that currently have resources
 	// under this providers
 	ListClusters() ([]string, error)
@@ -45,4 +115,20 @@ type Provider interface {
 	GetAPIServerInternalEndpoint(cluster string) (string, error)
 	// CollectLogs will populate dir with cluster logs and other debug files
 	CollectLogs(dir string, nodes []nodes.Node) error
+	// ProvisionLocalRegistry creates and starts a local image registry for
+	// the cluster, reachable from the nodes at common.LocalRegistryName on
+	// common.LocalRegistryInternalPort and from the host on localhost:hostPort
+	ProvisionLocalRegistry(cluster string, hostPort int32) error
+	// DataRootFreeSpace returns the free disk space in bytes where the
+	// provider stores node containers and images
+	DataRootFreeSpace() (uint64, error)
+	// Capacity returns the number of CPUs and the memory in bytes of the
+	// host the provider runs node containers on
+	Capacity() (cpus int, memory uint64, err error)
+	// DeleteLocalRegistry deletes the cluster's local image registry if it
+	// was created by ProvisionLocalRegistry, it is a no-op otherwise
+	DeleteLocalRegistry(cluster string) error
+	// DeleteUnusedImageCaches deletes the registry caches created for
+	// ProvisionOptions.SharedImageCache that no node uses anymore
+	DeleteUnusedImageCaches() error
 }
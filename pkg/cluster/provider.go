@@ -17,11 +17,13 @@ limitations under the License.
 package cluster
 
 import (
+	"io/ioutil"
 	"sort"
 
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 	"sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
+	"sigs.k8s.io/kind/pkg/errors"
 	"sigs.k8s.io/kind/pkg/log"
 
 	internalcreate "sigs.k8s.io/kind/pkg/cluster/internal/create"
@@ -144,8 +146,15 @@ func (p *Provider) Create(name string, options ...CreateOption) error {
 }
 
 // Delete tears down a kubernetes-in-docker cluster
-func (p *Provider) Delete(name, explicitKubeconfigPath string) error {
-	return internaldelete.Cluster(p.logger, p.provider, defaultName(name), explicitKubeconfigPath)
+func (p *Provider) Delete(name, explicitKubeconfigPath string, options ...DeleteOption) error {
+	// apply options
+	opts := internaldelete.ClusterOptions{}
+	for _, o := range options {
+		if err := o.apply(&opts); err != nil {
+			return err
+		}
+	}
+	return internaldelete.ClusterWithOptions(p.logger, p.provider, defaultName(name), explicitKubeconfigPath, opts)
 }
 
 // List returns a list of clusters for which nodes exist
@@ -164,8 +173,27 @@ func (p *Provider) KubeConfig(name string, internal bool) (string, error) {
 // it into the selected file, following the rules from
 // https://kubernetes.io/docs/reference/generated/kubectl/kubectl-commands#config
 // where explicitPath is the --kubeconfig value.
+// This warns if it replaces an existing context pointing at another cluster.
 func (p *Provider) ExportKubeConfig(name string, explicitPath string) error {
-	return kubeconfig.Export(p.provider, defaultName(name), explicitPath)
+	return kubeconfig.ExportWithOptions(p.provider, defaultName(name), explicitPath, kubeconfig.ExportOptions{
+		OnContextClash: func(context, reason string) {
+			p.logger.Warnf("WARNING: Replacing the existing kubeconfig context %q, which points at %s", context, reason)
+		},
+	})
+}
+
+// DecryptKubeconfig returns the KUBECONFIG at path that was encrypted with
+// key, see CreateWithKubeconfigEncryptionKey
+func DecryptKubeconfig(path, key string) (string, error) {
+	encrypted, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read encrypted kubeconfig")
+	}
+	b, err := kubeconfig.Decrypt(encrypted, key)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
 }
 
 // ListNodes returns the list of container IDs for the "nodes" in the cluster
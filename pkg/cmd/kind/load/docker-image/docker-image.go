@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	goruntime "runtime"
 
 	"github.com/spf13/cobra"
 
@@ -38,8 +39,9 @@ import (
 )
 
 type flagpole struct {
-	Name  string
-	Nodes []string
+	Name        string
+	Nodes       []string
+	Concurrency int
 }
 
 // NewCommand returns a new cobra.Command for loading an image into a cluster
@@ -72,10 +74,20 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		nil,
 		"comma separated list of nodes to load images into",
 	)
+	cmd.Flags().IntVar(
+		&flags.Concurrency,
+		"concurrency",
+		goruntime.NumCPU(),
+		"maximum number of nodes to load images into at the same time, defaults to the number of CPUs",
+	)
 	return cmd
 }
 
 func runE(logger log.Logger, flags *flagpole, args []string) error {
+	if flags.Concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency %d: must be at least 1", flags.Concurrency)
+	}
+
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
@@ -154,7 +166,7 @@ func runE(logger log.Logger, flags *flagpole, args []string) error {
 			return loadImage(imageTarPath, selectedNode)
 		})
 	}
-	return errors.UntilErrorConcurrent(fns)
+	return errors.UntilErrorConcurrentLimit(fns, flags.Concurrency)
 }
 
 // TODO: we should consider having a cluster method to load images
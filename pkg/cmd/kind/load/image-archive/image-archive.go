@@ -20,6 +20,7 @@ package load
 import (
 	"fmt"
 	"os"
+	goruntime "runtime"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kind/pkg/errors"
@@ -35,8 +36,9 @@ import (
 )
 
 type flagpole struct {
-	Name  string
-	Nodes []string
+	Name        string
+	Nodes       []string
+	Concurrency int
 }
 
 // NewCommand returns a new cobra.Command for loading an image into a cluster
@@ -69,10 +71,20 @@ func NewCommand(logger log.Logger, streams cmd.IOStreams) *cobra.Command {
 		nil,
 		"comma separated list of nodes to load images into",
 	)
+	cmd.Flags().IntVar(
+		&flags.Concurrency,
+		"concurrency",
+		goruntime.NumCPU(),
+		"maximum number of nodes to load images into at the same time, defaults to the number of CPUs",
+	)
 	return cmd
 }
 
 func runE(logger log.Logger, flags *flagpole, args []string) error {
+	if flags.Concurrency < 1 {
+		return fmt.Errorf("invalid --concurrency %d: must be at least 1", flags.Concurrency)
+	}
+
 	provider := cluster.NewProvider(
 		cluster.ProviderWithLogger(logger),
 		runtime.GetDefault(logger),
@@ -123,7 +135,7 @@ func runE(logger log.Logger, flags *flagpole, args []string) error {
 			return loadImage(imageTarPath, selectedNode)
 		})
 	}
-	return errors.UntilErrorConcurrent(fns)
+	return errors.UntilErrorConcurrentLimit(fns, flags.Concurrency)
 }
 
 // loads an image tarball onto a node
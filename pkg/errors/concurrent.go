@@ -16,10 +16,6 @@ limitations under the License.
 
 package errors
 
-import (
-	"sync"
-)
-
 // UntilErrorConcurrent runs all funcs in separate goroutines, returning the
 // first non-nil error returned from funcs, or nil if all funcs return nil
 func UntilErrorConcurrent(funcs []func() error) error {
@@ -38,25 +34,31 @@ func UntilErrorConcurrent(funcs []func() error) error {
 	return nil
 }
 
+// UntilErrorConcurrentLimit is like UntilErrorConcurrent, but runs at most
+// limit funcs at the same time, limit must be at least 1
+func UntilErrorConcurrentLimit(funcs []func() error, limit int) error {
+	errCh := runConcurrentLimit(funcs, limit)
+	for i := 0; i < len(funcs); i++ {
+		if err := <-errCh; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AggregateConcurrent runs fns concurrently, returning a NewAggregate if there are > 1 errors
 func AggregateConcurrent(funcs []func() error) error {
-	// run all fns concurrently
-	ch := make(chan error, len(funcs))
-	var wg sync.WaitGroup
-	for _, f := range funcs {
-		f := f // capture f
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			ch <- f()
-		}()
-	}
-	wg.Wait()
-	close(ch)
+	return AggregateConcurrentLimit(funcs, len(funcs))
+}
+
+// AggregateConcurrentLimit is like AggregateConcurrent, but runs at most
+// limit funcs at the same time, limit must be at least 1 if there are funcs
+func AggregateConcurrentLimit(funcs []func() error, limit int) error {
 	// collect up and return errors
+	errCh := runConcurrentLimit(funcs, limit)
 	errs := []error{}
-	for err := range ch {
-		if err != nil {
+	for i := 0; i < len(funcs); i++ {
+		if err := <-errCh; err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -67,3 +69,21 @@ func AggregateConcurrent(funcs []func() error) error {
 	}
 	return nil
 }
+
+// runConcurrentLimit runs all funcs in separate goroutines, at most limit at
+// the same time, returning a channel receiving each of their results
+// The channel is buffered for every result, so callers may stop receiving
+// early without leaking the goroutines
+func runConcurrentLimit(funcs []func() error, limit int) <-chan error {
+	errCh := make(chan error, len(funcs))
+	sem := make(chan struct{}, limit)
+	for _, f := range funcs {
+		f := f // capture f
+		go func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			errCh <- f()
+		}()
+	}
+	return errCh
+}
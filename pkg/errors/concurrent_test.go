@@ -18,7 +18,9 @@ package errors
 
 import (
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/kind/pkg/internal/assert"
 )
@@ -54,6 +56,50 @@ func TestUntilErrorConcurrent(t *testing.T) {
 	})
 }
 
+func TestUntilErrorConcurrentLimit(t *testing.T) {
+	t.Parallel()
+	t.Run("at most limit running", func(t *testing.T) {
+		t.Parallel()
+		const limit = 2
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		funcs := []func() error{}
+		for i := 0; i < 10; i++ {
+			funcs = append(funcs, func() error {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+		}
+		var expected error
+		assert.DeepEqual(t, expected, UntilErrorConcurrentLimit(funcs, limit))
+		if maxRunning > limit {
+			t.Errorf("expected at most %d funcs running at once but got %d", limit, maxRunning)
+		}
+	})
+	t.Run("error returned", func(t *testing.T) {
+		t.Parallel()
+		expected := New("error")
+		result := UntilErrorConcurrentLimit([]func() error{
+			func() error {
+				return nil
+			},
+			func() error {
+				return expected
+			},
+		}, 1)
+		assert.DeepEqual(t, expected, result)
+	})
+}
+
 func TestAggregateConcurrent(t *testing.T) {
 	t.Parallel()
 	t.Run("all errors returned", func(t *testing.T) {
@@ -98,3 +144,56 @@ func TestAggregateConcurrent(t *testing.T) {
 		assert.DeepEqual(t, expected, result)
 	})
 }
+
+func TestAggregateConcurrentLimit(t *testing.T) {
+	t.Parallel()
+	t.Run("at most limit running", func(t *testing.T) {
+		t.Parallel()
+		const limit = 2
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		funcs := []func() error{}
+		for i := 0; i < 10; i++ {
+			funcs = append(funcs, func() error {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			})
+		}
+		var expected error
+		assert.DeepEqual(t, expected, AggregateConcurrentLimit(funcs, limit))
+		if maxRunning > limit {
+			t.Errorf("expected at most %d funcs running at once but got %d", limit, maxRunning)
+		}
+	})
+	t.Run("all errors returned", func(t *testing.T) {
+		t.Parallel()
+		first := New("first")
+		second := New("second")
+		expected := []error{first, second}
+		result := AggregateConcurrentLimit([]func() error{
+			func() error {
+				return second
+			},
+			func() error {
+				return nil
+			},
+			func() error {
+				return first
+			},
+		}, 1)
+		resultErrors := Errors(result)
+		sort.SliceStable(resultErrors, func(i, j int) bool {
+			return resultErrors[i].Error() < resultErrors[j].Error()
+		})
+		assert.DeepEqual(t, expected, resultErrors)
+	})
+}
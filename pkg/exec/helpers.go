@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/alessio/shellescape"
 
@@ -72,6 +73,55 @@ func CombinedOutputLines(cmd Cmd) (lines []string, err error) {
 	return lines, err
 }
 
+// CombinedOutputLinesStreaming is like CombinedOutputLines, but additionally
+// calls onLine with each line of output as soon as it is written
+func CombinedOutputLinesStreaming(cmd Cmd, onLine func(line string)) (lines []string, err error) {
+	w := &lineWriter{
+		onLine: func(line string) {
+			lines = append(lines, line)
+			onLine(line)
+		},
+	}
+	cmd.SetStdout(w)
+	cmd.SetStderr(w)
+	err = cmd.Run()
+	w.flush()
+	return lines, err
+}
+
+// lineWriter is an io.Writer that calls onLine for each complete line written
+// it is safe to use for both stdout and stderr concurrently
+type lineWriter struct {
+	mu      sync.Mutex
+	partial []byte
+	onLine  func(line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(strings.TrimSuffix(string(w.partial[:i]), "\r"))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush calls onLine with any remaining output not ending in a newline
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.onLine(string(w.partial))
+		w.partial = nil
+	}
+}
+
 // OutputLines is like os/exec's cmd.Output(),
 // but over our Cmd interface, and instead of returning the byte buffer of
 // stdout, it scans these for lines and returns a slice of output lines
@@ -32,6 +32,11 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 		KubeadmConfigPatchesJSON6902:    make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
 		ContainerdConfigPatches:         in.ContainerdConfigPatches,
 		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+		ImageGCHighThresholdPercent:     in.ImageGCHighThresholdPercent,
+		ImageGCLowThresholdPercent:      in.ImageGCLowThresholdPercent,
+		SchedulerProfiles:               make([]SchedulerProfile, len(in.SchedulerProfiles)),
+		KubeReserved:                    in.KubeReserved,
+		SystemReserved:                  in.SystemReserved,
 	}
 
 	for i := range in.Nodes {
@@ -44,6 +49,10 @@ func Convertv1alpha4(in *v1alpha4.Cluster) *Cluster {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
 
+	for i := range in.SchedulerProfiles {
+		convertv1alpha4SchedulerProfile(&in.SchedulerProfiles[i], &out.SchedulerProfiles[i])
+	}
+
 	return out
 }
 
@@ -52,8 +61,21 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 	out.Image = in.Image
 
 	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.Env = in.Env
+	out.ImageGCHighThresholdPercent = in.ImageGCHighThresholdPercent
+	out.ImageGCLowThresholdPercent = in.ImageGCLowThresholdPercent
+	out.KubeReserved = in.KubeReserved
+	out.SystemReserved = in.SystemReserved
+	out.Zone = in.Zone
+	out.Region = in.Region
+	out.Labels = in.Labels
+	out.Hostname = in.Hostname
+	out.Entrypoint = in.Entrypoint
+	out.SkipPhases = in.SkipPhases
+	out.GPUs = in.GPUs
 	out.ExtraMounts = make([]Mount, len(in.ExtraMounts))
 	out.ExtraPortMappings = make([]PortMapping, len(in.ExtraPortMappings))
+	out.HostAliases = make([]HostAlias, len(in.HostAliases))
 	out.KubeadmConfigPatchesJSON6902 = make([]PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
 
 	for i := range in.ExtraMounts {
@@ -64,6 +86,10 @@ func convertv1alpha4Node(in *v1alpha4.Node, out *Node) {
 		convertv1alpha4PortMapping(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
 	}
 
+	for i := range in.HostAliases {
+		convertv1alpha4HostAlias(&in.HostAliases[i], &out.HostAliases[i])
+	}
+
 	for i := range in.KubeadmConfigPatchesJSON6902 {
 		convertv1alpha4PatchJSON6902(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
 	}
@@ -94,9 +120,142 @@ func convertv1alpha4Mount(in *v1alpha4.Mount, out *Mount) {
 	out.Propagation = MountPropagation(in.Propagation)
 }
 
+func convertv1alpha4HostAlias(in *v1alpha4.HostAlias, out *HostAlias) {
+	out.IP = in.IP
+	out.Hostnames = in.Hostnames
+}
+
+func convertv1alpha4SchedulerProfile(in *v1alpha4.SchedulerProfile, out *SchedulerProfile) {
+	out.SchedulerName = in.SchedulerName
+	out.Plugins = in.Plugins
+	out.PluginConfig = in.PluginConfig
+}
+
 func convertv1alpha4PortMapping(in *v1alpha4.PortMapping, out *PortMapping) {
 	out.ContainerPort = in.ContainerPort
 	out.HostPort = in.HostPort
 	out.ListenAddress = in.ListenAddress
 	out.Protocol = PortMappingProtocol(in.Protocol)
 }
+
+// ConvertToV1alpha4 converts an internal API version cluster to v1alpha4
+func ConvertToV1alpha4(in *Cluster) *v1alpha4.Cluster {
+	in = in.DeepCopy() // deep copy first to avoid touching the original
+	out := &v1alpha4.Cluster{
+		TypeMeta: v1alpha4.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: "kind.x-k8s.io/v1alpha4",
+		},
+		Name:                            in.Name,
+		Nodes:                           make([]v1alpha4.Node, len(in.Nodes)),
+		FeatureGates:                    in.FeatureGates,
+		RuntimeConfig:                   in.RuntimeConfig,
+		KubeadmConfigPatches:            in.KubeadmConfigPatches,
+		KubeadmConfigPatchesJSON6902:    make([]v1alpha4.PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902)),
+		ContainerdConfigPatches:         in.ContainerdConfigPatches,
+		ContainerdConfigPatchesJSON6902: in.ContainerdConfigPatchesJSON6902,
+		ImageGCHighThresholdPercent:     in.ImageGCHighThresholdPercent,
+		ImageGCLowThresholdPercent:      in.ImageGCLowThresholdPercent,
+		SchedulerProfiles:               make([]v1alpha4.SchedulerProfile, len(in.SchedulerProfiles)),
+		KubeReserved:                    in.KubeReserved,
+		SystemReserved:                  in.SystemReserved,
+	}
+
+	for i := range in.Nodes {
+		convertNodeToV1alpha4(&in.Nodes[i], &out.Nodes[i])
+	}
+
+	convertNetworkingToV1alpha4(&in.Networking, &out.Networking)
+
+	for i := range in.KubeadmConfigPatchesJSON6902 {
+		convertPatchJSON6902ToV1alpha4(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
+	}
+
+	for i := range in.SchedulerProfiles {
+		convertSchedulerProfileToV1alpha4(&in.SchedulerProfiles[i], &out.SchedulerProfiles[i])
+	}
+
+	return out
+}
+
+func convertNodeToV1alpha4(in *Node, out *v1alpha4.Node) {
+	out.Role = v1alpha4.NodeRole(in.Role)
+	out.Image = in.Image
+
+	out.KubeadmConfigPatches = in.KubeadmConfigPatches
+	out.Env = in.Env
+	out.ImageGCHighThresholdPercent = in.ImageGCHighThresholdPercent
+	out.ImageGCLowThresholdPercent = in.ImageGCLowThresholdPercent
+	out.KubeReserved = in.KubeReserved
+	out.SystemReserved = in.SystemReserved
+	out.Zone = in.Zone
+	out.Region = in.Region
+	out.Labels = in.Labels
+	out.Hostname = in.Hostname
+	out.Entrypoint = in.Entrypoint
+	out.SkipPhases = in.SkipPhases
+	out.GPUs = in.GPUs
+	out.ExtraMounts = make([]v1alpha4.Mount, len(in.ExtraMounts))
+	out.ExtraPortMappings = make([]v1alpha4.PortMapping, len(in.ExtraPortMappings))
+	out.HostAliases = make([]v1alpha4.HostAlias, len(in.HostAliases))
+	out.KubeadmConfigPatchesJSON6902 = make([]v1alpha4.PatchJSON6902, len(in.KubeadmConfigPatchesJSON6902))
+
+	for i := range in.ExtraMounts {
+		convertMountToV1alpha4(&in.ExtraMounts[i], &out.ExtraMounts[i])
+	}
+
+	for i := range in.ExtraPortMappings {
+		convertPortMappingToV1alpha4(&in.ExtraPortMappings[i], &out.ExtraPortMappings[i])
+	}
+
+	for i := range in.HostAliases {
+		convertHostAliasToV1alpha4(&in.HostAliases[i], &out.HostAliases[i])
+	}
+
+	for i := range in.KubeadmConfigPatchesJSON6902 {
+		convertPatchJSON6902ToV1alpha4(&in.KubeadmConfigPatchesJSON6902[i], &out.KubeadmConfigPatchesJSON6902[i])
+	}
+}
+
+func convertPatchJSON6902ToV1alpha4(in *PatchJSON6902, out *v1alpha4.PatchJSON6902) {
+	out.Group = in.Group
+	out.Version = in.Version
+	out.Kind = in.Kind
+	out.Patch = in.Patch
+}
+
+func convertNetworkingToV1alpha4(in *Networking, out *v1alpha4.Networking) {
+	out.IPFamily = v1alpha4.ClusterIPFamily(in.IPFamily)
+	out.APIServerPort = in.APIServerPort
+	out.APIServerAddress = in.APIServerAddress
+	out.PodSubnet = in.PodSubnet
+	out.KubeProxyMode = v1alpha4.ProxyMode(in.KubeProxyMode)
+	out.ServiceSubnet = in.ServiceSubnet
+	out.DisableDefaultCNI = in.DisableDefaultCNI
+}
+
+func convertMountToV1alpha4(in *Mount, out *v1alpha4.Mount) {
+	out.ContainerPath = in.ContainerPath
+	out.HostPath = in.HostPath
+	out.Readonly = in.Readonly
+	out.SelinuxRelabel = in.SelinuxRelabel
+	out.Propagation = v1alpha4.MountPropagation(in.Propagation)
+}
+
+func convertHostAliasToV1alpha4(in *HostAlias, out *v1alpha4.HostAlias) {
+	out.IP = in.IP
+	out.Hostnames = in.Hostnames
+}
+
+func convertSchedulerProfileToV1alpha4(in *SchedulerProfile, out *v1alpha4.SchedulerProfile) {
+	out.SchedulerName = in.SchedulerName
+	out.Plugins = in.Plugins
+	out.PluginConfig = in.PluginConfig
+}
+
+func convertPortMappingToV1alpha4(in *PortMapping, out *v1alpha4.PortMapping) {
+	out.ContainerPort = in.ContainerPort
+	out.HostPort = in.HostPort
+	out.ListenAddress = in.ListenAddress
+	out.Protocol = v1alpha4.PortMappingProtocol(in.Protocol)
+}
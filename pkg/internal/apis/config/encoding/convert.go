@@ -27,3 +27,8 @@ func V1Alpha4ToInternal(cluster *v1alpha4.Cluster) *config.Cluster {
 	v1alpha4.SetDefaultsCluster(cluster)
 	return config.Convertv1alpha4(cluster)
 }
+
+// InternalToV1Alpha4 converts from the internal API version to v1alpha4
+func InternalToV1Alpha4(cluster *config.Cluster) *v1alpha4.Cluster {
+	return config.ConvertToV1alpha4(cluster)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// LoadMerged reads the files at paths and merges them in order into a single
+// `kind` Config, with fields in later files overriding earlier ones.
+//
+// Maps (E.G. featureGates or networking) are merged key by key, while lists
+// (E.G. nodes) and scalar values are replaced entirely by the later file.
+// All files must have the same apiVersion and kind.
+//
+// If paths is empty then the default config is returned
+func LoadMerged(paths ...string) (*config.Cluster, error) {
+	if len(paths) == 0 {
+		return Load("")
+	}
+	var merged map[string]interface{}
+	var firstMeta typeMeta
+	for i, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading file %q", path)
+		}
+		tm := typeMeta{}
+		if err := yaml.Unmarshal(raw, &tm); err != nil {
+			return nil, errors.Wrapf(err, "could not determine kind / apiVersion for config %q", path)
+		}
+		if i == 0 {
+			firstMeta = tm
+		} else if tm != firstMeta {
+			return nil, errors.Errorf(
+				"config %q has apiVersion %q and kind %q, but %q has apiVersion %q and kind %q",
+				path, tm.APIVersion, tm.Kind, paths[0], firstMeta.APIVersion, firstMeta.Kind,
+			)
+		}
+		overlay := map[string]interface{}{}
+		if err := yaml.Unmarshal(raw, &overlay); err != nil {
+			return nil, errors.Wrapf(err, "unable to decode config %q", path)
+		}
+		merged = mergeMaps(merged, overlay)
+	}
+	raw, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to encode merged config")
+	}
+	return Parse(raw)
+}
+
+// mergeMaps recursively merges overlay onto base, values in overlay take
+// precedence except where both values are maps, in which case they are merged
+func mergeMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		overlayMap, overlayIsMap := v.(map[string]interface{})
+		baseMap, baseIsMap := out[k].(map[string]interface{})
+		if overlayIsMap && baseIsMap {
+			out[k] = mergeMaps(baseMap, overlayMap)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestLoadMerged(t *testing.T) {
+	t.Parallel()
+	cfg, err := LoadMerged(
+		"./testdata/v1alpha4/merge-base.yaml",
+		"./testdata/v1alpha4/merge-overlay.yaml",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error loading configs: %v", err)
+	}
+	// maps are merged
+	assert.DeepEqual(t, map[string]bool{"FooGate": true, "BarGate": false}, cfg.FeatureGates)
+	assert.StringEqual(t, "10.250.0.0/16", cfg.Networking.PodSubnet)
+	assert.StringEqual(t, "10.0.0.0/16", cfg.Networking.ServiceSubnet)
+	// lists are replaced
+	if len(cfg.Nodes) != 1 {
+		t.Errorf("expected the overlay to replace the nodes, got %d nodes", len(cfg.Nodes))
+	}
+}
+
+func TestLoadMergedErrors(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		TestName string
+		Paths    []string
+	}{
+		{
+			TestName: "mismatched apiVersion",
+			Paths:    []string{"./testdata/v1alpha4/merge-base.yaml", "./testdata/invalid-apiversion.yaml"},
+		},
+		{
+			TestName: "non-existent file",
+			Paths:    []string{"./testdata/v1alpha4/merge-base.yaml", "./testdata/bogus.yaml"},
+		},
+		{
+			TestName: "unknown field in overlay",
+			Paths:    []string{"./testdata/v1alpha4/merge-base.yaml", "./testdata/v1alpha4/invalid-bogus-field.yaml"},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.TestName, func(t *testing.T) {
+			t.Parallel()
+			_, err := LoadMerged(tc.Paths...)
+			assert.ExpectError(t, true, err)
+		})
+	}
+}
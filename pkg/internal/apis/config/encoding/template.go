@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"bytes"
+	"io/ioutil"
+	"text/template"
+
+	"sigs.k8s.io/kind/pkg/errors"
+
+	"sigs.k8s.io/kind/pkg/internal/apis/config"
+)
+
+// LoadTemplate reads the Go text/template at path, renders it with vars and
+// parses the result as a `kind` Config, see Parse.
+//
+// Every variable the template references must be set in vars, E.G.
+// {{ .Name }} requires vars["Name"].
+func LoadTemplate(path string, vars map[string]string) (*config.Cluster, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading file %q", path)
+	}
+	t, err := template.New(path).Option("missingkey=error").Parse(string(raw))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config template %q", path)
+	}
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	var buff bytes.Buffer
+	if err := t.Execute(&buff, vars); err != nil {
+		return nil, errors.Wrapf(err, "failed to render config template %q", path)
+	}
+	cfg, err := Parse(buff.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid config rendered from template %q", path)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encoding
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	t.Parallel()
+	cfg, err := LoadTemplate("./testdata/v1alpha4/template.yaml", map[string]string{
+		"Name":      "rendered",
+		"PodSubnet": "10.250.0.0/16",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error loading template: %v", err)
+	}
+	assert.StringEqual(t, "rendered", cfg.Name)
+	assert.StringEqual(t, "10.250.0.0/16", cfg.Networking.PodSubnet)
+	if len(cfg.Nodes) != 2 {
+		t.Errorf("expected 2 nodes, got %d nodes", len(cfg.Nodes))
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		TestName string
+		Path     string
+		Vars     map[string]string
+	}{
+		{
+			TestName: "missing variable",
+			Path:     "./testdata/v1alpha4/template.yaml",
+			Vars:     map[string]string{"Name": "rendered"},
+		},
+		{
+			TestName: "no variables",
+			Path:     "./testdata/v1alpha4/template.yaml",
+		},
+		{
+			TestName: "rendered config is invalid",
+			Path:     "./testdata/v1alpha4/invalid-template.yaml",
+			Vars:     map[string]string{"Field": "bogus"},
+		},
+		{
+			TestName: "non-existent file",
+			Path:     "./testdata/bogus.yaml",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.TestName, func(t *testing.T) {
+			t.Parallel()
+			_, err := LoadTemplate(tc.Path, tc.Vars)
+			assert.ExpectError(t, true, err)
+		})
+	}
+}
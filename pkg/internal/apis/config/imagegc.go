@@ -0,0 +1,39 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+// DefaultImageGCHighThresholdPercent is the kubelet imageGCHighThresholdPercent
+// kind uses if unset, which disables image garbage collection
+const DefaultImageGCHighThresholdPercent int32 = 100
+
+// kubeletDefaultImageGCLowThresholdPercent is the kubelet's own default
+// imageGCLowThresholdPercent, used if unset
+const kubeletDefaultImageGCLowThresholdPercent int32 = 80
+
+// ImageGCThresholds returns the kubelet image GC thresholds for node n in
+// cluster c, the node's thresholds take precedence over the cluster's
+// Zero values are unset
+func ImageGCThresholds(c *Cluster, n *Node) (high, low int32) {
+	high, low = c.ImageGCHighThresholdPercent, c.ImageGCLowThresholdPercent
+	if n.ImageGCHighThresholdPercent != 0 {
+		high = n.ImageGCHighThresholdPercent
+	}
+	if n.ImageGCLowThresholdPercent != 0 {
+		low = n.ImageGCLowThresholdPercent
+	}
+	return high, low
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// kubeletLabels are the kubernetes.io and k8s.io label keys the kubelet may
+// set on its own node with the NodeRestriction admission plugin enabled
+var kubeletLabels = map[string]bool{
+	"kubernetes.io/hostname":                   true,
+	"kubernetes.io/arch":                       true,
+	"kubernetes.io/os":                         true,
+	"beta.kubernetes.io/arch":                  true,
+	"beta.kubernetes.io/os":                    true,
+	"beta.kubernetes.io/instance-type":         true,
+	"node.kubernetes.io/instance-type":         true,
+	"failure-domain.beta.kubernetes.io/zone":   true,
+	"failure-domain.beta.kubernetes.io/region": true,
+	"topology.kubernetes.io/zone":              true,
+	"topology.kubernetes.io/region":            true,
+}
+
+// kubeletLabelNamespaces are the kubernetes.io label key prefixes the kubelet
+// may set on its own node, including their subdomains
+var kubeletLabelNamespaces = []string{"kubelet.kubernetes.io", "node.kubernetes.io"}
+
+// KubeletMaySetLabel returns true if the NodeRestriction admission plugin
+// allows the kubelet to set the label key on its own node, other keys in the
+// kubernetes.io and k8s.io namespaces and keys with the
+// node-restriction.kubernetes.io prefix must be set through the API instead
+func KubeletMaySetLabel(key string) bool {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return true
+	}
+	namespace := key[:i]
+	if namespace == "node-restriction.kubernetes.io" || strings.HasSuffix(namespace, ".node-restriction.kubernetes.io") {
+		return false
+	}
+	if !inNamespace(namespace, "kubernetes.io") && !inNamespace(namespace, "k8s.io") {
+		return true
+	}
+	if kubeletLabels[key] {
+		return true
+	}
+	for _, allowed := range kubeletLabelNamespaces {
+		if inNamespace(namespace, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// inNamespace returns true if prefix is namespace or one of its subdomains
+func inNamespace(prefix, namespace string) bool {
+	return prefix == namespace || strings.HasSuffix(prefix, "."+namespace)
+}
+
+// NodeLabels splits n's labels into those the kubelet may set at
+// registration and those that must be set through the API, see
+// KubeletMaySetLabel
+func NodeLabels(n *Node) (kubelet, api map[string]string) {
+	for key, value := range n.Labels {
+		if KubeletMaySetLabel(key) {
+			if kubelet == nil {
+				kubelet = map[string]string{}
+			}
+			kubelet[key] = value
+		} else {
+			if api == nil {
+				api = map[string]string{}
+			}
+			api[key] = value
+		}
+	}
+	return kubelet, api
+}
+
+// validLabelNameRE matches the name part of valid Kubernetes label keys,
+// which must also be at most 63 characters
+var validLabelNameRE = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// validateLabelKey validates a Kubernetes label key, an optional DNS-1123
+// subdomain prefix and a name separated by a slash
+func validateLabelKey(key string) error {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if len(prefix) > 253 || !validSubdomainRE.MatchString(prefix) {
+			return errors.Errorf("label key %q prefix must be a DNS-1123 subdomain", key)
+		}
+	}
+	if len(name) > 63 || !validLabelNameRE.MatchString(name) {
+		return errors.Errorf("label key %q name must be no more than 63 characters matching `%s`", key, validLabelNameRE.String())
+	}
+	return nil
+}
+
+// validateLabels validates Kubernetes label keys and values
+func validateLabels(labels map[string]string) error {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	errs := []error{}
+	for _, key := range keys {
+		if err := validateLabelKey(key); err != nil {
+			errs = append(errs, err)
+		}
+		if err := validateLabelValue(labels[key]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
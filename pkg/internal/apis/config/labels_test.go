@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestKubeletMaySetLabel(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Key      string
+		Expected bool
+	}{
+		{Key: "tier", Expected: true},
+		{Key: "example.com/team", Expected: true},
+		{Key: "topology.kubernetes.io/zone", Expected: true},
+		{Key: "kubelet.kubernetes.io/foo", Expected: true},
+		{Key: "foo.node.kubernetes.io/bar", Expected: true},
+		{Key: "node-role.kubernetes.io/worker", Expected: false},
+		{Key: "kubernetes.io/foo", Expected: false},
+		{Key: "foo.k8s.io/bar", Expected: false},
+		{Key: "node-restriction.kubernetes.io/pool", Expected: false},
+		{Key: "example.node-restriction.kubernetes.io/pool", Expected: false},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Key, func(t *testing.T) {
+			t.Parallel()
+			assert.BoolEqual(t, tc.Expected, KubeletMaySetLabel(tc.Key))
+		})
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	t.Parallel()
+	kubelet, api := NodeLabels(&Node{Labels: map[string]string{
+		"tier":                           "frontend",
+		"node-role.kubernetes.io/worker": "",
+	}})
+	assert.DeepEqual(t, map[string]string{"tier": "frontend"}, kubelet)
+	assert.DeepEqual(t, map[string]string{"node-role.kubernetes.io/worker": ""}, api)
+
+	kubelet, api = NodeLabels(&Node{})
+	assert.DeepEqual(t, map[string]string(nil), kubelet)
+	assert.DeepEqual(t, map[string]string(nil), api)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// reservableResources are the resources the kubelet can reserve for the
+// Kubernetes and OS system daemons
+var reservableResources = map[string]bool{
+	"cpu":               true,
+	"memory":            true,
+	"ephemeral-storage": true,
+	"pid":               true,
+}
+
+// quantityRE matches non-negative Kubernetes resource quantities, E.G. 500m,
+// 1.5, 256Mi, or 1e9
+var quantityRE = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei|[eE][+-]?[0-9]+)?$`)
+
+// quantitySuffixes are the multipliers of the quantity suffixes
+var quantitySuffixes = map[string]float64{
+	"":   1,
+	"m":  1e-3,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"P":  1e15,
+	"E":  1e18,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+// ParseQuantity returns the value of the Kubernetes resource quantity q,
+// E.G. 0.5 for 500m or 268435456 for 256Mi
+func ParseQuantity(q string) (float64, error) {
+	m := quantityRE.FindStringSubmatch(q)
+	if m == nil {
+		return 0, errors.Errorf("%q is not a valid quantity", q)
+	}
+	multiplier, ok := quantitySuffixes[m[3]]
+	if !ok {
+		// a decimal exponent, which ParseFloat understands
+		m[1], multiplier = q, 1
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, errors.Errorf("%q is not a valid quantity", q)
+	}
+	return value * multiplier, nil
+}
+
+// ReservedResources returns the kubelet kubeReserved and systemReserved
+// resources for node n in cluster c, the node's quantities take precedence
+// over the cluster's
+func ReservedResources(c *Cluster, n *Node) (kube, system map[string]string) {
+	return mergeReserved(c.KubeReserved, n.KubeReserved), mergeReserved(c.SystemReserved, n.SystemReserved)
+}
+
+func mergeReserved(cluster, node map[string]string) map[string]string {
+	if len(cluster) == 0 && len(node) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(cluster)+len(node))
+	for k, v := range cluster {
+		merged[k] = v
+	}
+	for k, v := range node {
+		merged[k] = v
+	}
+	return merged
+}
+
+// validateReserved validates kubelet reserved resources, field is the name
+// of the config field for errors
+func validateReserved(field string, reserved map[string]string) error {
+	names := make([]string, 0, len(reserved))
+	for name := range reserved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := []error{}
+	for _, name := range names {
+		quantity := reserved[name]
+		if !reservableResources[name] {
+			errs = append(errs, errors.Errorf("invalid %s resource %q: must be one of cpu, memory, ephemeral-storage, or pid", field, name))
+			continue
+		}
+		if _, err := ParseQuantity(quantity); err != nil {
+			errs = append(errs, errors.Errorf("invalid %s %s: %v", field, name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
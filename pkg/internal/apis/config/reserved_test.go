@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/internal/assert"
+)
+
+func TestParseQuantity(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		Quantity    string
+		Expected    float64
+		ExpectError bool
+	}{
+		{Quantity: "2", Expected: 2},
+		{Quantity: "500m", Expected: 0.5},
+		{Quantity: "1.5", Expected: 1.5},
+		{Quantity: "256Mi", Expected: 256 << 20},
+		{Quantity: "1G", Expected: 1e9},
+		{Quantity: "1e3", Expected: 1000},
+		{Quantity: "", ExpectError: true},
+		{Quantity: "-1", ExpectError: true},
+		{Quantity: "1GB", ExpectError: true},
+		{Quantity: "Mi", ExpectError: true},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Quantity, func(t *testing.T) {
+			t.Parallel()
+			value, err := ParseQuantity(tc.Quantity)
+			assert.ExpectError(t, tc.ExpectError, err)
+			if err == nil && value != tc.Expected {
+				t.Errorf("expected %v but got %v", tc.Expected, value)
+			}
+		})
+	}
+}
+
+func TestReservedResources(t *testing.T) {
+	t.Parallel()
+	c := &Cluster{
+		KubeReserved: map[string]string{"cpu": "100m", "memory": "256Mi"},
+	}
+	n := &Node{
+		KubeReserved:   map[string]string{"memory": "512Mi"},
+		SystemReserved: map[string]string{"pid": "1000"},
+	}
+	kube, system := ReservedResources(c, n)
+	assert.DeepEqual(t, map[string]string{"cpu": "100m", "memory": "512Mi"}, kube)
+	assert.DeepEqual(t, map[string]string{"pid": "1000"}, system)
+	kube, system = ReservedResources(&Cluster{}, &Node{})
+	assert.DeepEqual(t, map[string]string(nil), kube)
+	assert.DeepEqual(t, map[string]string(nil), system)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+// ValidateSchema performs structural checks on a defaulted Cluster: that
+// required fields are set, enum fields have known values, and fields with a
+// format (ports, addresses, CIDRs, percentages) are well formed.
+// Each error is qualified with the path of the field, E.G. nodes[1].role.
+// This catches malformed configs constructed in memory with clearer errors
+// than Validate, which should still be called to check semantics.
+func ValidateSchema(c *Cluster) error {
+	if c == nil {
+		return errors.New("config is required")
+	}
+	errs := []error{}
+	fieldErr := func(path, format string, args ...interface{}) {
+		errs = append(errs, errors.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Name == "" {
+		fieldErr("name", "required")
+	}
+	if len(c.Nodes) == 0 {
+		fieldErr("nodes", "at least one node is required")
+	}
+	validatePercentSchema(fieldErr, "imageGCHighThresholdPercent", c.ImageGCHighThresholdPercent)
+	validatePercentSchema(fieldErr, "imageGCLowThresholdPercent", c.ImageGCLowThresholdPercent)
+	for name := range c.FeatureGates {
+		if name == "" {
+			fieldErr("featureGates", "names must not be empty")
+		}
+	}
+	for key := range c.RuntimeConfig {
+		if key == "" {
+			fieldErr("runtimeConfig", "keys must not be empty")
+		}
+	}
+
+	// networking
+	switch c.Networking.IPFamily {
+	case IPv4Family, IPv6Family:
+	default:
+		fieldErr("networking.ipFamily", "unsupported value %q, must be one of %q, %q", c.Networking.IPFamily, IPv4Family, IPv6Family)
+	}
+	switch c.Networking.KubeProxyMode {
+	case IPTablesMode, IPVSMode, NFTablesMode:
+	default:
+		fieldErr("networking.kubeProxyMode", "unsupported value %q, must be one of %q, %q, %q", c.Networking.KubeProxyMode, IPTablesMode, IPVSMode, NFTablesMode)
+	}
+	validatePortSchema(fieldErr, "networking.apiServerPort", c.Networking.APIServerPort)
+	validateIPSchema(fieldErr, "networking.apiServerAddress", c.Networking.APIServerAddress, true)
+	validateCIDRSchema(fieldErr, "networking.podSubnet", c.Networking.PodSubnet)
+	validateCIDRSchema(fieldErr, "networking.serviceSubnet", c.Networking.ServiceSubnet)
+
+	// nodes
+	for i := range c.Nodes {
+		n := &c.Nodes[i]
+		path := fmt.Sprintf("nodes[%d]", i)
+		switch n.Role {
+		case ControlPlaneRole, WorkerRole, KubeletOnlyRole:
+		default:
+			fieldErr(path+".role", "unsupported value %q, must be one of %q, %q, %q", n.Role, ControlPlaneRole, WorkerRole, KubeletOnlyRole)
+		}
+		if n.Image == "" {
+			fieldErr(path+".image", "required")
+		}
+		validatePercentSchema(fieldErr, path+".imageGCHighThresholdPercent", n.ImageGCHighThresholdPercent)
+		validatePercentSchema(fieldErr, path+".imageGCLowThresholdPercent", n.ImageGCLowThresholdPercent)
+		for j, m := range n.ExtraMounts {
+			mountPath := fmt.Sprintf("%s.extraMounts[%d]", path, j)
+			if m.HostPath == "" {
+				fieldErr(mountPath+".hostPath", "required")
+			}
+			if m.ContainerPath == "" {
+				fieldErr(mountPath+".containerPath", "required")
+			}
+			switch m.Propagation {
+			case "", MountPropagationNone, MountPropagationHostToContainer, MountPropagationBidirectional:
+			default:
+				fieldErr(mountPath+".propagation", "unsupported value %q, must be one of %q, %q, %q", m.Propagation, MountPropagationNone, MountPropagationHostToContainer, MountPropagationBidirectional)
+			}
+		}
+		for j, pm := range n.ExtraPortMappings {
+			mappingPath := fmt.Sprintf("%s.extraPortMappings[%d]", path, j)
+			if pm.ContainerPort == 0 {
+				fieldErr(mappingPath+".containerPort", "required")
+			} else {
+				validatePortSchema(fieldErr, mappingPath+".containerPort", pm.ContainerPort)
+			}
+			validatePortSchema(fieldErr, mappingPath+".hostPort", pm.HostPort)
+			validateIPSchema(fieldErr, mappingPath+".listenAddress", pm.ListenAddress, false)
+			switch pm.Protocol {
+			case "", PortMappingProtocolTCP, PortMappingProtocolUDP, PortMappingProtocolSCTP:
+			default:
+				fieldErr(mappingPath+".protocol", "unsupported value %q, must be one of %q, %q, %q", pm.Protocol, PortMappingProtocolTCP, PortMappingProtocolUDP, PortMappingProtocolSCTP)
+			}
+		}
+		for name := range n.Env {
+			if name == "" {
+				fieldErr(path+".env", "names must not be empty")
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	return nil
+}
+
+// validatePortSchema checks that port is a port number, where -1 is allowed
+// for the backend to pick and 0 for kind to pick, see validatePort
+func validatePortSchema(fieldErr func(path, format string, args ...interface{}), path string, port int32) {
+	if port < -1 || port > 65535 {
+		fieldErr(path, "%d must be a port number between 0 and 65535, or -1", port)
+	}
+}
+
+// validateIPSchema checks that address is an IP address, if set or required
+func validateIPSchema(fieldErr func(path, format string, args ...interface{}), path, address string, required bool) {
+	if address == "" {
+		if required {
+			fieldErr(path, "required")
+		}
+		return
+	}
+	if net.ParseIP(address) == nil {
+		fieldErr(path, "%q must be an IP address", address)
+	}
+}
+
+// validateCIDRSchema checks that cidr is a required CIDR
+func validateCIDRSchema(fieldErr func(path, format string, args ...interface{}), path, cidr string) {
+	if cidr == "" {
+		fieldErr(path, "required")
+		return
+	}
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		fieldErr(path, "%q must be a CIDR", cidr)
+	}
+}
+
+// validatePercentSchema checks that percent is between 0 and 100
+func validatePercentSchema(fieldErr func(path, format string, args ...interface{}), path string, percent int32) {
+	if percent < 0 || percent > 100 {
+		fieldErr(path, "%d must be a percentage between 0 and 100", percent)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kind/pkg/errors"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+	defaulted := func(mutate func(c *Cluster)) *Cluster {
+		c := &Cluster{}
+		SetDefaultsCluster(c)
+		c.Nodes = append(c.Nodes, newDefaultedNode(WorkerRole))
+		if mutate != nil {
+			mutate(c)
+		}
+		return c
+	}
+	cases := []struct {
+		Name           string
+		Cluster        *Cluster
+		ExpectedErrors []string
+	}{
+		{
+			Name:    "defaulted",
+			Cluster: defaulted(nil),
+		},
+		{
+			Name:           "nil",
+			ExpectedErrors: []string{"config is required"},
+		},
+		{
+			Name: "unknown enum values",
+			Cluster: defaulted(func(c *Cluster) {
+				c.Networking.IPFamily = "ipv5"
+				c.Nodes[1].Role = "master"
+				c.Nodes[1].ExtraPortMappings = []PortMapping{{ContainerPort: 80, Protocol: "tcp"}}
+			}),
+			ExpectedErrors: []string{
+				"networking.ipFamily:",
+				"nodes[1].role:",
+				"nodes[1].extraPortMappings[0].protocol:",
+			},
+		},
+		{
+			Name: "missing required fields",
+			Cluster: defaulted(func(c *Cluster) {
+				c.Networking.PodSubnet = ""
+				c.Nodes[0].Image = ""
+				c.Nodes[1].ExtraMounts = []Mount{{HostPath: "/tmp"}}
+			}),
+			ExpectedErrors: []string{
+				"networking.podSubnet:",
+				"nodes[0].image:",
+				"nodes[1].extraMounts[0].containerPath:",
+			},
+		},
+		{
+			Name: "malformed fields",
+			Cluster: defaulted(func(c *Cluster) {
+				c.Networking.APIServerAddress = "localhost"
+				c.Networking.ServiceSubnet = "10.96.0.0"
+				c.Nodes[0].ExtraPortMappings = []PortMapping{{ContainerPort: 70000, ListenAddress: "0.0.0"}}
+				c.Nodes[1].ImageGCHighThresholdPercent = 101
+			}),
+			ExpectedErrors: []string{
+				"networking.apiServerAddress:",
+				"networking.serviceSubnet:",
+				"nodes[0].extraPortMappings[0].containerPort:",
+				"nodes[0].extraPortMappings[0].listenAddress:",
+				"nodes[1].imageGCHighThresholdPercent:",
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc := tc // capture loop variable
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateSchema(tc.Cluster)
+			if err == nil {
+				if len(tc.ExpectedErrors) != 0 {
+					t.Errorf("received no errors but expected %v", tc.ExpectedErrors)
+				}
+				return
+			}
+			errs := errors.Errors(err)
+			if errs == nil {
+				errs = []error{err}
+			}
+			if len(errs) != len(tc.ExpectedErrors) {
+				t.Fatalf("expected %d errors but got len(%v) = %d", len(tc.ExpectedErrors), errs, len(errs))
+			}
+			for i, expected := range tc.ExpectedErrors {
+				if !strings.HasPrefix(errs[i].Error(), expected) {
+					t.Errorf("expected error %d to start with %q but got %q", i, expected, errs[i].Error())
+				}
+			}
+		})
+	}
+}
@@ -69,6 +69,30 @@ type Cluster struct {
 	// in the order listed.
 	// These should be YAML or JSON formatting RFC 6902 JSON patches
 	ContainerdConfigPatchesJSON6902 []string
+
+	// ImageGCHighThresholdPercent is the kubelet's imageGCHighThresholdPercent
+	// for every node, unless overridden on the node.
+	// If unset kind disables image garbage collection by using 100
+	ImageGCHighThresholdPercent int32
+
+	// ImageGCLowThresholdPercent is the kubelet's imageGCLowThresholdPercent
+	// for every node, unless overridden on the node.
+	// If unset the kubelet default is used
+	ImageGCLowThresholdPercent int32
+
+	// SchedulerProfiles are additional kube-scheduler profiles, pods select
+	// a profile by setting its name as their spec.schedulerName.
+	// The default-scheduler profile is kept unless it is listed here.
+	SchedulerProfiles []SchedulerProfile
+
+	// KubeReserved is the kubelet's kubeReserved for every node, resources
+	// reserved for Kubernetes system daemons by name and quantity.
+	// Nodes may override the quantity of each resource.
+	KubeReserved map[string]string
+
+	// SystemReserved is the kubelet's systemReserved for every node,
+	// resources reserved for the OS system daemons, like KubeReserved
+	SystemReserved map[string]string
 }
 
 // Node contains settings for a node in the `kind` Cluster.
@@ -82,7 +106,8 @@ type Node struct {
 	Role NodeRole
 
 	// Image is the node image to use when creating this node
-	// If unset a default image will be used, see defaults.Image
+	// If unset $KIND_NODE_IMAGE or else a default image will be used,
+	// see defaults.Image
 	Image string
 
 	/* Advanced fields */
@@ -95,6 +120,68 @@ type Node struct {
 	// binded to a host Port
 	ExtraPortMappings []PortMapping
 
+	// HostAliases are additional /etc/hosts entries for the node container,
+	// E.G. to resolve internal services during bootstrap. These take
+	// precedence over the container runtime's DNS for these hostnames.
+	HostAliases []HostAlias
+
+	// Env sets additional environment variables on the node container.
+	// NOTE: these are set on the container, not for the kubelet or other
+	// processes the node's init system starts, but may be used to configure
+	// custom node images at boot
+	Env map[string]string
+
+	// GPUs gives the node container access to the host's GPUs, either "all",
+	// a number of GPUs, or "device=" and a comma separated list of GPU device
+	// indexes or UUIDs, E.G. "device=0,2", like docker's --gpus.
+	// This is only supported by the docker provider, and the node image must
+	// include the runtime hooks for the GPUs, E.G. the NVIDIA container toolkit
+	GPUs string
+
+	// ImageGCHighThresholdPercent overrides the cluster-wide
+	// imageGCHighThresholdPercent for this node's kubelet if set
+	ImageGCHighThresholdPercent int32
+
+	// ImageGCLowThresholdPercent overrides the cluster-wide
+	// imageGCLowThresholdPercent for this node's kubelet if set
+	ImageGCLowThresholdPercent int32
+
+	// KubeReserved and SystemReserved override the quantities of the
+	// cluster-wide kubeReserved and systemReserved resources for this
+	// node's kubelet, other resources are still reserved
+	KubeReserved   map[string]string
+	SystemReserved map[string]string
+
+	// Zone is the topology.kubernetes.io/zone label for this node, it is
+	// applied by the kubelet at registration before the node is schedulable
+	Zone string
+
+	// Region is the topology.kubernetes.io/region label for this node, it is
+	// applied by the kubelet at registration before the node is schedulable
+	Region string
+
+	// Labels are added to this node, those the kubelet may set on its own
+	// node are applied at registration and the rest, E.G. node-role labels
+	// prohibited by the NodeRestriction admission plugin, through the API
+	// after the node joins
+	Labels map[string]string
+
+	// Hostname is the hostname of the node container, if set it is also the
+	// Kubernetes node name instead of the container name.
+	// It must be a DNS-1123 label, unique across nodes
+	Hostname string
+
+	// Entrypoint overrides the node container's entrypoint, the first element
+	// is the executable and the rest are its arguments.
+	// WARNING: this is unsupported and for experimenting with alternative
+	// node init systems, kind will not work unless it still starts systemd
+	Entrypoint []string
+
+	// SkipPhases are additional kubeadm init or join phases to skip on this
+	// node, E.G. "addon/kube-proxy". The first control plane node runs init
+	// and the others run join. Phases kind depends on cannot be skipped.
+	SkipPhases []string
+
 	// KubeadmConfigPatches are applied to the generated kubeadm config as
 	// strategic merge patches to `kustomize build` internally
 	// https://github.com/kubernetes/community/blob/a9cf5c8f3380bb52ebe57b1e2dbdec136d8dd484/contributors/devel/sig-api-machinery/strategic-merge-patch.md
@@ -117,6 +204,11 @@ const (
 	ControlPlaneRole NodeRole = "control-plane"
 	// WorkerRole identifies a node that hosts a Kubernetes worker
 	WorkerRole NodeRole = "worker"
+	// KubeletOnlyRole identifies a node that runs a kubelet, but never joins
+	// the cluster, E.G. for experimenting with a standalone kubelet and static
+	// pods. These nodes will not appear in `kubectl get nodes`.
+	// NOTE: kind does not configure the kubelet on these nodes
+	KubeletOnlyRole NodeRole = "kubelet-only"
 )
 
 // Networking contains cluster wide network settings
@@ -165,8 +257,12 @@ type ProxyMode string
 const (
 	// IPTablesMode sets ProxyMode to iptables
 	IPTablesMode ProxyMode = "iptables"
-	// IPVSMode sets ProxyMode to iptables
+	// IPVSMode sets ProxyMode to ipvs
 	IPVSMode ProxyMode = "ipvs"
+	// NFTablesMode sets ProxyMode to nftables
+	// This requires Kubernetes v1.31+, or v1.29+ with the NFTablesProxyMode
+	// feature gate enabled
+	NFTablesMode ProxyMode = "nftables"
 )
 
 // PatchJSON6902 represents an inline kustomize json 6902 patch
@@ -180,6 +276,27 @@ type PatchJSON6902 struct {
 	Patch string
 }
 
+// HostAlias is an /etc/hosts entry resolving Hostnames to IP, like the
+// Kubernetes PodSpec field of the same name
+type HostAlias struct {
+	// IP is the IPv4 or IPv6 address the hostnames resolve to
+	IP string
+	// Hostnames are the hostnames resolving to IP
+	Hostnames []string
+}
+
+// SchedulerProfile is a profile in the kube-scheduler's
+// KubeSchedulerConfiguration
+type SchedulerProfile struct {
+	// SchedulerName is the name pods use to select this profile
+	SchedulerName string
+	// Plugins is the profile's `plugins` field as an inline yaml blob-string
+	Plugins string
+	// PluginConfig is the profile's `pluginConfig` field as an inline yaml
+	// blob-string
+	PluginConfig string
+}
+
 // Mount specifies a host volume to mount into a container.
 // This is a close copy of the upstream cri Mount type
 // see: k8s.io/kubernetes/pkg/kubelet/apis/cri/runtime/v1alpha2
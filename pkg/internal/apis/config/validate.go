@@ -18,6 +18,9 @@ package config
 
 import (
 	"net"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v3"
 
 	"sigs.k8s.io/kind/pkg/errors"
 )
@@ -45,19 +48,35 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Wrapf(err, "invalid serviceSubnet"))
 	}
 
-	// KubeProxyMode should be iptables or ipvs
-	if c.Networking.KubeProxyMode != IPTablesMode && c.Networking.KubeProxyMode != IPVSMode {
+	// KubeProxyMode should be iptables, ipvs or nftables
+	switch c.Networking.KubeProxyMode {
+	case IPTablesMode, IPVSMode, NFTablesMode:
+	default:
 		errs = append(errs, errors.Errorf("invalid kubeProxyMode: %s", c.Networking.KubeProxyMode))
 	}
 
 	// validate nodes
 	numByRole := make(map[NodeRole]int32)
+	hostnames := make(map[string]int)
 	// All nodes in the config should be valid
 	for i, n := range c.Nodes {
 		// validate the node
 		if err := n.Validate(); err != nil {
 			errs = append(errs, errors.Errorf("invalid configuration for node %d: %v", i, err))
 		}
+		// validate the image GC thresholds the node will actually use
+		high, low := ImageGCThresholds(c, &c.Nodes[i])
+		if err := validateImageGCThresholds(high, low); err != nil {
+			errs = append(errs, errors.Errorf("invalid configuration for node %d: %v", i, err))
+		}
+		// hostnames must be unique, since they are also node names
+		if n.Hostname != "" {
+			if j, exists := hostnames[n.Hostname]; exists {
+				errs = append(errs, errors.Errorf("invalid configuration for node %d: hostname %q is already used by node %d", i, n.Hostname, j))
+			} else {
+				hostnames[n.Hostname] = i
+			}
+		}
 		// update role count
 		if num, ok := numByRole[n.Role]; ok {
 			numByRole[n.Role] = 1 + num
@@ -72,6 +91,27 @@ func (c *Cluster) Validate() error {
 		errs = append(errs, errors.Errorf("must have at least one %s node", string(ControlPlaneRole)))
 	}
 
+	// validate the kubelet reserved resources, nodes validate their own
+	if err := validateReserved("kubeReserved", c.KubeReserved); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateReserved("systemReserved", c.SystemReserved); err != nil {
+		errs = append(errs, err)
+	}
+
+	// validate scheduler profiles, the scheduler names must be unique
+	schedulerNames := make(map[string]int)
+	for i, p := range c.SchedulerProfiles {
+		if err := p.Validate(); err != nil {
+			errs = append(errs, errors.Errorf("invalid scheduler profile %d: %v", i, err))
+		}
+		if j, exists := schedulerNames[p.SchedulerName]; exists {
+			errs = append(errs, errors.Errorf("invalid scheduler profile %d: schedulerName %q is already used by scheduler profile %d", i, p.SchedulerName, j))
+		} else {
+			schedulerNames[p.SchedulerName] = i
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
@@ -86,7 +126,8 @@ func (n *Node) Validate() error {
 	// validate node role should be one of the expected values
 	switch n.Role {
 	case ControlPlaneRole,
-		WorkerRole:
+		WorkerRole,
+		KubeletOnlyRole:
 	default:
 		errs = append(errs, errors.Errorf("%q is not a valid node role", n.Role))
 	}
@@ -106,6 +147,101 @@ func (n *Node) Validate() error {
 		}
 	}
 
+	// validate topology labels, these must be valid label values
+	if err := validateLabelValue(n.Zone); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid zone"))
+	}
+	if err := validateLabelValue(n.Region); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid region"))
+	}
+
+	// validate the labels, kubelet-only nodes never join so cannot be labeled
+	if len(n.Labels) > 0 && n.Role == KubeletOnlyRole {
+		errs = append(errs, errors.Errorf("invalid labels: %s nodes do not join the cluster", KubeletOnlyRole))
+	}
+	if err := validateLabels(n.Labels); err != nil {
+		errs = append(errs, errors.Wrapf(err, "invalid labels"))
+	}
+
+	// validate the hostname, which is also used as the Kubernetes node name
+	if n.Hostname != "" {
+		if len(n.Hostname) > 63 || !validHostnameRE.MatchString(n.Hostname) {
+			errs = append(errs, errors.Errorf("invalid hostname %q, must be a DNS-1123 label matching `%s`", n.Hostname, validHostnameRE.String()))
+		}
+	}
+
+	// validate the /etc/hosts entries
+	for _, alias := range n.HostAliases {
+		if net.ParseIP(alias.IP) == nil {
+			errs = append(errs, errors.Errorf("invalid host alias IP %q, must be an IPv4 or IPv6 address", alias.IP))
+		}
+		if len(alias.Hostnames) == 0 {
+			errs = append(errs, errors.Errorf("invalid host alias for %q, must have at least one hostname", alias.IP))
+		}
+		for _, hostname := range alias.Hostnames {
+			if len(hostname) > 253 || !validSubdomainRE.MatchString(hostname) {
+				errs = append(errs, errors.Errorf("invalid host alias hostname %q, must be a DNS-1123 subdomain matching `%s`", hostname, validSubdomainRE.String()))
+			}
+		}
+	}
+
+	// validate the entrypoint override, if set it must have an executable
+	if n.Entrypoint != nil && (len(n.Entrypoint) == 0 || n.Entrypoint[0] == "") {
+		errs = append(errs, errors.New("invalid entrypoint, must not be empty if set"))
+	}
+
+	// validate the GPUs, these are passed through to the container runtime
+	if n.GPUs != "" && !validGPUsRE.MatchString(n.GPUs) {
+		errs = append(errs, errors.Errorf("invalid gpus %q, must be \"all\", a number of GPUs, or GPU devices matching `%s`", n.GPUs, validGPUsRE.String()))
+	}
+
+	// validate container environment variable names
+	for name := range n.Env {
+		if !validEnvNameRE.MatchString(name) {
+			errs = append(errs, errors.Errorf("invalid env name %q, env names must match `%s`", name, validEnvNameRE.String()))
+		}
+	}
+
+	// validate the kubelet reserved resources
+	if err := validateReserved("kubeReserved", n.KubeReserved); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateReserved("systemReserved", n.SystemReserved); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+
+	return nil
+}
+
+// Validate returns a ConfigErrors with an entry for each problem
+// with the SchedulerProfile, or nil if there are none
+func (p *SchedulerProfile) Validate() error {
+	errs := []error{}
+
+	// pods select the profile by schedulerName, which must be a subdomain
+	if len(p.SchedulerName) > 253 || !validSubdomainRE.MatchString(p.SchedulerName) {
+		errs = append(errs, errors.Errorf("invalid schedulerName %q, must be a DNS-1123 subdomain matching `%s`", p.SchedulerName, validSubdomainRE.String()))
+	}
+
+	// the fields are checked against the scheduler's config API when the
+	// cluster is created, but they must at least have the right shape
+	if p.Plugins != "" {
+		var plugins map[string]interface{}
+		if err := yaml.Unmarshal([]byte(p.Plugins), &plugins); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid plugins, must be a yaml mapping"))
+		}
+	}
+	if p.PluginConfig != "" {
+		var pluginConfig []interface{}
+		if err := yaml.Unmarshal([]byte(p.PluginConfig), &pluginConfig); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid pluginConfig, must be a yaml list"))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.NewAggregate(errs)
 	}
@@ -113,6 +249,63 @@ func (n *Node) Validate() error {
 	return nil
 }
 
+// validEnvNameRE matches valid environment variable names, following
+// the same rules as Kubernetes container env
+var validEnvNameRE = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+
+// validGPUsRE matches "all", a number of GPUs, or "device=" and a comma
+// separated list of GPU device indexes or UUIDs
+var validGPUsRE = regexp.MustCompile(`^(all|[1-9][0-9]*|device=[-A-Za-z0-9]+(,[-A-Za-z0-9]+)*)$`)
+
+// validHostnameRE matches DNS-1123 labels, which must also be at most 63
+// characters
+var validHostnameRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// validSubdomainRE matches DNS-1123 subdomains, which must also be at most
+// 253 characters
+var validSubdomainRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
+// validLabelValueRE matches valid Kubernetes label values, which must also
+// be at most 63 characters
+var validLabelValueRE = regexp.MustCompile(`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`)
+
+// validateLabelValue validates a Kubernetes label value, empty values are valid
+func validateLabelValue(value string) error {
+	if len(value) > 63 {
+		return errors.Errorf("label value %q must be no more than 63 characters", value)
+	}
+	if !validLabelValueRE.MatchString(value) {
+		return errors.Errorf("label value %q must match `%s`", value, validLabelValueRE.String())
+	}
+	return nil
+}
+
+// validateImageGCThresholds validates the kubelet image GC thresholds,
+// zero values are treated as unset
+func validateImageGCThresholds(high, low int32) error {
+	errs := []error{}
+	if high < 0 || high > 100 {
+		errs = append(errs, errors.Errorf("invalid imageGCHighThresholdPercent %d: must be within 0-100", high))
+	}
+	if low < 0 || low > 100 {
+		errs = append(errs, errors.Errorf("invalid imageGCLowThresholdPercent %d: must be within 0-100", low))
+	}
+	if len(errs) > 0 {
+		return errors.NewAggregate(errs)
+	}
+	// compare the values the kubelet will actually use
+	if high == 0 {
+		high = DefaultImageGCHighThresholdPercent
+	}
+	if low == 0 {
+		low = kubeletDefaultImageGCLowThresholdPercent
+	}
+	if high <= low {
+		return errors.Errorf("imageGCHighThresholdPercent %d must be greater than imageGCLowThresholdPercent %d", high, low)
+	}
+	return nil
+}
+
 func validatePort(port int32) error {
 	// NOTE: -1 is a special value for auto-selecting the port in the container
 	// backend where possible as opposed to in kind itself.
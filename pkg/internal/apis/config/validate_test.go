@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/kind/pkg/errors"
@@ -46,6 +47,18 @@ func TestClusterValidate(t *testing.T) {
 				return c
 			}(),
 		},
+		{
+			Name: "duplicate hostnames",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes = append(c.Nodes, newDefaultedNode(WorkerRole), newDefaultedNode(WorkerRole))
+				c.Nodes[1].Hostname = "worker"
+				c.Nodes[2].Hostname = "worker"
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 		{
 			Name: "default IPv6",
 			Cluster: func() Cluster {
@@ -55,6 +68,118 @@ func TestClusterValidate(t *testing.T) {
 				return c
 			}(),
 		},
+		{
+			Name: "valid image GC thresholds with node override",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.ImageGCHighThresholdPercent = 90
+				c.ImageGCLowThresholdPercent = 50
+				worker := newDefaultedNode(WorkerRole)
+				worker.ImageGCLowThresholdPercent = 85
+				c.Nodes = append(c.Nodes, worker)
+				return c
+			}(),
+		},
+		{
+			Name: "image GC high threshold out of range",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.ImageGCHighThresholdPercent = 101
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "image GC high threshold below default low threshold",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.ImageGCHighThresholdPercent = 70
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "node image GC low threshold above cluster high threshold",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.ImageGCHighThresholdPercent = 90
+				c.Nodes[0].ImageGCLowThresholdPercent = 95
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "valid reserved resources with node override",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.KubeReserved = map[string]string{"cpu": "100m", "memory": "256Mi"}
+				c.SystemReserved = map[string]string{"ephemeral-storage": "1Gi", "pid": "1000"}
+				c.Nodes[0].KubeReserved = map[string]string{"memory": "0.5e9"}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus reserved resources",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.KubeReserved = map[string]string{"gpu": "1"}
+				c.SystemReserved = map[string]string{"memory": "-1Gi"}
+				c.Nodes[0].SystemReserved = map[string]string{"cpu": "lots"}
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			Name: "valid node labels",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes[0].Labels = map[string]string{
+					"tier":                            "frontend",
+					"node-role.kubernetes.io/ingress": "",
+					"example.com/team":                "a-team",
+				}
+				return c
+			}(),
+		},
+		{
+			Name: "bogus node labels",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes[0].Labels = map[string]string{
+					"Example.com/team": "a",
+					"tier":             "front end",
+				}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "kubelet-only nodes",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes = append(c.Nodes, newDefaultedNode(KubeletOnlyRole))
+				return c
+			}(),
+		},
+		{
+			Name: "only kubelet-only nodes",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.Nodes = []Node{newDefaultedNode(KubeletOnlyRole)}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
 		{
 			Name: "bogus podSubnet",
 			Cluster: func() Cluster {
@@ -117,6 +242,49 @@ func TestClusterValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			Name: "valid scheduler profiles",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.SchedulerProfiles = []SchedulerProfile{
+					{SchedulerName: "default-scheduler"},
+					{
+						SchedulerName: "no-scoring-scheduler",
+						Plugins:       "preScore:\n  disabled:\n  - name: '*'\nscore:\n  disabled:\n  - name: '*'\n",
+						PluginConfig:  "- name: NodeResourcesFit\n  args:\n    scoringStrategy:\n      type: MostAllocated\n",
+					},
+				}
+				return c
+			}(),
+		},
+		{
+			Name: "duplicate scheduler profile names",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.SchedulerProfiles = []SchedulerProfile{
+					{SchedulerName: "my-scheduler"},
+					{SchedulerName: "my-scheduler"},
+				}
+				return c
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			Name: "bogus scheduler profiles",
+			Cluster: func() Cluster {
+				c := Cluster{}
+				SetDefaultsCluster(&c)
+				c.SchedulerProfiles = []SchedulerProfile{
+					{SchedulerName: ""},
+					{SchedulerName: "my-scheduler", Plugins: "- not a mapping"},
+					{SchedulerName: "other-scheduler", PluginConfig: "name: NotAList"},
+				}
+				return c
+			}(),
+			ExpectErrors: 3,
+		},
 	}
 
 	for _, tc := range cases {
@@ -226,6 +394,123 @@ func TestNodeValidate(t *testing.T) {
 			}(),
 			ExpectErrors: 1,
 		},
+		{
+			TestName: "Valid env",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Env = map[string]string{"FOO_BAR": "baz", "foo.bar-1": ""}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid env names",
+			Node: func() Node {
+				cfg := newDefaultedNode(ControlPlaneRole)
+				cfg.Env = map[string]string{"1FOO": "bar", "FOO=BAR": "baz"}
+				return cfg
+			}(),
+			ExpectErrors: 2,
+		},
+		{
+			TestName: "Valid zone and region",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Zone = "us-east-1a"
+				cfg.Region = "us-east-1"
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Valid hostname",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Hostname = "worker-1"
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Valid entrypoint",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Entrypoint = []string{"/usr/local/bin/entrypoint", "/sbin/my-init"}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Empty entrypoint",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Entrypoint = []string{}
+				return cfg
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Invalid hostname",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Hostname = "Worker_1"
+				return cfg
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Valid host aliases",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.HostAliases = []HostAlias{
+					{IP: "10.0.0.10", Hostnames: []string{"registry.internal", "git"}},
+					{IP: "fd00::10", Hostnames: []string{"mirror.internal"}},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid host aliases",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.HostAliases = []HostAlias{
+					{IP: "10.0.0", Hostnames: []string{"registry.internal"}},
+					{IP: "10.0.0.11"},
+					{IP: "10.0.0.12", Hostnames: []string{"Registry_Internal"}},
+				}
+				return cfg
+			}(),
+			ExpectErrors: 3,
+		},
+		{
+			TestName: "Valid GPUs",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.GPUs = "device=0,GPU-3a23c669-1f69-c64e-cf85-44e9b07e7a2a"
+				return cfg
+			}(),
+			ExpectErrors: 0,
+		},
+		{
+			TestName: "Invalid GPUs",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.GPUs = "0,1"
+				return cfg
+			}(),
+			ExpectErrors: 1,
+		},
+		{
+			TestName: "Invalid zone and region",
+			Node: func() Node {
+				cfg := newDefaultedNode(WorkerRole)
+				cfg.Zone = "-zone"
+				cfg.Region = strings.Repeat("a", 64)
+				return cfg
+			}(),
+			ExpectErrors: 2,
+		},
 	}
 
 	for _, tc := range cases {
@@ -42,9 +42,18 @@ func StatusForLogger(l log.Logger) *Status {
 		successFormat: " ✓ %s\n",
 		failureFormat: " ✗ %s\n",
 	}
+	// look through loggers wrapping the CLI logger, E.G. to copy its output
+	inner := l
+	for {
+		u, ok := inner.(interface{ Unwrap() log.Logger })
+		if !ok {
+			break
+		}
+		inner = u.Unwrap()
+	}
 	// if we're using the CLI logger, check for if it has a spinner setup
 	// and wire the status to that
-	if v, ok := l.(*Logger); ok {
+	if v, ok := inner.(*Logger); ok {
 		if v2, ok := v.writer.(*Spinner); ok {
 			s.spinner = v2
 			// use colored success / failure messages